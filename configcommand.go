@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"microservice/internal/configuration"
+)
+
+// configCommand is the argument that lets the binary work with the
+// configuration instead of starting the service.
+const configCommand = "config"
+
+// configPrintCommand prints the effective configuration.
+const configPrintCommand = "print"
+
+// configurationCommand runs the configuration subcommand and returns the exit code.
+func configurationCommand(args []string) int {
+	if len(args) == 0 || args[0] != configPrintCommand {
+		fmt.Fprintf(os.Stderr, "usage: %s %s %s\n", os.Args[0], configCommand, configPrintCommand)
+		return 2 //nolint:mnd
+	}
+
+	if err := configuration.Default.Initialize(); err != nil {
+		fmt.Fprintln(os.Stderr, "unable to initialize configuration:", err)
+		return 1
+	}
+
+	// the dynamic database credentials are not requested as printing the
+	// configuration should not lease credentials from the vault
+	if err := configuration.Default.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, "unable to parse configuration:", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(configuration.Default.Effective()); err != nil {
+		fmt.Fprintln(os.Stderr, "unable to print configuration:", err)
+		return 1
+	}
+
+	// the configuration is printed even if it is invalid to help finding
+	// the invalid values
+	var validationErrs configuration.ValidationErrors
+	if err := configuration.Default.Validate(); errors.As(err, &validationErrs) {
+		for _, err := range validationErrs {
+			fmt.Fprintln(os.Stderr, "invalid configuration:", err)
+		}
+		return 1
+	}
+	return 0
+}
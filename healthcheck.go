@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// healthcheckCommand is the argument that lets the binary check the health
+// of a running instance instead of starting the service. This allows the
+// usage in a HEALTHCHECK of images without a shell or other tools.
+const healthcheckCommand = "healthcheck"
+
+// healthcheckTimeout limits the time waited for the health endpoint.
+const healthcheckTimeout = 5 * time.Second
+
+// defaultHealthcheckPort is used if no port has been set in the environment.
+const defaultHealthcheckPort = "8000"
+
+// healthcheck requests the readiness of the instance listening on the local
+// host and returns the exit code for the container runtime.
+// The port is read from the HTTP_PORT environment variable as the
+// configuration may only be readable with credentials (e.g., from a vault).
+// Passing "liveness" as argument checks the liveness instead.
+func healthcheck(args []string) int {
+	path := "/readyz"
+	if len(args) > 0 && args[0] == "liveness" {
+		path = "/healthz"
+	}
+
+	port, set := os.LookupEnv("HTTP_PORT")
+	if !set {
+		port = defaultHealthcheckPort
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthcheckTimeout)
+	defer cancel()
+
+	url := "http://" + net.JoinHostPort("127.0.0.1", port) + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "%s responded with %s\n", path, res.Status)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,121 @@
+package access
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	jwtMiddleware "github.com/wisdom-oss/common-go/v3/middleware/gin/jwt"
+
+	"microservice/internal"
+	"microservice/internal/configuration"
+)
+
+// Rule describes which water rights may be read by callers whose token
+// contains the claim value the rule has been configured for.
+// A water right is permitted by the rule if it has been assigned to one of
+// the legal departments and is managed by one of the water authorities.
+// An empty list does not restrict the respective attribute.
+type Rule struct {
+	LegalDepartments []string `json:"legalDepartments,omitempty" mapstructure:"legal-departments"`
+	WaterAuthorities []string `json:"waterAuthorities,omitempty" mapstructure:"water-authorities"`
+}
+
+// Restriction contains the rules that apply to a caller. A water right or
+// usage location is visible to the caller if any of the rules permits it.
+// A nil Restriction does not restrict the caller.
+type Restriction []Rule
+
+// For returns the restriction that applies to the request.
+//
+// The values of the configured claim are mapped to the rules of the access
+// policy.
+// Callers without a matching rule may not read any water right while
+// administrators and requests handled without an enabled access policy are
+// not restricted.
+func For(c *gin.Context) Restriction {
+	config := configuration.Default.Viper()
+	if !config.GetBool(configuration.ConfigurationKey_AccessPolicyEnabled) {
+		return nil
+	}
+
+	if c.GetBool(jwtMiddleware.KeyAdministrator) {
+		return nil
+	}
+
+	restriction := make(Restriction, 0)
+
+	var rules map[string]Rule
+	if err := config.UnmarshalKey(configuration.ConfigurationKey_AccessPolicyRules, &rules); err != nil {
+		// failing closed if the rules cannot be read
+		return restriction
+	}
+
+	claims, _ := c.Get(internal.KeyTokenClaims)
+	for _, claimValue := range readClaim(claims, config.GetString(configuration.ConfigurationKey_AccessPolicyClaim)) {
+		// viper lowercases all keys which makes the lookup case-insensitive
+		if rule, found := rules[strings.ToLower(claimValue)]; found {
+			restriction = append(restriction, rule)
+		}
+	}
+
+	return restriction
+}
+
+// Arg returns the query argument that is passed to the
+// water_rights.visible function in the queries.
+func (r Restriction) Arg() any {
+	if r == nil {
+		return nil
+	}
+	encoded, _ := json.Marshal(r)
+	return string(encoded)
+}
+
+// Permits reports if the restriction permits reading a water right or usage
+// location with the supplied legal departments and water authority.
+// It mirrors the water_rights.visible database function.
+func (r Restriction) Permits(legalDepartments []string, waterAuthority *string) bool {
+	if r == nil {
+		return true
+	}
+
+	for _, rule := range r {
+		if len(rule.LegalDepartments) > 0 && !slices.ContainsFunc(legalDepartments, func(department string) bool {
+			return slices.Contains(rule.LegalDepartments, department)
+		}) {
+			continue
+		}
+		if len(rule.WaterAuthorities) > 0 && (waterAuthority == nil || !slices.Contains(rule.WaterAuthorities, *waterAuthority)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// readClaim returns the values of the claim which may either be a single
+// string or a list of strings.
+func readClaim(claims any, name string) []string {
+	claimSet, ok := claims.(map[string]any)
+	if !ok || name == "" {
+		return nil
+	}
+
+	switch value := claimSet[name].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
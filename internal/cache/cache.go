@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"microservice/internal/configuration"
+)
+
+// Default is the cache used by the service.
+var Default = New()
+
+// Response is a response body stored in the cache.
+type Response struct {
+	Status      int
+	ContentType string
+	ETag        string
+	Body        []byte
+}
+
+type entry struct {
+	key        string
+	generation uint64
+	expires    time.Time
+	response   Response
+}
+
+// Cache stores the response bodies of expensive requests.
+// The cached responses are bound to the generation of the cache which is
+// bumped whenever the underlying data or the configuration changes. The total
+// size of the stored bodies is limited by the cache.max-size configuration
+// value and the least recently used responses are evicted first.
+//
+// The data version is the id of the latest change event. A new data version
+// invalidates the cached responses.
+type Cache struct {
+	generation  atomic.Uint64
+	dataVersion atomic.Int64
+
+	lock    sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	size    int
+}
+
+// New creates an empty cache.
+func New() *Cache {
+	return &Cache{
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Generation returns the current generation of the cache.
+func (c *Cache) Generation() uint64 {
+	return c.generation.Load()
+}
+
+// DataVersion returns the id of the latest change event contained in the
+// data.
+func (c *Cache) DataVersion() int64 {
+	return c.dataVersion.Load()
+}
+
+// SetDataVersion updates the data version and removes all cached responses
+// if the data version changed.
+func (c *Cache) SetDataVersion(version int64) {
+	if c.dataVersion.Swap(version) != version {
+		c.Invalidate()
+	}
+}
+
+// Invalidate bumps the generation and removes all cached responses.
+func (c *Cache) Invalidate() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.generation.Add(1)
+	clear(c.entries)
+	c.lru.Init()
+	c.size = 0
+}
+
+// Get returns the cached response for the key if it has been stored for the
+// current generation and has not expired yet.
+func (c *Cache) Get(key string) (Response, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element, found := c.entries[key]
+	if !found {
+		return Response{}, false
+	}
+
+	e := element.Value.(*entry) //nolint:forcetypeassert
+	if e.generation != c.generation.Load() || time.Now().After(e.expires) {
+		c.remove(element)
+		return Response{}, false
+	}
+
+	c.lru.MoveToFront(element)
+	return e.response, true
+}
+
+// Put stores the response for the key.
+// The response is discarded if the generation changed since the response has
+// been generated or the response is larger than the cache.
+func (c *Cache) Put(key string, generation uint64, response Response) {
+	config := configuration.Default.Viper()
+	maxSize := config.GetInt(configuration.ConfigurationKey_CacheMaxSize)
+	ttl := config.GetDuration(configuration.ConfigurationKey_CacheTTL)
+
+	if len(response.Body) > maxSize {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if generation != c.generation.Load() {
+		return
+	}
+
+	if element, found := c.entries[key]; found {
+		c.remove(element)
+	}
+
+	element := c.lru.PushFront(&entry{
+		key:        key,
+		generation: generation,
+		expires:    time.Now().Add(ttl),
+		response:   response,
+	})
+	c.entries[key] = element
+	c.size += len(response.Body)
+
+	for c.size > maxSize {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove deletes the entry from the cache. The lock needs to be held.
+func (c *Cache) remove(element *list.Element) {
+	e := element.Value.(*entry) //nolint:forcetypeassert
+	c.lru.Remove(element)
+	delete(c.entries, e.key)
+	c.size -= len(e.response.Body)
+}
@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"microservice/internal/access"
+	"microservice/internal/configuration"
+	"microservice/internal/redaction"
+)
+
+// etagLength is the number of bytes of the body hash used in the etag.
+const etagLength = 16
+
+// Middleware serves the responses of the following handlers from the cache.
+//
+// The responses are cached per route, normalized query parameters and the
+// restrictions applied to the caller (access policy and redaction). The
+// strong ETag of a response is derived from its body, which lets every
+// instance of the service answer conditional requests for the same content
+// regardless of how the cache has been invalidated.
+// Responses that have not been cached are buffered to compute the ETag before
+// they are sent.
+func Middleware(c *gin.Context) {
+	config := configuration.Default.Viper()
+	if !config.GetBool(configuration.ConfigurationKey_CacheEnabled) {
+		c.Next()
+		return
+	}
+
+	key := requestKey(c)
+	headers := map[string]string{
+		"Cache-Control": cacheControl(config.GetDuration(configuration.ConfigurationKey_CacheMaxAge)),
+	}
+	ifNoneMatch := c.GetHeader("If-None-Match")
+
+	if response, found := Default.Get(key); found {
+		headers["ETag"] = response.ETag
+		setHeaders(c.Writer.Header(), headers)
+		if matchesETag(ifNoneMatch, response.ETag) {
+			c.AbortWithStatus(http.StatusNotModified)
+			return
+		}
+		c.Header("X-Cache", "hit")
+		c.Data(response.Status, response.ContentType, response.Body)
+		c.Abort()
+		return
+	}
+
+	// the generation is read before generating the response. an
+	// invalidation while generating discards the response afterward
+	generation := Default.Generation()
+
+	c.Header("X-Cache", "miss")
+	writer := &recordingWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+	c.Next()
+	c.Writer = writer.ResponseWriter
+
+	if !writer.written {
+		return
+	}
+
+	successful := len(c.Errors) == 0 && writer.Status() >= 200 && writer.Status() < 300
+	if !successful {
+		// the response is sent as generated without the caching headers
+		c.Writer.WriteHeaderNow()
+		_, _ = c.Writer.Write(writer.body.Bytes())
+		return
+	}
+
+	etag := bodyETag(acceptsGzip(c), writer.body.Bytes())
+	headers["ETag"] = etag
+	setHeaders(c.Writer.Header(), headers)
+	Default.Put(key, generation, Response{
+		Status:      writer.Status(),
+		ContentType: writer.Header().Get("Content-Type"),
+		ETag:        etag,
+		Body:        writer.body.Bytes(),
+	})
+
+	if matchesETag(ifNoneMatch, etag) {
+		c.Writer.WriteHeader(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Writer.WriteHeaderNow()
+	_, _ = c.Writer.Write(writer.body.Bytes())
+}
+
+// requestKey builds the cache key of the request.
+// The query parameters are sorted by their name and values to let
+// equivalent requests share the cached response.
+func requestKey(c *gin.Context) string {
+	query := c.Request.URL.Query()
+	normalized := make(url.Values, len(query))
+	for name, values := range query {
+		trimmed := make([]string, 0, len(values))
+		for _, value := range values {
+			if value = strings.TrimSpace(value); value != "" {
+				trimmed = append(trimmed, value)
+			}
+		}
+		if len(trimmed) == 0 {
+			continue
+		}
+		slices.Sort(trimmed)
+		normalized[name] = trimmed
+	}
+
+	var restriction string
+	if arg := access.For(c).Arg(); arg != nil {
+		restriction = arg.(string) //nolint:forcetypeassert
+	}
+
+	return strings.Join([]string{
+		c.Request.Method,
+		c.FullPath(),
+		normalized.Encode(),
+		restriction,
+		redaction.For(c).Mode(),
+		fmt.Sprint(acceptsGzip(c)),
+	}, "\n")
+}
+
+// acceptsGzip reports if the response is compressed by a previous middleware.
+// The compression needs to be reflected in the cache key and the strong etag.
+func acceptsGzip(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept-Encoding"), "gzip")
+}
+
+// bodyETag derives the strong etag from the uncompressed body and the
+// content encoding of the response.
+func bodyETag(compressed bool, body []byte) string {
+	h := sha256.New()
+	if compressed {
+		h.Write([]byte("gzip\n"))
+	}
+	h.Write(body)
+	return fmt.Sprintf(`"%x"`, h.Sum(nil)[:etagLength])
+}
+
+func cacheControl(maxAge time.Duration) string {
+	// the responses depend on the caller and may therefore not be stored in
+	// shared caches
+	if maxAge <= 0 {
+		return "private, no-cache"
+	}
+	return fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds()))
+}
+
+// matchesETag reports if the If-None-Match header contains the etag.
+func matchesETag(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag || candidate == "W/"+etag {
+			return true
+		}
+	}
+	return false
+}
+
+// setHeaders sets the caching headers of the response.
+// The responses depend on the authorization of the caller which is added to
+// the existing Vary header.
+func setHeaders(header http.Header, headers map[string]string) {
+	for name, value := range headers {
+		header.Set(name, value)
+	}
+	header.Add("Vary", "Authorization")
+}
+
+// recordingWriter keeps the written body without passing it on, which allows
+// deriving the etag and answering conditional requests once the response has
+// been generated.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body    bytes.Buffer
+	written bool
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *recordingWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *recordingWriter) Written() bool {
+	return w.written
+}
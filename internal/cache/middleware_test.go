@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"microservice/internal/configuration"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	if err := configuration.Default.Initialize(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// instance simulates an instance of the service with its own cache.
+type instance struct {
+	engine   *gin.Engine
+	cache    *Cache
+	status   int
+	handled  int
+	response string
+}
+
+func newInstance(t *testing.T, dataVersion int64) *instance {
+	t.Helper()
+
+	i := &instance{cache: New(), status: http.StatusOK, response: `{"data":"current"}`}
+	i.cache.SetDataVersion(dataVersion)
+
+	i.engine = gin.New()
+	i.engine.GET("/", func(c *gin.Context) {
+		// the middleware uses the default cache
+		Default = i.cache
+		c.Next()
+	}, Middleware, func(c *gin.Context) {
+		i.handled++
+		c.Data(i.status, "application/json", []byte(i.response))
+	})
+	return i
+}
+
+func (i *instance) get(ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	res := httptest.NewRecorder()
+	i.engine.ServeHTTP(res, req)
+	return res
+}
+
+func TestMiddlewareETag(t *testing.T) {
+	previous := Default
+	t.Cleanup(func() { Default = previous })
+
+	first := newInstance(t, 42)
+	res := first.get("")
+	etag := res.Header().Get("ETag")
+	if res.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected a response with etag, got %d %q", res.Code, etag)
+	}
+
+	t.Run("cached response", func(t *testing.T) {
+		res := first.get(etag)
+		if res.Code != http.StatusNotModified || res.Body.Len() != 0 {
+			t.Errorf("expected 304 Not Modified without body, got %d %q", res.Code, res.Body.String())
+		}
+		if first.handled != 1 {
+			t.Errorf("expected the response to be served from the cache, handled %d requests", first.handled)
+		}
+	})
+
+	t.Run("other instance", func(t *testing.T) {
+		other := newInstance(t, 42)
+		res := other.get(etag)
+		if res.Code != http.StatusNotModified || res.Body.Len() != 0 {
+			t.Errorf("expected 304 Not Modified without body, got %d %q", res.Code, res.Body.String())
+		}
+		if res.Header().Get("ETag") != etag {
+			t.Errorf("expected the etag %s, got %s", etag, res.Header().Get("ETag"))
+		}
+		// the response is generated as the instance has not cached it yet
+		if other.handled != 1 {
+			t.Errorf("expected the response to be generated, handled %d requests", other.handled)
+		}
+	})
+
+	t.Run("changed data", func(t *testing.T) {
+		changed := newInstance(t, 43)
+		changed.response = `{"data":"changed"}`
+		res := changed.get(etag)
+		if res.Code != http.StatusOK || res.Body.String() != changed.response {
+			t.Errorf("expected the changed data, got %d %q", res.Code, res.Body.String())
+		}
+		if res.Header().Get("ETag") == etag {
+			t.Error("expected a new etag for the changed data")
+		}
+	})
+
+	t.Run("changed data without change event", func(t *testing.T) {
+		reset := newInstance(t, 42)
+		reset.get("")
+		reset.cache.Invalidate()
+		reset.response = `{"data":"changed"}`
+
+		res := reset.get(etag)
+		if res.Code != http.StatusOK || res.Body.String() != reset.response {
+			t.Errorf("expected the changed data, got %d %q", res.Code, res.Body.String())
+		}
+		if res.Header().Get("ETag") == etag {
+			t.Error("expected a new etag for the changed data")
+		}
+	})
+
+	t.Run("unchanged data after change event", func(t *testing.T) {
+		unchanged := newInstance(t, 43)
+		res := unchanged.get(etag)
+		if res.Code != http.StatusNotModified {
+			t.Errorf("expected 304 Not Modified for the unchanged data, got %d", res.Code)
+		}
+	})
+
+	t.Run("started instances", func(t *testing.T) {
+		started := newInstance(t, 0)
+		started.response = `{"data":"other"}`
+		res := started.get(etag)
+		if res.Code != http.StatusOK || res.Header().Get("ETag") == etag {
+			t.Errorf("expected other data to use another etag, got %d %s", res.Code, res.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("invalidated cache", func(t *testing.T) {
+		invalidated := newInstance(t, 42)
+		invalidated.get("")
+		invalidated.cache.Invalidate()
+		invalidated.status = http.StatusInternalServerError
+
+		res := invalidated.get(etag)
+		if res.Code != http.StatusInternalServerError || res.Body.String() != invalidated.response {
+			t.Errorf("expected the failed response to be passed on, got %d %q", res.Code, res.Body.String())
+		}
+	})
+}
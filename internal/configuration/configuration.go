@@ -1,10 +1,14 @@
 package configuration
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	_ "github.com/dr4hcu5-jan/viper-vault/remote"
 	_ "github.com/dr4hcu5-jan/viper-vault/remote/vault"
@@ -17,18 +21,25 @@ import (
 var Default configuration
 
 type configuration struct {
+	lock        sync.RWMutex // guards the replacement of the viper instance
 	i           *viper.Viper // viper instance
 	t           string       // type of configuration
 	vaultClient *vault.Vault // client used to access a hashicorp vault
 	s           []string     // paths to the secrets to be read from the vault
 	dbRole      string
 	dbMount     string
+
+	rotationLock     sync.Mutex
+	rotationHandlers []func() // called after rotating the database credentials
+
+	reloadLock     sync.Mutex
+	reloadHandlers []func() // called after reloading the configuration
+
+	reloading      sync.Mutex        // serializes the reloading
+	pendingRestart map[string]string // static keys changed since the start
 }
 
 func (c *configuration) Initialize() error {
-	c.i = viper.New()
-	c.setupDefaults()
-
 	configurationType, set := os.LookupEnv(EnvConfigurationType)
 	if !set {
 		configurationType = ConfigurationType_Local
@@ -37,32 +48,93 @@ func (c *configuration) Initialize() error {
 	switch configurationType {
 	case ConfigurationType_Local:
 		c.t = configurationType
-		return c.initializeLocalReading()
 	case ConfigurationType_Vault:
 		c.t = configurationType
-		return c.initializeVaultReading()
+		if err := c.initializeVaultClient(); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unsupported value set in %s", EnvConfigurationType)
 	}
 
+	i, err := c.newInstance()
+	if err != nil {
+		return err
+	}
+	c.i = i
+	return nil
 }
 
+// Viper returns the viper instance containing the current configuration.
+// The instance is replaced if the configuration is reloaded. Therefore, the
+// instance should not be stored by the callers.
 func (c *configuration) Viper() *viper.Viper {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	return c.i
 }
 
+// Read reads the configuration and requests the dynamic database credentials
+// from the vault.
 func (c *configuration) Read() error {
+	if err := c.Load(); err != nil {
+		return err
+	}
+
+	if c.t == ConfigurationType_Vault {
+		return c.setupDatabaseCredentials()
+	}
+	return nil
+}
+
+// Load reads the configuration without requesting the dynamic database
+// credentials from the vault.
+// This allows inspecting the configuration without leasing credentials.
+func (c *configuration) Load() error {
+	return c.readInstance(c.i)
+}
+
+// newInstance creates a viper instance that is set up to read the
+// configuration from the configured source.
+func (c *configuration) newInstance() (*viper.Viper, error) {
+	i := viper.New()
+	setupDefaults(i)
+
 	switch c.t {
 	case ConfigurationType_Local:
-		return c.readLocalConfiguration()
+		if err := initializeLocalReading(i); err != nil {
+			return nil, err
+		}
 	case ConfigurationType_Vault:
-		return c.readVaultConfiguration()
+		if err := c.initializeVaultReading(i); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("unsupported configuration type for reading configuration")
+	}
+	return i, nil
+}
+
+// readInstance reads the configuration into the supplied instance.
+func (c *configuration) readInstance(i *viper.Viper) error {
+	var err error
+	switch c.t {
+	case ConfigurationType_Local:
+		err = readLocalConfiguration(i)
+	case ConfigurationType_Vault:
+		err = readVaultConfiguration(i)
 	default:
 		return errors.New("unsupported configuration type for reading configuration")
 	}
+	if err != nil {
+		return err
+	}
+
+	migrateDeprecatedKeys(i)
+	return nil
 }
 
-func (c *configuration) initializeVaultReading() error {
+func (c *configuration) initializeVaultClient() error {
 	c.vaultClient = &vault.Vault{}
 	if err := c.vaultClient.Initialize(); err != nil {
 		return fmt.Errorf("unable to initialize vault client: %w", err)
@@ -72,77 +144,144 @@ func (c *configuration) initializeVaultReading() error {
 		return fmt.Errorf("unable to authenticate with configured vault: %w", err)
 	}
 
+	// renew the token of the login and log in again before the token reaches
+	// the end of its lifetime
+	go c.vaultClient.AutoLogin(context.Background())
+
 	secretPaths, set := os.LookupEnv(vault.EnvVaultPaths)
 	if !set {
 		return fmt.Errorf("no paths to read secrets from set in %s", vault.EnvVaultPaths)
 	}
 	c.s = strings.Split(secretPaths, ",")
 
+	return nil
+}
+
+func (c *configuration) initializeVaultReading(i *viper.Viper) error {
 	for _, s := range c.s {
-		if err := c.i.AddRemoteProvider("vault", c.vaultClient.ServerAddress(), s); err != nil {
+		if err := i.AddRemoteProvider("vault", c.vaultClient.ServerAddress(), s); err != nil {
 			return err
 		}
 	}
-	c.i.SetConfigType("json")
+	i.SetConfigType("json")
 
 	return nil
 }
 
-func (c *configuration) readVaultConfiguration() error {
-	if err := c.i.ReadRemoteConfig(); err != nil {
+func readVaultConfiguration(i *viper.Viper) error {
+	if err := i.ReadRemoteConfig(); err != nil {
 		return fmt.Errorf("unable to read remote configuration: %w", err)
 	}
+	return nil
+}
 
+// setupDatabaseCredentials requests the dynamic database credentials from the
+// vault unless static credentials have been configured.
+func (c *configuration) setupDatabaseCredentials() error {
 	if c.i.GetString(ConfigurationKey_DatabaseCredentialType) == DatabaseCredentialType_Static {
 		return nil
 	}
 
 	c.dbRole = c.i.GetString(ConfigurationKey_DatabaseCredentialRole)
+	c.dbMount = c.i.GetString(ConfigurationKey_DatabaseCredentialMount)
 
-	username, password, err := c.vaultClient.DatabaseCredentials("databases", c.dbRole)
+	username, password, err := c.vaultClient.DatabaseCredentials(c.dbMount, c.dbRole)
 	if err != nil {
 		return fmt.Errorf("unable to set up dynamic database credentials: %w", err)
 	}
 
-	// automatically renew the database credentials if the lease expires or
-	// the credentials are expiring
-	go c.vaultClient.AutoRenewDatabaseCredentials()
+	// renew the database credentials until they reach their maximal lifetime
+	// and issue new credentials before they expire
+	go c.vaultClient.AutoRenewDatabaseCredentials(context.Background(), c.dbMount, c.dbRole, c.notifyCredentialRotation)
 
+	// the credentials are only set to indicate that they have been
+	// configured. the current credentials are available using
+	// [configuration.DatabaseCredentials] as they change while running
 	c.i.Set(ConfigurationKey_DatabaseUser, username)
 	c.i.Set(ConfigurationKey_DatabasePassword, password)
 
 	return nil
 }
 
+// RefreshDatabaseCredentials issues new database credentials and notifies
+// the users of the credentials about the rotation.
 func (c *configuration) RefreshDatabaseCredentials() error {
-	if c.t != ConfigurationType_Vault {
-		return errors.New("refreshing database credentials is only supported for vault configuration")
+	if !c.dynamicDatabaseCredentials() {
+		return errors.New("refreshing database credentials is only supported for dynamic credentials issued by a vault")
 	}
 
-	c.dbMount = c.i.GetString(ConfigurationKey_DatabaseCredentialMount)
+	if err := c.vaultClient.IssueDatabaseCredentials(c.dbMount, c.dbRole); err != nil {
+		return fmt.Errorf("unable to refresh dynamic database credentials: %w", err)
+	}
 
-	username, password, err := c.vaultClient.DatabaseCredentials(c.dbMount, c.dbRole)
-	if err != nil {
-		return fmt.Errorf("unable to set up dynamic database credentials: %w", err)
+	c.notifyCredentialRotation()
+	return nil
+}
+
+// DatabaseCredentials returns the credentials that are currently used to
+// connect to the database.
+// Dynamic credentials issued by a vault are rotated while the service is
+// running, therefore the credentials need to be requested for every new
+// connection.
+func (c *configuration) DatabaseCredentials() (username, password string) {
+	if c.dynamicDatabaseCredentials() {
+		return c.vaultClient.CurrentDatabaseCredentials()
 	}
+	i := c.Viper()
+	return i.GetString(ConfigurationKey_DatabaseUser), i.GetString(ConfigurationKey_DatabasePassword)
+}
 
-	c.i.Set(ConfigurationKey_DatabaseUser, username)
-	c.i.Set(ConfigurationKey_DatabasePassword, password)
+// OnDatabaseCredentialRotation registers a function that is called after the
+// database credentials have been rotated.
+func (c *configuration) OnDatabaseCredentialRotation(fn func()) {
+	c.rotationLock.Lock()
+	defer c.rotationLock.Unlock()
+	c.rotationHandlers = append(c.rotationHandlers, fn)
+}
 
-	return nil
+func (c *configuration) notifyCredentialRotation() {
+	c.rotationLock.Lock()
+	handlers := slices.Clone(c.rotationHandlers)
+	c.rotationLock.Unlock()
+
+	for _, fn := range handlers {
+		fn()
+	}
+}
+
+func (c *configuration) dynamicDatabaseCredentials() bool {
+	return c.t == ConfigurationType_Vault && c.dbRole != ""
 }
 
-func (c *configuration) initializeLocalReading() error {
-	c.i.SetConfigName("config")
-	c.i.AddConfigPath("/etc/award/")
-	c.i.AddConfigPath("/run/secrets/")
-	c.i.AddConfigPath("$HOME/.config/award/")
-	c.i.AddConfigPath(".")
-	c.i.SetEnvPrefix("")
-	c.i.SetEnvKeyReplacer(strings.NewReplacer(".", "__", "-", "_"))
-	c.i.AutomaticEnv()
+// issuesDatabaseCredentials reports if the database credentials are issued by
+// the vault instead of being part of the configuration.
+func (c *configuration) issuesDatabaseCredentials() bool {
+	return c.t == ConfigurationType_Vault &&
+		c.Viper().GetString(ConfigurationKey_DatabaseCredentialType) != DatabaseCredentialType_Static
+}
+
+// DatabaseCredentialsExpiry returns the time at which the database credentials
+// issued by the vault expire.
+// The second return value is false if the credentials are not issued
+// dynamically by a vault.
+func (c *configuration) DatabaseCredentialsExpiry() (time.Time, bool) {
+	if !c.dynamicDatabaseCredentials() {
+		return time.Time{}, false
+	}
+	return c.vaultClient.DatabaseCredentialsExpiry()
+}
 
-	if err := c.setupEnvironmentAliases(); err != nil {
+func initializeLocalReading(i *viper.Viper) error {
+	i.SetConfigName("config")
+	i.AddConfigPath("/etc/award/")
+	i.AddConfigPath("/run/secrets/")
+	i.AddConfigPath("$HOME/.config/award/")
+	i.AddConfigPath(".")
+	i.SetEnvPrefix("")
+	i.SetEnvKeyReplacer(strings.NewReplacer(".", "__", "-", "_"))
+	i.AutomaticEnv()
+
+	if err := setupEnvironmentAliases(i); err != nil {
 		return fmt.Errorf("unable to setup environment variable aliases: %w", err)
 
 	}
@@ -150,29 +289,47 @@ func (c *configuration) initializeLocalReading() error {
 	return nil
 }
 
-func (c *configuration) setupEnvironmentAliases() error {
+func setupEnvironmentAliases(i *viper.Viper) error {
 	for key, envVars := range environmentVariables {
 		args := make([]string, len(envVars)+1)
 		args[0] = key
 		for idx, envVar := range envVars {
 			args[idx+1] = envVar
 		}
-		if err := c.i.BindEnv(args...); err != nil {
+		if err := i.BindEnv(args...); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (c *configuration) readLocalConfiguration() error {
-	_ = c.i.ReadInConfig()
+func readLocalConfiguration(i *viper.Viper) error {
+	// the configuration file is optional as the configuration may be set
+	// using environment variables only
+	err := i.ReadInConfig()
+	if errors.As(err, &viper.ConfigFileNotFoundError{}) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read configuration file: %w", err)
+	}
 
 	return nil
 }
 
-func (c *configuration) setupDefaults() {
+// migrateDeprecatedKeys copies the values of renamed configuration keys to
+// their current key if the current key has not been set explicitly.
+func migrateDeprecatedKeys(i *viper.Viper) {
+	for deprecatedKey, key := range deprecatedKeys {
+		if i.IsSet(deprecatedKey) && !i.IsSet(key) {
+			i.Set(key, i.Get(deprecatedKey))
+		}
+	}
+}
+
+func setupDefaults(i *viper.Viper) {
 	for key, defaultValue := range defaults {
-		c.i.SetDefault(key, defaultValue)
+		i.SetDefault(key, defaultValue)
 	}
 
 }
@@ -33,7 +33,46 @@ const (
 	ConfigurationKey_HttpHost = "http.host"
 	ConfigurationKey_HttpPort = "http.port"
 
-	ConfigurationKey_OidcAuthority = "oidc.auhority"
+	ConfigurationKey_LogLevel  = "log.level"
+	ConfigurationKey_LogFormat = "log.format"
+
+	ConfigurationKey_ReloadInterval = "reload.interval" // used for vault reading
+
+	ConfigurationKey_OidcAuthority = "oidc.authority"
+	ConfigurationKey_OidcAudience  = "oidc.audience"
 
 	ConfigurationKey_AuthorizationRequired = "authorization.required"
+
+	ConfigurationKey_AccessPolicyEnabled = "access-policy.enabled"
+	ConfigurationKey_AccessPolicyClaim   = "access-policy.claim"
+	ConfigurationKey_AccessPolicyRules   = "access-policy.rules"
+
+	ConfigurationKey_RedactionEnabled      = "redaction.enabled"
+	ConfigurationKey_RedactionMode         = "redaction.mode"
+	ConfigurationKey_RedactionPseudonymKey = "redaction.pseudonym-key"
+
+	ConfigurationKey_TracingEnabled     = "tracing.enabled"
+	ConfigurationKey_TracingExporter    = "tracing.exporter"
+	ConfigurationKey_TracingEndpoint    = "tracing.endpoint"
+	ConfigurationKey_TracingInsecure    = "tracing.insecure"
+	ConfigurationKey_TracingSampleRatio = "tracing.sample-ratio"
+
+	ConfigurationKey_CacheEnabled = "cache.enabled"
+	ConfigurationKey_CacheTTL     = "cache.ttl"
+	ConfigurationKey_CacheMaxSize = "cache.max-size" // in bytes
+	ConfigurationKey_CacheMaxAge  = "cache.max-age"  // sent in the Cache-Control header
+
+	ConfigurationKey_WebhooksPollInterval = "webhooks.poll-interval"
+	ConfigurationKey_WebhooksTimeout      = "webhooks.timeout"
+	ConfigurationKey_WebhooksMaxAttempts  = "webhooks.max-attempts"
+	ConfigurationKey_WebhooksBackoff      = "webhooks.backoff"
+	ConfigurationKey_WebhooksRetention    = "webhooks.retention" // of the change events
+
+	ConfigurationKey_QualityExtentMinLongitude = "quality.extent.min-longitude"
+	ConfigurationKey_QualityExtentMinLatitude  = "quality.extent.min-latitude"
+	ConfigurationKey_QualityExtentMaxLongitude = "quality.extent.max-longitude"
+	ConfigurationKey_QualityExtentMaxLatitude  = "quality.extent.max-latitude"
+	ConfigurationKey_QualityMunicipalityLayer  = "quality.municipality-layer" // name of a reference layer
+	ConfigurationKey_QualityDuplicateDistance  = "quality.duplicate-distance" // in meters
+	ConfigurationKey_QualityRules              = "quality.rules"              // enabled flag and weight of the rules by their name
 )
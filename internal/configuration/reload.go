@@ -0,0 +1,174 @@
+package configuration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// OnReload registers a function that is called after the configuration has
+// been reloaded.
+// Values that are read for every usage from [configuration.Viper] do not need
+// a handler as they automatically use the reloaded configuration.
+func (c *configuration) OnReload(fn func()) {
+	c.reloadLock.Lock()
+	defer c.reloadLock.Unlock()
+	c.reloadHandlers = append(c.reloadHandlers, fn)
+}
+
+// Watch reloads the configuration once it changes until the context is
+// canceled.
+// Local configuration files are watched for changes while the paths in the
+// vault are read again periodically.
+func (c *configuration) Watch(ctx context.Context) {
+	switch c.t {
+	case ConfigurationType_Local:
+		c.watchLocalConfiguration(ctx)
+	case ConfigurationType_Vault:
+		c.pollVaultConfiguration(ctx)
+	}
+}
+
+func (c *configuration) watchLocalConfiguration(ctx context.Context) {
+	// a separate instance is used for watching the file as viper reads the
+	// changed file into the watched instance
+	watcher := viper.New()
+	if err := initializeLocalReading(watcher); err != nil {
+		slog.Warn("unable to watch configuration file", "error", err)
+		return
+	}
+	if err := watcher.ReadInConfig(); err != nil {
+		slog.Debug("no configuration file to watch", "error", err)
+		return
+	}
+
+	watcher.OnConfigChange(func(fsnotify.Event) {
+		c.reload()
+	})
+	watcher.WatchConfig()
+	slog.Debug("watching configuration file for changes", "file", watcher.ConfigFileUsed())
+
+	<-ctx.Done()
+}
+
+func (c *configuration) pollVaultConfiguration(ctx context.Context) {
+	ticker := time.NewTicker(c.Viper().GetDuration(ConfigurationKey_ReloadInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.reload()
+		}
+	}
+}
+
+// reload reads the configuration into a new instance and replaces the current
+// instance if the new configuration is valid.
+// Changes to the static keys are reverted and reported as they would only be
+// applied after a restart.
+func (c *configuration) reload() {
+	c.reloading.Lock()
+	defer c.reloading.Unlock()
+
+	next, err := c.newInstance()
+	if err != nil {
+		slog.Error("unable to reload configuration", "error", err)
+		return
+	}
+	if err := c.readInstance(next); err != nil {
+		slog.Error("unable to reload configuration", "error", err)
+		return
+	}
+
+	current := c.Viper()
+	if c.dynamicDatabaseCredentials() {
+		next.Set(ConfigurationKey_DatabaseUser, current.Get(ConfigurationKey_DatabaseUser))
+		next.Set(ConfigurationKey_DatabasePassword, current.Get(ConfigurationKey_DatabasePassword))
+	}
+
+	if err := validateInstance(next, c.dynamicDatabaseCredentials()); err != nil {
+		slog.Error("ignoring invalid configuration change", "error", err)
+		return
+	}
+
+	pendingRestart := make(map[string]string)
+	defer func() { c.pendingRestart = pendingRestart }()
+
+	changed := changedKeys(current, next)
+	if len(changed) == 0 {
+		return
+	}
+
+	applied := 0
+	for _, key := range changed {
+		oldValue, newValue := current.Get(key), next.Get(key)
+		if isSecret(key) {
+			oldValue, newValue = maskedValue, maskedValue
+		}
+
+		if isStatic(key) {
+			// the warning is only repeated if the value changes again
+			if c.pendingRestart[key] != fmt.Sprint(next.Get(key)) {
+				slog.Warn("configuration change requires a restart and has not been applied",
+					"key", key, "current", fmt.Sprint(oldValue), "new", fmt.Sprint(newValue))
+			}
+			pendingRestart[key] = fmt.Sprint(next.Get(key))
+			next.Set(key, current.Get(key))
+			continue
+		}
+
+		slog.Info("configuration changed", "key", key, "old", fmt.Sprint(oldValue), "new", fmt.Sprint(newValue))
+		applied++
+	}
+
+	if applied == 0 {
+		return
+	}
+
+	c.lock.Lock()
+	c.i = next
+	c.lock.Unlock()
+
+	c.reloadLock.Lock()
+	handlers := slices.Clone(c.reloadHandlers)
+	c.reloadLock.Unlock()
+
+	for _, fn := range handlers {
+		fn()
+	}
+	slog.Info("reloaded configuration", "changes", applied)
+}
+
+// changedKeys returns the keys whose values differ between both instances.
+func changedKeys(current, next *viper.Viper) []string {
+	keys := append(current.AllKeys(), next.AllKeys()...)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	var changed []string
+	for _, key := range keys {
+		if !reflect.DeepEqual(current.Get(key), next.Get(key)) {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
+
+func isStatic(key string) bool {
+	for _, static := range staticKeys {
+		if key == static || (strings.HasSuffix(static, ".") && strings.HasPrefix(key, static)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package configuration
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+// writeConfigurationFile writes the configuration file read from the working
+// directory.
+func writeConfigurationFile(t *testing.T, content string) {
+	t.Helper()
+
+	if err := os.WriteFile("config.yaml", []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newLocalConfiguration reads the local configuration from the working
+// directory.
+func newLocalConfiguration(t *testing.T) *configuration {
+	t.Helper()
+
+	c := &configuration{t: ConfigurationType_Local}
+	i, err := c.newInstance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.readInstance(i); err != nil {
+		t.Fatal(err)
+	}
+	c.i = i
+	return c
+}
+
+// captureLogs records the log messages written during the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+	})
+	return &logs
+}
+
+func TestReload(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeConfigurationFile(t, `
+database:
+  host: localhost
+  user: water-rights
+  password: initial-password
+http:
+  port: 8000
+log:
+  level: info
+redaction:
+  pseudonym-key: initial-key
+`)
+
+	c := newLocalConfiguration(t)
+
+	var reloaded int
+	c.OnReload(func() {
+		reloaded++
+	})
+
+	writeConfigurationFile(t, `
+database:
+  host: localhost
+  user: water-rights
+  password: changed-password
+http:
+  port: 9000
+log:
+  level: debug
+redaction:
+  pseudonym-key: changed-key
+`)
+	logs := captureLogs(t)
+	c.reload()
+
+	if reloaded != 1 {
+		t.Errorf("expected the reload handlers to be called once, got %d calls", reloaded)
+	}
+
+	config := c.Viper()
+	if level := config.GetString(ConfigurationKey_LogLevel); level != "debug" {
+		t.Errorf("expected the log level to be reloaded, got %s", level)
+	}
+	if key := config.GetString(ConfigurationKey_RedactionPseudonymKey); key != "changed-key" {
+		t.Errorf("expected the pseudonym key to be reloaded, got %s", key)
+	}
+
+	// static keys keep their value until the service is restarted
+	if port := config.GetInt(ConfigurationKey_HttpPort); port != 8000 {
+		t.Errorf("expected the http port to be kept, got %d", port)
+	}
+	if password := config.GetString(ConfigurationKey_DatabasePassword); password != "initial-password" {
+		t.Errorf("expected the database password to be kept, got %s", password)
+	}
+	if !strings.Contains(logs.String(), "requires a restart") ||
+		!strings.Contains(logs.String(), ConfigurationKey_HttpPort) {
+		t.Errorf("expected a warning about the changed http port, got %s", logs.String())
+	}
+
+	for _, secret := range []string{"initial-password", "changed-password", "initial-key", "changed-key"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Errorf("expected the secret %q to be masked in the logs, got %s", secret, logs.String())
+		}
+	}
+}
+
+func TestReloadWithoutChanges(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeConfigurationFile(t, `
+database:
+  host: localhost
+  user: water-rights
+  password: password
+`)
+
+	c := newLocalConfiguration(t)
+
+	initial := c.Viper()
+	c.OnReload(func() {
+		t.Error("expected the reload handlers not to be called")
+	})
+	c.reload()
+
+	if c.Viper() != initial {
+		t.Error("expected the configuration to be kept")
+	}
+}
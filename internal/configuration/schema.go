@@ -0,0 +1,326 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// maskedValue replaces the values of secret configuration keys when printing
+// the configuration.
+const maskedValue = "********"
+
+// The value types supported by the configuration schema.
+const (
+	typeString = iota
+	typeInt
+	typeFloat
+	typeBool
+	typeDuration
+	typeURL
+	typeMap
+)
+
+// field describes the allowed values of a single configuration key.
+type field struct {
+	kind     int
+	required bool
+	secret   bool
+
+	// allowed lists the accepted values of a string field. The values are
+	// compared case-insensitively
+	allowed []string
+
+	// min and max limit the values of numeric fields and durations if
+	// bounded is set
+	bounded  bool
+	min, max float64
+}
+
+// portRange contains the valid tcp ports.
+func portRange() field {
+	return field{kind: typeInt, bounded: true, min: 1, max: 65535} //nolint:mnd
+}
+
+// positiveDuration contains all durations longer than zero.
+func positiveDuration() field {
+	return field{kind: typeDuration, bounded: true, min: float64(time.Nanosecond), max: float64(1<<63 - 1)}
+}
+
+// nonNegativeDuration contains all durations including zero.
+func nonNegativeDuration() field {
+	return field{kind: typeDuration, bounded: true, min: 0, max: float64(1<<63 - 1)}
+}
+
+// sslModes contains the ssl modes supported by PostgreSQL.
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+var credentialTypes = []string{DatabaseCredentialType_Static, DatabaseCredentialType_Dynamic}
+
+// schema describes the values of all configuration keys used by the service.
+var schema = map[string]field{
+	ConfigurationKey_DatabaseUser:            {kind: typeString, required: true},
+	ConfigurationKey_DatabasePassword:        {kind: typeString, required: true, secret: true},
+	ConfigurationKey_DatabaseHost:            {kind: typeString, required: true},
+	ConfigurationKey_DatabasePort:            portRange(),
+	ConfigurationKey_DatabaseSSLMode:         {kind: typeString, allowed: sslModes},
+	ConfigurationKey_DatabaseName:            {kind: typeString, required: true},
+	ConfigurationKey_DatabaseCredentialType:  {kind: typeString, allowed: credentialTypes},
+	ConfigurationKey_DatabaseCredentialRole:  {kind: typeString},
+	ConfigurationKey_DatabaseCredentialMount: {kind: typeString},
+
+	ConfigurationKey_HttpHost: {kind: typeString},
+	ConfigurationKey_HttpPort: portRange(),
+
+	ConfigurationKey_LogLevel:  {kind: typeString, allowed: []string{"debug", "info", "warn", "error"}},
+	ConfigurationKey_LogFormat: {kind: typeString, allowed: []string{"text", "json"}},
+
+	ConfigurationKey_ReloadInterval: positiveDuration(),
+
+	ConfigurationKey_OidcAuthority:         {kind: typeURL},
+	ConfigurationKey_OidcAudience:          {kind: typeString},
+	ConfigurationKey_AuthorizationRequired: {kind: typeBool},
+
+	ConfigurationKey_AccessPolicyEnabled: {kind: typeBool},
+	ConfigurationKey_AccessPolicyClaim:   {kind: typeString},
+	ConfigurationKey_AccessPolicyRules:   {kind: typeMap},
+
+	ConfigurationKey_RedactionEnabled:      {kind: typeBool},
+	ConfigurationKey_RedactionMode:         {kind: typeString, allowed: []string{"null", "pseudonymize"}},
+	ConfigurationKey_RedactionPseudonymKey: {kind: typeString, secret: true},
+
+	ConfigurationKey_TracingEnabled:     {kind: typeBool},
+	ConfigurationKey_TracingExporter:    {kind: typeString, allowed: []string{"otlp-grpc", "otlp-http"}},
+	ConfigurationKey_TracingEndpoint:    {kind: typeURL},
+	ConfigurationKey_TracingInsecure:    {kind: typeBool},
+	ConfigurationKey_TracingSampleRatio: {kind: typeFloat, bounded: true, min: 0, max: 1},
+
+	ConfigurationKey_CacheEnabled: {kind: typeBool},
+	ConfigurationKey_CacheTTL:     positiveDuration(),
+	ConfigurationKey_CacheMaxSize: {kind: typeInt, bounded: true, min: 0, max: float64(1<<63 - 1)},
+	ConfigurationKey_CacheMaxAge:  nonNegativeDuration(),
+
+	ConfigurationKey_WebhooksPollInterval: positiveDuration(),
+	ConfigurationKey_WebhooksTimeout:      positiveDuration(),
+	ConfigurationKey_WebhooksMaxAttempts:  {kind: typeInt, bounded: true, min: 1, max: 100}, //nolint:mnd
+	ConfigurationKey_WebhooksBackoff:      nonNegativeDuration(),
+	ConfigurationKey_WebhooksRetention:    positiveDuration(),
+
+	ConfigurationKey_QualityExtentMinLongitude: {kind: typeFloat, bounded: true, min: -180, max: 180},
+	ConfigurationKey_QualityExtentMinLatitude:  {kind: typeFloat, bounded: true, min: -90, max: 90},
+	ConfigurationKey_QualityExtentMaxLongitude: {kind: typeFloat, bounded: true, min: -180, max: 180},
+	ConfigurationKey_QualityExtentMaxLatitude:  {kind: typeFloat, bounded: true, min: -90, max: 90},
+	ConfigurationKey_QualityMunicipalityLayer:  {kind: typeString},
+	ConfigurationKey_QualityDuplicateDistance:  {kind: typeFloat, bounded: true, min: 0, max: 10000}, //nolint:mnd
+	ConfigurationKey_QualityRules:              {kind: typeMap},
+}
+
+// secretKeyFragments are used to detect secret values in configuration keys
+// that are not part of the schema.
+var secretKeyFragments = []string{"password", "secret", "token", "key"}
+
+// ValidationErrors contains all problems found while validating the
+// configuration.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for idx, err := range e {
+		messages[idx] = err.Error()
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+// Validate checks the values of all configuration keys against the schema.
+// All problems are collected and returned as [ValidationErrors].
+func (c *configuration) Validate() error {
+	return validateInstance(c.Viper(), c.issuesDatabaseCredentials())
+}
+
+// validateInstance checks the values of the instance against the schema.
+// The database credentials are not required if they are issued by the vault
+// as they are only set once they have been requested.
+func validateInstance(i *viper.Viper, issuedCredentials bool) error {
+	var errs ValidationErrors
+
+	keys := make([]string, 0, len(schema))
+	for key := range schema {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		f := schema[key]
+		if issuedCredentials && (key == ConfigurationKey_DatabaseUser || key == ConfigurationKey_DatabasePassword) {
+			f.required = false
+		}
+		if err := f.validate(i.Get(key)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+
+	authority := strings.TrimSpace(i.GetString(ConfigurationKey_OidcAuthority))
+	if i.GetBool(ConfigurationKey_AuthorizationRequired) && authority == "" {
+		errs = append(errs, fmt.Errorf("%s: required if %s is enabled",
+			ConfigurationKey_OidcAuthority, ConfigurationKey_AuthorizationRequired))
+	}
+
+	audience := strings.TrimSpace(i.GetString(ConfigurationKey_OidcAudience))
+	if i.GetBool(ConfigurationKey_AuthorizationRequired) && audience == "" {
+		errs = append(errs, fmt.Errorf("%s: required if %s is enabled",
+			ConfigurationKey_OidcAudience, ConfigurationKey_AuthorizationRequired))
+	}
+
+	// a generated key would differ between the instances and restarts which
+	// breaks the correlation of the pseudonyms
+	pseudonymKey := strings.TrimSpace(i.GetString(ConfigurationKey_RedactionPseudonymKey))
+	if RedactionEnabled(i) &&
+		strings.EqualFold(i.GetString(ConfigurationKey_RedactionMode), "pseudonymize") && pseudonymKey == "" {
+		errs = append(errs, fmt.Errorf("%s: required if %s is enabled using the pseudonymize mode",
+			ConfigurationKey_RedactionPseudonymKey, ConfigurationKey_RedactionEnabled))
+	}
+
+	// bounds that are not numbers have already been reported above
+	for _, bounds := range [][2]string{
+		{ConfigurationKey_QualityExtentMinLongitude, ConfigurationKey_QualityExtentMaxLongitude},
+		{ConfigurationKey_QualityExtentMinLatitude, ConfigurationKey_QualityExtentMaxLatitude},
+	} {
+		lower, lowerErr := cast.ToFloat64E(i.Get(bounds[0]))
+		upper, upperErr := cast.ToFloat64E(i.Get(bounds[1]))
+		if lowerErr == nil && upperErr == nil && lower >= upper {
+			errs = append(errs, fmt.Errorf("%s: must be less than %s", bounds[0], bounds[1]))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (f field) validate(value any) error {
+	if value == nil || value == "" {
+		if f.required {
+			return errors.New("required but not set")
+		}
+		return nil
+	}
+
+	var number float64
+	switch f.kind {
+	case typeString:
+		s, err := cast.ToStringE(value)
+		if err != nil {
+			return errors.New("expected a string")
+		}
+		if len(f.allowed) > 0 && !slices.Contains(f.allowed, strings.ToLower(s)) {
+			return fmt.Errorf("unsupported value %q, expected one of: %s", s, strings.Join(f.allowed, ", "))
+		}
+		return nil
+	case typeInt:
+		i, err := cast.ToIntE(value)
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", fmt.Sprint(value))
+		}
+		number = float64(i)
+	case typeFloat:
+		n, err := cast.ToFloat64E(value)
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", fmt.Sprint(value))
+		}
+		number = n
+	case typeBool:
+		if _, err := cast.ToBoolE(value); err != nil {
+			return fmt.Errorf("expected a boolean, got %q", fmt.Sprint(value))
+		}
+		return nil
+	case typeDuration:
+		d, err := cast.ToDurationE(value)
+		if err != nil {
+			return fmt.Errorf("expected a duration (e.g., 15s), got %q", fmt.Sprint(value))
+		}
+		number = float64(d)
+	case typeURL:
+		s, err := cast.ToStringE(value)
+		if err != nil {
+			return errors.New("expected a url")
+		}
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("expected an absolute http(s) url, got %q", s)
+		}
+		return nil
+	case typeMap:
+		if _, err := cast.ToStringMapE(value); err != nil {
+			return errors.New("expected a mapping")
+		}
+		return nil
+	}
+
+	if f.bounded && (number < f.min || number > f.max) {
+		if f.kind == typeDuration && f.min > 0 {
+			return fmt.Errorf("expected a duration longer than 0s, got %s", time.Duration(number))
+		}
+		if f.kind == typeDuration {
+			return fmt.Errorf("expected a duration of at least 0s, got %s", time.Duration(number))
+		}
+		return fmt.Errorf("%v is out of range [%v, %v]", number, f.min, f.max)
+	}
+	return nil
+}
+
+// Effective returns all configuration values with the values of secret keys
+// being masked.
+func (c *configuration) Effective() map[string]any {
+	return maskSecrets("", c.Viper().AllSettings())
+}
+
+func maskSecrets(prefix string, settings map[string]any) map[string]any {
+	masked := make(map[string]any, len(settings))
+	for key, value := range settings {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		if nested, isMap := value.(map[string]any); isMap {
+			masked[key] = maskSecrets(path, nested)
+			continue
+		}
+
+		if isSecret(path) && value != nil && value != "" {
+			masked[key] = maskedValue
+			continue
+		}
+		masked[key] = value
+	}
+	return masked
+}
+
+func isSecret(key string) bool {
+	if f, known := schema[key]; known {
+		return f.secret
+	}
+	for _, fragment := range secretKeyFragments {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactionEnabled reports if the personal data needs to be redacted.
+// The redaction is enabled whenever the authorization is required, unless it
+// has been disabled explicitly.
+func RedactionEnabled(i *viper.Viper) bool {
+	if i.IsSet(ConfigurationKey_RedactionEnabled) {
+		return i.GetBool(ConfigurationKey_RedactionEnabled)
+	}
+	return i.GetBool(ConfigurationKey_AuthorizationRequired)
+}
@@ -0,0 +1,165 @@
+package configuration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// newTestInstance returns an instance containing the defaults, a database
+// connection and the supplied values.
+func newTestInstance(values map[string]any) *viper.Viper {
+	i := viper.New()
+	setupDefaults(i)
+	i.Set(ConfigurationKey_DatabaseHost, "localhost")
+	i.Set(ConfigurationKey_DatabaseUser, "water-rights")
+	i.Set(ConfigurationKey_DatabasePassword, "password")
+	for key, value := range values {
+		i.Set(key, value)
+	}
+	return i
+}
+
+func TestValidateInstance(t *testing.T) {
+	tests := []struct {
+		name              string
+		values            map[string]any
+		issuedCredentials bool
+		invalid           string
+	}{
+		{
+			name:   "defaults",
+			values: nil,
+		},
+		{
+			name: "redaction removing the personal data",
+			values: map[string]any{
+				ConfigurationKey_RedactionEnabled: true,
+			},
+		},
+		{
+			name: "pseudonymization without key",
+			values: map[string]any{
+				ConfigurationKey_RedactionEnabled: true,
+				ConfigurationKey_RedactionMode:    "pseudonymize",
+			},
+			invalid: ConfigurationKey_RedactionPseudonymKey,
+		},
+		{
+			name: "pseudonymization with key",
+			values: map[string]any{
+				ConfigurationKey_RedactionEnabled:      true,
+				ConfigurationKey_RedactionMode:         "pseudonymize",
+				ConfigurationKey_RedactionPseudonymKey: "secret",
+			},
+		},
+		{
+			name: "pseudonymization without key using the required authorization",
+			values: map[string]any{
+				ConfigurationKey_AuthorizationRequired: true,
+				ConfigurationKey_OidcAuthority:         "https://auth.example.com",
+				ConfigurationKey_OidcAudience:          "water-rights",
+				ConfigurationKey_RedactionMode:         "pseudonymize",
+			},
+			invalid: ConfigurationKey_RedactionPseudonymKey,
+		},
+		{
+			name: "pseudonymization without key and disabled redaction",
+			values: map[string]any{
+				ConfigurationKey_AuthorizationRequired: true,
+				ConfigurationKey_OidcAuthority:         "https://auth.example.com",
+				ConfigurationKey_OidcAudience:          "water-rights",
+				ConfigurationKey_RedactionEnabled:      false,
+				ConfigurationKey_RedactionMode:         "pseudonymize",
+			},
+		},
+		{
+			name: "missing database credentials",
+			values: map[string]any{
+				ConfigurationKey_DatabaseUser: "",
+			},
+			invalid: ConfigurationKey_DatabaseUser,
+		},
+		{
+			name: "database credentials issued by the vault",
+			values: map[string]any{
+				ConfigurationKey_DatabaseUser:     "",
+				ConfigurationKey_DatabasePassword: "",
+			},
+			issuedCredentials: true,
+		},
+		{
+			name: "inverted extent longitudes",
+			values: map[string]any{
+				ConfigurationKey_QualityExtentMinLongitude: 11.7,
+				ConfigurationKey_QualityExtentMaxLongitude: 6.6,
+			},
+			invalid: ConfigurationKey_QualityExtentMinLongitude + ": must be less than",
+		},
+		{
+			name: "empty extent latitudes",
+			values: map[string]any{
+				ConfigurationKey_QualityExtentMinLatitude: 52.5,
+				ConfigurationKey_QualityExtentMaxLatitude: "52.5",
+			},
+			invalid: ConfigurationKey_QualityExtentMinLatitude + ": must be less than",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateInstance(newTestInstance(test.values), test.issuedCredentials)
+			switch {
+			case test.invalid == "" && err != nil:
+				t.Errorf("expected a valid configuration, got %v", err)
+			case test.invalid != "" && (err == nil || !strings.Contains(err.Error(), test.invalid)):
+				t.Errorf("expected %s to be invalid, got %v", test.invalid, err)
+			}
+		})
+	}
+}
+
+func TestRedactionEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   map[string]any
+		expected bool
+	}{
+		{
+			name:     "defaults",
+			values:   nil,
+			expected: false,
+		},
+		{
+			name: "required authorization",
+			values: map[string]any{
+				ConfigurationKey_AuthorizationRequired: true,
+			},
+			expected: true,
+		},
+		{
+			name: "required authorization and disabled redaction",
+			values: map[string]any{
+				ConfigurationKey_AuthorizationRequired: true,
+				ConfigurationKey_RedactionEnabled:      false,
+			},
+			expected: false,
+		},
+		{
+			name: "enabled redaction without authorization",
+			values: map[string]any{
+				ConfigurationKey_RedactionEnabled: true,
+			},
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if enabled := RedactionEnabled(newTestInstance(test.values)); enabled != test.expected {
+				t.Errorf("expected the redaction to be %t, got %t", test.expected, enabled)
+			}
+		})
+	}
+}
@@ -24,13 +24,73 @@ var environmentVariables = map[string][]string{
 		"DATABASE_SSL_MODE",
 	},
 	ConfigurationKey_HttpPort:              {"HTTP_PORT"},
+	ConfigurationKey_LogLevel:              {"LOG_LEVEL"},
+	ConfigurationKey_LogFormat:             {"LOG_FORMAT"},
 	ConfigurationKey_AuthorizationRequired: {"AUTH_REQUIRED", "AUTHORIZATION_REQUIRED"},
 	ConfigurationKey_OidcAuthority:         {"OIDC_AUTHORITY", "OIDC_ISSUER"},
+	ConfigurationKey_OidcAudience:          {"OIDC_AUDIENCE"},
+}
+
+// deprecatedKeys maps configuration keys that have been renamed to their
+// current key to keep existing configuration files working.
+var deprecatedKeys = map[string]string{
+	"oidc.auhority": ConfigurationKey_OidcAuthority,
+}
+
+// staticKeys contains the keys and key prefixes of the configuration values
+// that are only read during the startup of the service. Changes to them are
+// not applied when reloading the configuration.
+var staticKeys = []string{
+	"database.",
+	"http.",
+	"oidc.",
+	"authorization.",
+	"tracing.",
+	"webhooks.",
+	"reload.",
+	ConfigurationKey_LogFormat,
 }
 
 var defaults = map[string]any{
 	ConfigurationKey_DatabasePort:    5432, //nolint:mnd
 	ConfigurationKey_DatabaseSSLMode: "disable",
 	ConfigurationKey_DatabaseName:    "wisdom",
-	ConfigurationKey_HttpPort:        8000, //nolint:mnd
+
+	ConfigurationKey_DatabaseCredentialMount: "databases",
+	ConfigurationKey_HttpPort:                8000, //nolint:mnd
+
+	ConfigurationKey_LogLevel:  "info",
+	ConfigurationKey_LogFormat: "text",
+
+	ConfigurationKey_ReloadInterval: "1m",
+
+	ConfigurationKey_AccessPolicyEnabled: false,
+	ConfigurationKey_AccessPolicyClaim:   "groups",
+
+	// redaction.enabled has no default as it follows authorization.required
+	// unless it has been set explicitly
+	ConfigurationKey_RedactionMode: "null",
+
+	ConfigurationKey_TracingEnabled:     false,
+	ConfigurationKey_TracingExporter:    "otlp-grpc",
+	ConfigurationKey_TracingSampleRatio: 1.0,
+
+	ConfigurationKey_CacheEnabled: true,
+	ConfigurationKey_CacheTTL:     "10m",
+	ConfigurationKey_CacheMaxSize: 64 << 20, //nolint:mnd
+	ConfigurationKey_CacheMaxAge:  "0s",
+
+	ConfigurationKey_WebhooksPollInterval: "15s",
+	ConfigurationKey_WebhooksTimeout:      "10s",
+	ConfigurationKey_WebhooksMaxAttempts:  5, //nolint:mnd
+	ConfigurationKey_WebhooksBackoff:      "2s",
+	ConfigurationKey_WebhooksRetention:    "720h",
+
+	// the bounding box of Lower Saxony including the islands
+	ConfigurationKey_QualityExtentMinLongitude: 6.6,
+	ConfigurationKey_QualityExtentMinLatitude:  51.2,
+	ConfigurationKey_QualityExtentMaxLongitude: 11.7,
+	ConfigurationKey_QualityExtentMaxLatitude:  54.0,
+	ConfigurationKey_QualityMunicipalityLayer:  "municipalities",
+	ConfigurationKey_QualityDuplicateDistance:  1.0,
 }
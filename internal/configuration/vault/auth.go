@@ -0,0 +1,152 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/api/auth/approle"
+	"github.com/hashicorp/vault/api/auth/kubernetes"
+	"github.com/hashicorp/vault/api/auth/userpass"
+)
+
+// The supported methods to authenticate with the vault.
+const (
+	AuthMethodToken      = "token"
+	AuthMethodUserpass   = "userpass"
+	AuthMethodAppRole    = "approle"
+	AuthMethodKubernetes = "kubernetes"
+)
+
+// The environment variables configuring the authentication.
+// The authentication can not be configured using the remaining configuration
+// as the configuration is read from the vault itself.
+const (
+	EnvVaultAuthMethod          = "VAULT_AUTH_METHOD"
+	EnvVaultAuthMount           = "VAULT_AUTH_MOUNT"
+	EnvVaultRoleID              = "VAULT_ROLE_ID"
+	EnvVaultSecretID            = "VAULT_SECRET_ID"
+	EnvVaultSecretIDFile        = "VAULT_SECRET_ID_FILE"
+	EnvVaultKubernetesRole      = "VAULT_KUBERNETES_ROLE"
+	EnvVaultKubernetesTokenPath = "VAULT_KUBERNETES_TOKEN_PATH"
+)
+
+// ErrTokenNotSet is returned if the token authentication is used without a
+// token in the environment.
+var ErrTokenNotSet = fmt.Errorf("no token set in %s", api.EnvVaultToken)
+
+// AuthMethodFromEnvironment creates the method used to authenticate with the
+// vault from the environment variables.
+// The userpass authentication is used if no method has been set.
+func AuthMethodFromEnvironment() (api.AuthMethod, error) {
+	method := strings.ToLower(strings.TrimSpace(os.Getenv(EnvVaultAuthMethod)))
+	mount := strings.TrimSpace(os.Getenv(EnvVaultAuthMount))
+
+	switch method {
+	case AuthMethodToken:
+		token, set := os.LookupEnv(api.EnvVaultToken)
+		if !set || token == "" {
+			return nil, ErrTokenNotSet
+		}
+		return NewTokenAuth(token), nil
+	case AuthMethodUserpass, "":
+		username, set := os.LookupEnv(EnvVaultUsername)
+		if !set {
+			return nil, fmt.Errorf("no username set in %s", EnvVaultUsername)
+		}
+		var opts []userpass.LoginOption
+		if mount != "" {
+			opts = append(opts, userpass.WithMountPath(mount))
+		}
+		return userpass.NewUserpassAuth(username, &userpass.Password{FromEnv: EnvVaultPassword}, opts...)
+	case AuthMethodAppRole:
+		roleID, set := os.LookupEnv(EnvVaultRoleID)
+		if !set {
+			return nil, fmt.Errorf("no role id set in %s", EnvVaultRoleID)
+		}
+		secretID := &approle.SecretID{FromEnv: EnvVaultSecretID}
+		if file, set := os.LookupEnv(EnvVaultSecretIDFile); set {
+			secretID = &approle.SecretID{FromFile: file}
+		}
+		var opts []approle.LoginOption
+		if mount != "" {
+			opts = append(opts, approle.WithMountPath(mount))
+		}
+		return approle.NewAppRoleAuth(roleID, secretID, opts...)
+	case AuthMethodKubernetes:
+		role, set := os.LookupEnv(EnvVaultKubernetesRole)
+		if !set {
+			return nil, fmt.Errorf("no role set in %s", EnvVaultKubernetesRole)
+		}
+		var opts []kubernetes.LoginOption
+		if mount != "" {
+			opts = append(opts, kubernetes.WithMountPath(mount))
+		}
+		if path, set := os.LookupEnv(EnvVaultKubernetesTokenPath); set {
+			opts = append(opts, kubernetes.WithServiceAccountTokenPath(path))
+		}
+		return kubernetes.NewKubernetesAuth(role, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported vault authentication method set in %s: %s", EnvVaultAuthMethod, method)
+	}
+}
+
+// TokenAuth authenticates with an already issued token.
+// The login looks up the token to allow renewing it like the tokens issued
+// by the other authentication methods.
+type TokenAuth struct {
+	token string
+}
+
+// NewTokenAuth creates the authentication using the supplied token.
+func NewTokenAuth(token string) *TokenAuth {
+	return &TokenAuth{token: token}
+}
+
+// Login validates the token and returns its properties as login secret.
+func (a *TokenAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	if a.token == "" {
+		return nil, ErrTokenNotSet
+	}
+
+	// the lookup is executed with a copy of the client to not replace the
+	// token of the supplied client before the token has been validated
+	lookupClient, err := client.Clone()
+	if err != nil {
+		return nil, err
+	}
+	lookupClient.SetToken(a.token)
+
+	secret, err := lookupClient.Auth().Token().LookupSelfWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to look up token: %w", err)
+	}
+	if secret == nil {
+		return nil, errors.New("unable to look up token: empty response")
+	}
+
+	renewable, err := secret.TokenIsRenewable()
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := secret.TokenTTL()
+	if err != nil {
+		return nil, err
+	}
+	policies, err := secret.TokenPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.Secret{
+		Auth: &api.SecretAuth{
+			ClientToken:   a.token,
+			Policies:      policies,
+			Renewable:     renewable,
+			LeaseDuration: int(ttl.Seconds()),
+		},
+	}, nil
+}
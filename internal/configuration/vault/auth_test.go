@@ -0,0 +1,140 @@
+package vault
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+func TestAuthMethodFromEnvironment(t *testing.T) {
+	secretIDFile := filepath.Join(t.TempDir(), "secret-id")
+	if err := os.WriteFile(secretIDFile, []byte("file-secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	serviceAccountToken := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(serviceAccountToken, []byte("service-account-jwt"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		path  string
+		data  map[string]any
+		token string
+	}{
+		{
+			name:  "token",
+			env:   map[string]string{EnvVaultAuthMethod: AuthMethodToken, api.EnvVaultToken: "static-token"},
+			path:  "/v1/auth/token/lookup-self",
+			data:  map[string]any{"token": "static-token"},
+			token: "static-token",
+		},
+		{
+			name:  "userpass",
+			env:   map[string]string{EnvVaultUsername: "water-rights", EnvVaultPassword: "password"},
+			path:  "/v1/auth/userpass/login/water-rights",
+			data:  map[string]any{"password": "password"},
+			token: "token-1",
+		},
+		{
+			name: "approle",
+			env: map[string]string{
+				EnvVaultAuthMethod: AuthMethodAppRole,
+				EnvVaultAuthMount:  "services",
+				EnvVaultRoleID:     "role",
+				EnvVaultSecretID:   "secret",
+			},
+			path:  "/v1/auth/services/login",
+			data:  map[string]any{"role_id": "role", "secret_id": "secret"},
+			token: "token-1",
+		},
+		{
+			name: "approle with secret id file",
+			env: map[string]string{
+				EnvVaultAuthMethod:   AuthMethodAppRole,
+				EnvVaultRoleID:       "role",
+				EnvVaultSecretIDFile: secretIDFile,
+			},
+			path:  "/v1/auth/approle/login",
+			data:  map[string]any{"role_id": "role", "secret_id": "file-secret"},
+			token: "token-1",
+		},
+		{
+			name: "kubernetes",
+			env: map[string]string{
+				EnvVaultAuthMethod:          AuthMethodKubernetes,
+				EnvVaultKubernetesRole:      "water-rights",
+				EnvVaultKubernetesTokenPath: serviceAccountToken,
+			},
+			path:  "/v1/auth/kubernetes/login",
+			data:  map[string]any{"role": "water-rights", "jwt": "service-account-jwt"},
+			token: "token-1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// the login exports the token for the remote configuration
+			t.Setenv(api.EnvVaultToken, "")
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+
+			auth, err := AuthMethodFromEnvironment()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fake := newFakeVault(t, 60, time.Hour)
+			v := New(fake.client(t), auth)
+			if err := v.Login(); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(fake.logins) != 1 {
+				t.Fatalf("expected a single login, got %d", len(fake.logins))
+			}
+			l := fake.logins[0]
+			if l.path != test.path {
+				t.Errorf("expected the login at %s, got %s", test.path, l.path)
+			}
+			for key, value := range test.data {
+				if l.data[key] != value {
+					t.Errorf("expected %s to be %v, got %v", key, value, l.data[key])
+				}
+			}
+
+			if v.l.Auth.ClientToken != test.token || v.c.Token() != test.token {
+				t.Errorf("expected the client to use the token %s, got %s", test.token, v.c.Token())
+			}
+			if v.l.Auth.LeaseDuration != 60 {
+				t.Errorf("expected the lease duration of the token, got %d", v.l.Auth.LeaseDuration)
+			}
+			if os.Getenv(api.EnvVaultToken) != test.token {
+				t.Errorf("expected the token to be exported into %s", api.EnvVaultToken)
+			}
+		})
+	}
+}
+
+func TestAuthMethodFromEnvironmentErrors(t *testing.T) {
+	t.Run("token without token", func(t *testing.T) {
+		t.Setenv(EnvVaultAuthMethod, AuthMethodToken)
+		t.Setenv(api.EnvVaultToken, "")
+		if _, err := AuthMethodFromEnvironment(); !errors.Is(err, ErrTokenNotSet) {
+			t.Errorf("expected %v, got %v", ErrTokenNotSet, err)
+		}
+	})
+
+	t.Run("unsupported method", func(t *testing.T) {
+		t.Setenv(EnvVaultAuthMethod, "ldap")
+		if _, err := AuthMethodFromEnvironment(); err == nil {
+			t.Error("expected an unsupported method to be refused")
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// reissueDelay is the time waited before retrying to issue new database
+// credentials after the vault refused to issue them.
+const reissueDelay = 30 * time.Second
+
+// ErrIncompleteDatabaseCredentials is returned if the vault issued database
+// credentials without a username or password.
+var ErrIncompleteDatabaseCredentials = errors.New("vault issued database credentials without username or password")
+
+// DatabaseCredentials returns the current database credentials.
+// New credentials are requested from the database secrets engine mounted at
+// mount using the supplied role if no credentials have been issued yet.
+func (v *Vault) DatabaseCredentials(mount, role string) (username, password string, err error) {
+	v.lock.RLock()
+	issued := v.dbCreds != nil
+	username, password = v.dbUsername, v.dbPassword
+	v.lock.RUnlock()
+
+	if issued {
+		return username, password, nil
+	}
+
+	if err := v.IssueDatabaseCredentials(mount, role); err != nil {
+		return "", "", err
+	}
+
+	username, password = v.CurrentDatabaseCredentials()
+	return username, password, nil
+}
+
+// CurrentDatabaseCredentials returns the most recently issued database
+// credentials without requesting new credentials.
+func (v *Vault) CurrentDatabaseCredentials() (username, password string) {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	return v.dbUsername, v.dbPassword
+}
+
+// IssueDatabaseCredentials requests new database credentials and replaces
+// the current credentials with them.
+// The lease of the previous credentials is left untouched and expires on its
+// own to allow connections using them to finish their work.
+func (v *Vault) IssueDatabaseCredentials(mount, role string) error {
+	path, err := url.JoinPath(mount, "/creds/", role)
+	if err != nil {
+		return err
+	}
+
+	issuedAt := time.Now()
+	secret, err := v.c.Logical().ReadWithContext(v.ctx, path)
+	if err != nil {
+		return fmt.Errorf("unable to get database credentials: %w", err)
+	}
+	if secret == nil {
+		return fmt.Errorf("unable to get database credentials: no secret at %s", path)
+	}
+
+	username, usernameOk := secret.Data["username"].(string)
+	password, passwordOk := secret.Data["password"].(string)
+	if !usernameOk || !passwordOk || username == "" || password == "" {
+		return ErrIncompleteDatabaseCredentials
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.dbCreds = secret
+	v.dbUsername = username
+	v.dbPassword = password
+	v.dbCredsExpiry = issuedAt.Add(time.Duration(secret.LeaseDuration) * time.Second)
+	return nil
+}
+
+// DatabaseCredentialsExpiry returns the time at which the lease of the
+// database credentials ends.
+// The second return value is false if no credentials have been issued yet.
+func (v *Vault) DatabaseCredentialsExpiry() (time.Time, bool) {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	return v.dbCredsExpiry, !v.dbCredsExpiry.IsZero()
+}
+
+// AutoRenewDatabaseCredentials keeps the database credentials valid until the
+// context is canceled.
+// The lease of the credentials is renewed until it approaches its maximal
+// lifetime. New credentials are issued before the lease ends and rotated is
+// called afterward to allow the users of the credentials to switch to the new
+// credentials.
+func (v *Vault) AutoRenewDatabaseCredentials(ctx context.Context, mount, role string, rotated func()) {
+	for {
+		v.lock.RLock()
+		secret := v.dbCreds
+		v.lock.RUnlock()
+
+		if secret != nil && (secret.LeaseID == "" || secret.LeaseDuration == 0) {
+			// credentials without a lease do not expire and need no rotation
+			<-ctx.Done()
+			return
+		}
+
+		if secret != nil {
+			if err := v.watchDatabaseLease(ctx, secret); err != nil {
+				slog.Warn("unable to renew database credentials", "error", err)
+			}
+		}
+
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+
+			err := v.IssueDatabaseCredentials(mount, role)
+			if err == nil {
+				break
+			}
+			slog.Warn("unable to issue new database credentials", "error", err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reissueDelay):
+			}
+		}
+
+		slog.Info("issued new database credentials")
+		if rotated != nil {
+			rotated()
+		}
+	}
+}
+
+// watchDatabaseLease renews the lease of the database credentials until the
+// lease approaches its maximal lifetime or the context is canceled.
+func (v *Vault) watchDatabaseLease(ctx context.Context, secret *api.Secret) error {
+	w, err := v.c.NewLifetimeWatcher(&api.LifetimeWatcherInput{
+		Secret:    secret,
+		Increment: int(newLeaseTTL.Seconds()),
+		// the watcher keeps renewing the lease until the lifetime threshold
+		// is reached, allowing new credentials to be issued in time
+		RenewBehavior: api.RenewBehaviorIgnoreErrors,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to instantiate new lifetime watcher: %w", err)
+	}
+
+	go w.Start()
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-w.DoneCh():
+			return err
+		case renewal := <-w.RenewCh():
+			if renewal == nil || renewal.Secret == nil {
+				continue
+			}
+			v.lock.Lock()
+			if v.dbCreds == secret {
+				v.dbCredsExpiry = renewal.RenewedAt.Add(time.Duration(renewal.Secret.LeaseDuration) * time.Second)
+			}
+			v.lock.Unlock()
+			slog.Debug("renewed database credentials", "leaseDuration", renewal.Secret.LeaseDuration)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package vault
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDatabaseCredentials(t *testing.T) {
+	fake := newFakeVault(t, 60, time.Hour)
+	v := New(fake.client(t), nil)
+
+	for range 2 {
+		username, password, err := v.DatabaseCredentials(testDatabaseMount, testDatabaseRole)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if username != "v-water-rights-1" || password != "password-1" {
+			t.Errorf("unexpected credentials %s:%s", username, password)
+		}
+	}
+
+	if issued, _ := fake.counts(); issued != 1 {
+		t.Errorf("expected the credentials to be issued once, got %d", issued)
+	}
+
+	expiry, issued := v.DatabaseCredentialsExpiry()
+	if !issued || time.Until(expiry) <= 50*time.Second {
+		t.Errorf("expected the credentials to expire with their lease, got %s", expiry)
+	}
+}
+
+func TestAutoRenewDatabaseCredentialsRenewsLease(t *testing.T) {
+	fake := newFakeVault(t, 2, time.Hour)
+	v := New(fake.client(t), nil)
+	if err := v.IssueDatabaseCredentials(testDatabaseMount, testDatabaseRole); err != nil {
+		t.Fatal(err)
+	}
+	initialExpiry, _ := v.DatabaseCredentialsExpiry()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		v.AutoRenewDatabaseCredentials(ctx, testDatabaseMount, testDatabaseRole, func() {
+			t.Error("expected the credentials not to be rotated")
+		})
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, renewals := fake.counts(); renewals > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the lease to be renewed")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if issued, _ := fake.counts(); issued != 1 {
+		t.Errorf("expected no new credentials, got %d issued credentials", issued)
+	}
+	if expiry, _ := v.DatabaseCredentialsExpiry(); !expiry.After(initialExpiry) {
+		t.Errorf("expected the renewal to extend the expiry %s, got %s", initialExpiry, expiry)
+	}
+}
+
+func TestAutoRenewDatabaseCredentialsReissuesAfterMaxTTL(t *testing.T) {
+	fake := newFakeVault(t, 2, 3*time.Second)
+	v := New(fake.client(t), nil)
+	if err := v.IssueDatabaseCredentials(testDatabaseMount, testDatabaseRole); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rotated := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		v.AutoRenewDatabaseCredentials(ctx, testDatabaseMount, testDatabaseRole, func() {
+			rotated <- struct{}{}
+		})
+	}()
+
+	select {
+	case <-rotated:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the credentials to be rotated")
+	}
+	cancel()
+	<-done
+
+	issued, renewals := fake.counts()
+	if issued != 2 {
+		t.Errorf("expected new credentials to be issued once, got %d issued credentials", issued)
+	}
+	if renewals == 0 {
+		t.Error("expected the lease to be renewed before reaching the maximal lifetime")
+	}
+	if username, password := v.CurrentDatabaseCredentials(); username != "v-water-rights-2" || password != "password-2" {
+		t.Errorf("expected the new credentials to be used, got %s:%s", username, password)
+	}
+}
+
+func TestAutoRenewDatabaseCredentialsWithoutLease(t *testing.T) {
+	fake := newFakeVault(t, 0, time.Hour)
+	v := New(fake.client(t), nil)
+	if err := v.IssueDatabaseCredentials(testDatabaseMount, testDatabaseRole); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	v.AutoRenewDatabaseCredentials(ctx, testDatabaseMount, testDatabaseRole, func() {
+		t.Error("expected credentials without a lease not to be rotated")
+	})
+
+	if issued, renewals := fake.counts(); issued != 1 || renewals != 0 {
+		t.Errorf("expected no renewal and no new credentials, got %d issued and %d renewals", issued, renewals)
+	}
+}
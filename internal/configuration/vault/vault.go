@@ -2,26 +2,34 @@ package vault
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log/slog"
-	"net/url"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/vault/api"
-	"github.com/hashicorp/vault/api/auth/userpass"
 )
 
 type Vault struct {
-	c       *api.Client     // acutal api client
-	l       *api.Secret     // login information
-	dbCreds *api.Secret     // database credentials
-	ctx     context.Context // vault context
+	c    *api.Client     // acutal api client
+	auth api.AuthMethod  // method used to log into the vault
+	l    *api.Secret     // login information
+	ctx  context.Context // vault context
+
+	lock          sync.RWMutex
+	dbCreds       *api.Secret // database credentials
+	dbUsername    string
+	dbPassword    string
+	dbCredsExpiry time.Time // end of the lease of the database credentials
 }
 
 const (
 	newLeaseTTL = 4 * time.Hour
+
+	// loginRetryDelay is the time waited before retrying to log into the
+	// vault after the login failed.
+	loginRetryDelay = 1 * time.Minute
 )
 
 const (
@@ -30,6 +38,14 @@ const (
 	EnvVaultPaths    = "VAULT_PATHS"
 )
 
+// New creates a vault using an already configured client and authentication
+// method.
+// This allows using the vault with a client that does not read its settings
+// from the environment (e.g., with a test server).
+func New(client *api.Client, auth api.AuthMethod) *Vault {
+	return &Vault{c: client, auth: auth, ctx: context.Background()}
+}
+
 func (v *Vault) Initialize() error {
 	v.ctx = context.Background()
 	if _, set := os.LookupEnv(api.EnvVaultAddress); !set {
@@ -46,22 +62,18 @@ func (v *Vault) Initialize() error {
 		return fmt.Errorf("unable to create vault client: %w", err)
 	}
 
+	auth, err := AuthMethodFromEnvironment()
+	if err != nil {
+		return fmt.Errorf("unable to construct login data: %w", err)
+	}
+
 	v.c = client
+	v.auth = auth
 	return nil
 }
 
 func (v *Vault) Login() error {
-	username, set := os.LookupEnv(EnvVaultUsername)
-	if !set {
-		return fmt.Errorf("no username set in %s", EnvVaultUsername)
-	}
-
-	authData, err := userpass.NewUserpassAuth(username, &userpass.Password{FromEnv: EnvVaultPassword})
-	if err != nil {
-		return fmt.Errorf("unable to construct login data: %w", err)
-	}
-
-	s, err := v.c.Auth().Login(context.Background(), authData)
+	s, err := v.c.Auth().Login(v.ctx, v.auth)
 	if err != nil {
 		return fmt.Errorf("unable to login into vault: %w", err)
 	}
@@ -75,89 +87,57 @@ func (v *Vault) Login() error {
 	return nil
 }
 
-func (v *Vault) AutoLogin() {
-	l := slog.New(slog.NewTextHandler(os.Stdout, nil))
+// AutoLogin keeps the login into the vault valid until the context is
+// canceled.
+// The token of the current login is renewed until it reaches its maximal
+// lifetime. Tokens that can not be renewed are kept until they approach the
+// end of their lifetime. Afterward, the vault is logged into again.
+func (v *Vault) AutoLogin(ctx context.Context) {
+	l := slog.Default()
 	for {
-		if err := v.Login(); err != nil {
-			l.LogAttrs(v.ctx, slog.LevelWarn, "unable to autologin into vault", slog.String("error", err.Error()))
-			time.Sleep(1 * time.Minute)
-			continue
-		}
-
-		if err := v.manageSecretLifecycle(v.l, "login"); err != nil {
-			l.LogAttrs(v.ctx, slog.LevelError, "unable to manage secret lifecycle", slog.String("error", err.Error()))
+		if v.l != nil && v.l.Auth != nil && v.l.Auth.LeaseDuration == 0 {
+			// tokens without a ttl (e.g., root tokens) never expire
 			return
 		}
-	}
-}
 
-func (v *Vault) AutoRenewDatabaseCredentials() {
-	l := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	for {
-		if err := v.Login(); err != nil {
-			l.LogAttrs(v.ctx, slog.LevelWarn, "unable to generate database credentials", slog.String("error", err.Error()))
-			time.Sleep(1 * time.Minute)
-			continue
+		if v.l != nil {
+			if err := v.manageSecretLifecycle(ctx, v.l, "login"); err != nil {
+				l.LogAttrs(ctx, slog.LevelError, "unable to manage secret lifecycle", slog.String("error", err.Error()))
+			}
 		}
 
-		if err := v.manageSecretLifecycle(v.dbCreds, "database-credentials"); err != nil {
-			l.LogAttrs(v.ctx, slog.LevelError, "unable to manage secret lifecycle", slog.String("error", err.Error()))
+		if ctx.Err() != nil {
 			return
 		}
-	}
-}
-
-func (v *Vault) DatabaseCredentials(mount, role string) (username, password string, err error) {
-	if v.dbCreds != nil {
-		var ok bool
-		username, ok = v.dbCreds.Data["username"].(string)
-		if !ok {
-			goto request
-		}
 
-		password, ok = v.dbCreds.Data["password"].(string)
-		if !ok {
-			goto request
+		if err := v.Login(); err != nil {
+			l.LogAttrs(ctx, slog.LevelWarn, "unable to autologin into vault", slog.String("error", err.Error()))
+			v.l = nil
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(loginRetryDelay):
+			}
+			continue
 		}
-
-		return username, password, nil
-	}
-request:
-	path, err := url.JoinPath(mount, "/creds/", role)
-	if err != nil {
-		return "", "", err
 	}
-
-	v.dbCreds, err = v.c.Logical().Read(path)
-	if err != nil {
-		return "", "", fmt.Errorf("unable to get database credentials: %w", err)
-	}
-
-	username, ok := v.dbCreds.Data["username"].(string)
-	if !ok {
-		goto request
-	}
-
-	password, ok = v.dbCreds.Data["password"].(string)
-	if !ok {
-		goto request
-	}
-
-	return username, password, nil
 }
 
 func (v *Vault) ServerAddress() string {
 	return v.c.Address()
 }
 
-func (v *Vault) manageSecretLifecycle(s *api.Secret, label string) error {
-	if (s.Auth != nil && !s.Auth.Renewable) && !s.Renewable {
-		return errors.New("supplied secret is not renewable")
-	}
-
+// manageSecretLifecycle renews the secret until it reaches its maximal
+// lifetime or the context is canceled.
+// Secrets that can not be renewed are watched until they approach the end of
+// their lifetime.
+func (v *Vault) manageSecretLifecycle(ctx context.Context, s *api.Secret, label string) error {
 	w, err := v.c.NewLifetimeWatcher(&api.LifetimeWatcherInput{
 		Secret:    s,
-		Increment: int(newLeaseTTL),
+		Increment: int(newLeaseTTL.Seconds()),
+		// the watcher waits for the end of the lifetime of secrets that are
+		// not renewable instead of returning immediately
+		RenewBehavior: api.RenewBehaviorIgnoreErrors,
 	})
 	if err != nil {
 		return fmt.Errorf("unable to instantiate new lifetime watcher: %w", err)
@@ -172,14 +152,16 @@ func (v *Vault) manageSecretLifecycle(s *api.Secret, label string) error {
 
 	for {
 		select {
+		case <-ctx.Done():
+			return nil
 		case err := <-w.DoneCh():
 			if err != nil {
-				slog.LogAttrs(context.Background(), slog.LevelError, "failed to renew secret lease", logAttrs...)
+				slog.LogAttrs(ctx, slog.LevelError, "failed to renew secret lease", logAttrs...)
 			}
-			slog.LogAttrs(context.Background(), slog.LevelError, "secret reached max ttl and cannot be renewed", logAttrs...)
+			slog.LogAttrs(ctx, slog.LevelInfo, "secret approaches the end of its lifetime", logAttrs...)
 			return nil
 		case <-w.RenewCh():
-			slog.LogAttrs(context.Background(), slog.LevelInfo, "renewed secret", logAttrs...)
+			slog.LogAttrs(ctx, slog.LevelInfo, "renewed secret", logAttrs...)
 		}
 	}
 }
@@ -0,0 +1,308 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/api/auth/approle"
+)
+
+const (
+	testDatabaseMount = "database"
+	testDatabaseRole  = "water-rights"
+)
+
+// fakeVault is a stand-in for a vault issuing tokens and database
+// credentials.
+// The tokens and the leases of the credentials are renewed until they reach
+// their maximal lifetime.
+type fakeVault struct {
+	server *httptest.Server
+
+	leaseDuration int           // lease duration of tokens and credentials in seconds
+	maxTTL        time.Duration // maximal lifetime of tokens and credentials
+	renewable     bool          // issue renewable tokens
+
+	lock          sync.Mutex
+	issued        int
+	renewals      int
+	leases        map[string]time.Time // time at which the leases have been issued
+	logins        []login
+	tokens        map[string]time.Time // time at which the tokens have been issued
+	tokenRenewals int
+}
+
+// login is a login request received by the fake vault.
+type login struct {
+	path string
+	data map[string]any
+}
+
+func newFakeVault(t *testing.T, leaseDuration int, maxTTL time.Duration) *fakeVault {
+	t.Helper()
+
+	v := &fakeVault{
+		leaseDuration: leaseDuration,
+		maxTTL:        maxTTL,
+		renewable:     true,
+		leases:        make(map[string]time.Time),
+		tokens:        make(map[string]time.Time),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/"+testDatabaseMount+"/creds/"+testDatabaseRole, v.issue)
+	mux.HandleFunc("PUT /v1/sys/leases/renew", v.renew)
+	mux.HandleFunc("PUT /v1/auth/{mount}/login", v.login)
+	mux.HandleFunc("PUT /v1/auth/{mount}/login/{username}", v.login)
+	mux.HandleFunc("GET /v1/auth/token/lookup-self", v.lookupToken)
+	mux.HandleFunc("PUT /v1/auth/token/renew-self", v.renewToken)
+	v.server = httptest.NewServer(mux)
+	t.Cleanup(v.server.Close)
+	return v
+}
+
+// client returns a client authenticated with the fake vault.
+func (v *fakeVault) client(t *testing.T) *api.Client {
+	t.Helper()
+
+	conf := api.DefaultConfig()
+	conf.Address = v.server.URL
+	client, err := api.NewClient(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetToken("test-token")
+	return client
+}
+
+// remainingLeaseDuration returns the lease duration of a token or lease
+// issued at the supplied time.
+// The lease is shortened once it approaches the maximal lifetime.
+func (v *fakeVault) remainingLeaseDuration(issuedAt time.Time) int {
+	return max(min(v.leaseDuration, int((v.maxTTL-time.Since(issuedAt)).Seconds())), 0)
+}
+
+func (v *fakeVault) issue(w http.ResponseWriter, _ *http.Request) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	v.issued++
+	leaseID := fmt.Sprintf("%s/creds/%s/%d", testDatabaseMount, testDatabaseRole, v.issued)
+	v.leases[leaseID] = time.Now()
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"lease_id":       leaseID,
+		"lease_duration": v.leaseDuration,
+		"renewable":      true,
+		"data": map[string]string{
+			"username": fmt.Sprintf("v-water-rights-%d", v.issued),
+			"password": fmt.Sprintf("password-%d", v.issued),
+		},
+	})
+}
+
+func (v *fakeVault) renew(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		LeaseID string `json:"lease_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	issuedAt, found := v.leases[body.LeaseID]
+	if !found {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	v.renewals++
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"lease_id":       body.LeaseID,
+		"lease_duration": v.remainingLeaseDuration(issuedAt),
+		"renewable":      true,
+	})
+}
+
+func (v *fakeVault) login(w http.ResponseWriter, r *http.Request) {
+	var data map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	v.logins = append(v.logins, login{path: r.URL.Path, data: data})
+	token := fmt.Sprintf("token-%d", len(v.logins))
+	v.tokens[token] = time.Now()
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"auth": map[string]any{
+			"client_token":   token,
+			"policies":       []string{"water-rights"},
+			"lease_duration": v.leaseDuration,
+			"renewable":      v.renewable,
+		},
+	})
+}
+
+func (v *fakeVault) lookupToken(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get("X-Vault-Token")
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	v.logins = append(v.logins, login{path: r.URL.Path, data: map[string]any{"token": token}})
+	v.tokens[token] = time.Now()
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"data": map[string]any{
+			"id":        token,
+			"policies":  []string{"water-rights"},
+			"ttl":       v.leaseDuration,
+			"renewable": v.renewable,
+		},
+	})
+}
+
+func (v *fakeVault) renewToken(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get("X-Vault-Token")
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	issuedAt, found := v.tokens[token]
+	if !found || !v.renewable {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	v.tokenRenewals++
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"auth": map[string]any{
+			"client_token":   token,
+			"lease_duration": v.remainingLeaseDuration(issuedAt),
+			"renewable":      true,
+		},
+	})
+}
+
+func (v *fakeVault) counts() (issued, renewals int) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return v.issued, v.renewals
+}
+
+func (v *fakeVault) loginCounts() (logins, renewals int) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return len(v.logins), v.tokenRenewals
+}
+
+// runAutoLogin logs into the fake vault using the AppRole authentication and
+// keeps the login valid until the returned function is called.
+func runAutoLogin(t *testing.T, fake *fakeVault) (stop func()) {
+	t.Helper()
+
+	// the login exports the token for the remote configuration
+	t.Setenv(api.EnvVaultToken, "")
+
+	auth, err := approle.NewAppRoleAuth("role", &approle.SecretID{FromString: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := New(fake.client(t), auth)
+	if err := v.Login(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		v.AutoLogin(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// awaitLogins waits until the fake vault received the expected number of
+// logins.
+func awaitLogins(t *testing.T, fake *fakeVault, expected int) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if logins, _ := fake.loginCounts(); logins >= expected {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d logins", expected)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestAutoLoginRenewsToken(t *testing.T) {
+	fake := newFakeVault(t, 2, time.Hour)
+	stop := runAutoLogin(t, fake)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, renewals := fake.loginCounts(); renewals > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the token to be renewed")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	stop()
+
+	if logins, _ := fake.loginCounts(); logins != 1 {
+		t.Errorf("expected a single login, got %d", logins)
+	}
+}
+
+func TestAutoLoginAfterMaxTTL(t *testing.T) {
+	fake := newFakeVault(t, 2, 3*time.Second)
+	stop := runAutoLogin(t, fake)
+	defer stop()
+
+	awaitLogins(t, fake, 2)
+	if _, renewals := fake.loginCounts(); renewals == 0 {
+		t.Error("expected the token to be renewed before reaching the maximal lifetime")
+	}
+}
+
+func TestAutoLoginNonRenewableToken(t *testing.T) {
+	fake := newFakeVault(t, 2, time.Hour)
+	fake.renewable = false
+
+	start := time.Now()
+	stop := runAutoLogin(t, fake)
+	defer stop()
+
+	awaitLogins(t, fake, 2)
+	if elapsed := time.Since(start); elapsed >= 2*time.Second {
+		t.Errorf("expected to log in again before the token expired, logged in after %s", elapsed)
+	}
+	if _, renewals := fake.loginCounts(); renewals != 0 {
+		t.Errorf("expected the token not to be renewed, got %d renewals", renewals)
+	}
+}
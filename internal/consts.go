@@ -1,3 +1,7 @@
 package internal
 
 const ServiceName = "water-rights"
+
+// KeyTokenClaims is used to store the private claims of the validated token
+// in the request context.
+const KeyTokenClaims = "jwt.claims"
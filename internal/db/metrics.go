@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"microservice/internal/metrics"
+)
+
+// collectionTimeout limits the time the queries for the data metrics may take
+// during a single scrape.
+const collectionTimeout = 5 * time.Second
+
+func init() {
+	metrics.Registry.MustRegister(poolCollector{}, dataCollector{})
+}
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "db_pool", name), help, nil, nil)
+}
+
+var (
+	poolAcquiredConnections  = poolDesc("acquired_connections", "Number of currently acquired connections")
+	poolIdleConnections      = poolDesc("idle_connections", "Number of currently idle connections")
+	poolTotalConnections     = poolDesc("total_connections", "Number of connections currently in the pool")
+	poolMaxConnections       = poolDesc("max_connections", "Maximum number of connections in the pool")
+	poolAcquires             = poolDesc("acquires_total", "Number of successful connection acquires")
+	poolAcquireDuration      = poolDesc("acquire_duration_seconds_total", "Total time spent acquiring connections")
+	poolCanceledAcquires     = poolDesc("canceled_acquires_total", "Number of acquires canceled by a context")
+	poolEmptyAcquires        = poolDesc("empty_acquires_total", "Number of acquires that waited for a connection")
+	poolNewConnections       = poolDesc("new_connections_total", "Number of newly opened connections")
+	poolMaxLifetimeDestroyed = poolDesc("max_lifetime_destroyed_total", "Number of connections closed due to their lifetime") //nolint:lll
+	poolMaxIdleDestroyed     = poolDesc("max_idle_destroyed_total", "Number of connections closed due to their idle time")    //nolint:lll
+)
+
+// poolCollector exports the statistics of the database pool.
+type poolCollector struct{}
+
+func (poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolAcquiredConnections
+	ch <- poolIdleConnections
+	ch <- poolTotalConnections
+	ch <- poolMaxConnections
+	ch <- poolAcquires
+	ch <- poolAcquireDuration
+	ch <- poolCanceledAcquires
+	ch <- poolEmptyAcquires
+	ch <- poolNewConnections
+	ch <- poolMaxLifetimeDestroyed
+	ch <- poolMaxIdleDestroyed
+}
+
+func (poolCollector) Collect(ch chan<- prometheus.Metric) {
+	if !Connected() {
+		return
+	}
+
+	stat := pool.Stat()
+	ch <- prometheus.MustNewConstMetric(poolAcquiredConnections, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdleConnections, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotalConnections, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolMaxConnections, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(poolCanceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolNewConnections, prometheus.CounterValue, float64(stat.NewConnsCount()))
+	ch <- prometheus.MustNewConstMetric(poolMaxLifetimeDestroyed, prometheus.CounterValue, float64(stat.MaxLifetimeDestroyCount())) //nolint:lll
+	ch <- prometheus.MustNewConstMetric(poolMaxIdleDestroyed, prometheus.CounterValue, float64(stat.MaxIdleDestroyCount()))         //nolint:lll
+}
+
+var (
+	currentRights = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "", "current_rights"),
+		"Number of water rights that are currently valid", nil, nil)
+	activeLocations = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "", "active_usage_locations"),
+		"Number of active usage locations of the current water rights", nil, nil)
+	lastImport = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "", "last_import_timestamp_seconds"),
+		"Unix timestamp of the last change applied by an import", nil, nil)
+)
+
+// dataCollector exports statistics about the stored water rights. The
+// statistics are queried on every scrape.
+type dataCollector struct{}
+
+func (dataCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- currentRights
+	ch <- activeLocations
+	ch <- lastImport
+}
+
+func (dataCollector) Collect(ch chan<- prometheus.Metric) {
+	if !Connected() || !QueriesLoaded() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), collectionTimeout)
+	defer cancel()
+
+	for name, desc := range map[string]*prometheus.Desc{
+		"metrics_count-current-rights":   currentRights,
+		"metrics_count-active-locations": activeLocations,
+	} {
+		query, err := Queries.Raw(name)
+		if err != nil {
+			continue
+		}
+
+		var count int64
+		if err := pool.QueryRow(ctx, query).Scan(&count); err != nil {
+			slog.Warn("unable to collect data metric", "query", name, "error", err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(count))
+	}
+
+	query, err := Queries.Raw("metrics_last-import")
+	if err != nil {
+		return
+	}
+
+	var lastChange pgtype.Timestamptz
+	if err := pool.QueryRow(ctx, query).Scan(&lastChange); err != nil {
+		slog.Warn("unable to collect data metric", "query", "metrics_last-import", "error", err)
+		return
+	}
+	if lastChange.Valid {
+		ch <- prometheus.MustNewConstMetric(lastImport, prometheus.GaugeValue, float64(lastChange.Time.Unix()))
+	}
+}
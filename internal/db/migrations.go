@@ -30,6 +30,7 @@ func MigrateDatabase() error {
 	if err := goose.Up(db, "migrations"); err != nil {
 		return err
 	}
+	migrated.Store(true)
 	return nil
 
 }
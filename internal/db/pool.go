@@ -77,11 +77,48 @@ func Connect() (err error) {
 	)
 	slog.Debug("generated connection string", "connString", connectionString)
 
+	pgConfig, err := newPoolConfig(connectionString)
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("initializing database pool with connection string", "connString", connectionString)
+	pool, err = pgxpool.NewWithConfig(context.Background(), pgConfig)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrPoolConfigurationFailed.Error(), err)
+	}
+
+	slog.Info("validating database connection")
+	if err := pool.Ping(context.Background()); err != nil {
+		return fmt.Errorf("%s: %w", ErrPoolPingFailed.Error(), err)
+	}
+	connected.Store(true)
+	configuration.Default.OnDatabaseCredentialRotation(drainStaleConnections)
+	return nil
+}
+
+// newPoolConfig parses the connection string and sets up the hooks of the
+// pool which register the custom types and handle rotated credentials.
+func newPoolConfig(connectionString string) (*pgxpool.Config, error) {
 	pgConfig, err := pgxpool.ParseConfig(connectionString)
 	if err != nil {
-		return fmt.Errorf("unable to parse database configuration string: %w", err)
+		return nil, fmt.Errorf("unable to parse database configuration string: %w", err)
 	}
 
+	pgConfig.ConnConfig.Tracer = newTracer()
+
+	// the credentials may be rotated while the service is running. new
+	// connections therefore always use the current credentials while
+	// connections using previous credentials are closed once they are idle
+	pgConfig.BeforeConnect = func(_ context.Context, cc *pgx.ConnConfig) error {
+		cc.User, cc.Password = configuration.Default.DatabaseCredentials()
+		return nil
+	}
+	pgConfig.BeforeAcquire = func(_ context.Context, c *pgx.Conn) bool {
+		return usesCurrentCredentials(c)
+	}
+	pgConfig.AfterRelease = usesCurrentCredentials
+
 	pgConfig.AfterConnect = func(ctx context.Context, c *pgx.Conn) error {
 		err := pgxgeom.Register(ctx, c)
 		if err != nil {
@@ -106,16 +143,25 @@ func Connect() (err error) {
 		}
 		return nil
 	}
+	return pgConfig, nil
+}
 
-	slog.Debug("initializing database pool with connection string", "connString", connectionString)
-	pool, err = pgxpool.NewWithConfig(context.Background(), pgConfig)
-	if err != nil {
-		return fmt.Errorf("%s: %w", ErrPoolConfigurationFailed.Error(), err)
-	}
+// usesCurrentCredentials reports if the connection has been established using
+// the current database credentials.
+func usesCurrentCredentials(c *pgx.Conn) bool {
+	username, password := configuration.Default.DatabaseCredentials()
+	return c.Config().User == username && c.Config().Password == password
+}
 
-	slog.Info("validating database connection")
-	if err := pool.Ping(context.Background()); err != nil {
-		return fmt.Errorf("%s: %w", ErrPoolPingFailed.Error(), err)
+// drainStaleConnections closes the idle connections that have been
+// established using rotated credentials.
+// Connections that are in use are closed once they are released into the
+// pool, which allows running queries to finish.
+func drainStaleConnections() {
+	// acquiring the idle connections calls the BeforeAcquire hook which
+	// destroys the connections using rotated credentials
+	for _, conn := range pool.AcquireAllIdle(context.Background()) {
+		conn.Release()
 	}
-	return nil
+	slog.Info("closed idle database connections using rotated credentials")
 }
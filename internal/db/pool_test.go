@@ -0,0 +1,186 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"microservice/internal/configuration"
+)
+
+func TestMain(m *testing.M) {
+	if err := configuration.Default.Initialize(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeDatabase is a stand-in for a database server accepting every login.
+// It records the users connecting to it.
+type fakeDatabase struct {
+	listener net.Listener
+
+	lock  sync.Mutex
+	users []string
+}
+
+func newFakeDatabase(t *testing.T) *fakeDatabase {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &fakeDatabase{listener: listener}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go d.serve(conn)
+		}
+	}()
+	return d
+}
+
+func (d *fakeDatabase) serve(conn net.Conn) {
+	defer conn.Close()
+
+	backend := pgproto3.NewBackend(conn, conn)
+	msg, err := backend.ReceiveStartupMessage()
+	if err != nil {
+		return
+	}
+	startup, ok := msg.(*pgproto3.StartupMessage)
+	if !ok {
+		return
+	}
+
+	d.lock.Lock()
+	d.users = append(d.users, startup.Parameters["user"])
+	d.lock.Unlock()
+
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+
+	// the connection is kept open until the client terminates it
+	for {
+		if _, err := backend.Receive(); err != nil {
+			return
+		}
+	}
+}
+
+func (d *fakeDatabase) connectedUsers() []string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return append([]string(nil), d.users...)
+}
+
+// setCredentials replaces the database credentials in the configuration.
+// The configuration is not safe for concurrent use, therefore the pool must
+// not be releasing connections in the background while it is changed.
+func setCredentials(username, password string) {
+	c := configuration.Default.Viper()
+	c.Set(KeyUser, username)
+	c.Set(KeyPassword, password)
+}
+
+func TestPoolRotatesCredentials(t *testing.T) {
+	d := newFakeDatabase(t)
+	setCredentials("user-1", "password-1")
+	t.Cleanup(func() { setCredentials("", "") })
+
+	addr := d.listener.Addr().(*net.TCPAddr)
+	pgConfig, err := newPoolConfig(fmt.Sprintf(pgSqlConnString,
+		"user-1", "password-1", addr.IP, addr.Port, "disable", "water_rights"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the stand-in does not provide the custom types
+	pgConfig.AfterConnect = nil
+
+	pool, err = pgxpool.NewWithConfig(context.Background(), pgConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		pool.Close()
+		pool = nil
+	})
+
+	idle, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	busy, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	idle.Release()
+	awaitIdleConnections(1)
+
+	setCredentials("user-2", "password-2")
+	drainStaleConnections()
+	if total := awaitConnections(1); total != 1 {
+		t.Errorf("expected the idle connection to be closed, got %d connections", total)
+	}
+
+	// the connection in use is closed once it is released
+	busy.Release()
+	if total := awaitConnections(0); total != 0 {
+		t.Errorf("expected the released connection to be closed, got %d connections", total)
+	}
+
+	conn, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.Conn().Config().User != "user-2" {
+		t.Errorf("expected the new connection to use the current credentials, got %s", conn.Conn().Config().User)
+	}
+	conn.Release()
+	awaitIdleConnections(1)
+
+	users := d.connectedUsers()
+	expected := []string{"user-1", "user-1", "user-2"}
+	if fmt.Sprint(users) != fmt.Sprint(expected) {
+		t.Errorf("expected the logins %v, got %v", expected, users)
+	}
+}
+
+// awaitConnections waits for the pool to close its connections until the
+// expected number of connections is left and returns the number of
+// connections. The connections are closed in the background by the pool.
+func awaitConnections(expected int32) int32 {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		total := pool.Stat().TotalConns()
+		if total == expected || time.Now().After(deadline) {
+			return total
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// awaitIdleConnections waits for the pool to return the released connections
+// into the pool, which happens in the background.
+func awaitIdleConnections(expected int32) {
+	deadline := time.Now().Add(5 * time.Second)
+	for pool.Stat().IdleConns() != expected && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
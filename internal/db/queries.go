@@ -14,6 +14,22 @@ import (
 // Queries contains the prepared sql queries from the resources folder.
 var Queries *dotsql.DotSql
 
+// queryNames maps the sql of the loaded queries to their names.
+var queryNames map[string]string
+
+// QueryName returns the name of the loaded query with the supplied sql.
+// Queries which have not been loaded from the query files are reported using
+// a common placeholder name.
+func QueryName(sql string) string {
+	if !QueriesLoaded() {
+		return unnamedQuery
+	}
+	if name, found := queryNames[sql]; found {
+		return name
+	}
+	return unnamedQuery
+}
+
 func LoadQueries() error {
 	slog.Debug("loading embedded database queries")
 	queryFiles, err := fs.ReadDir(resources.QueryFiles, ".")
@@ -35,5 +51,17 @@ func LoadQueries() error {
 	}
 
 	Queries = dotsql.Merge(dotSqls...)
+
+	names := make(map[string]string)
+	for name := range Queries.QueryMap() {
+		sql, err := Queries.Raw(name)
+		if err != nil {
+			return err
+		}
+		names[sql] = name
+	}
+	queryNames = names
+	queriesLoaded.Store(true)
+
 	return nil
 }
@@ -0,0 +1,26 @@
+package db
+
+import "sync/atomic"
+
+// The startup state of the database is tracked separately as the health
+// checks may run concurrently to the initialization of the database.
+var (
+	connected     atomic.Bool
+	migrated      atomic.Bool
+	queriesLoaded atomic.Bool
+)
+
+// Connected reports if the connection pool has been established.
+func Connected() bool {
+	return connected.Load()
+}
+
+// Migrated reports if the database migrations have been applied successfully.
+func Migrated() bool {
+	return migrated.Load()
+}
+
+// QueriesLoaded reports if the [Queries] have been loaded.
+func QueriesLoaded() bool {
+	return queriesLoaded.Load()
+}
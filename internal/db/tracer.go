@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/exaring/otelpgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
+
+	"microservice/internal/metrics"
+)
+
+// unnamedQuery is used as name for queries that have not been loaded from the
+// query files (e.g., the migrations or the type registration).
+const unnamedQuery = "<unnamed>"
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	name    string
+	started time.Time
+}
+
+// newTracer returns the tracer used by the connections of the pool. It
+// records the query metrics and creates a span for each query which is named
+// after the query in the query files.
+func newTracer() pgx.QueryTracer {
+	return multitracer.New(
+		queryTracer{},
+		// the span name function is only applied if the sql is trimmed in the
+		// span names
+		otelpgx.NewTracer(
+			otelpgx.WithTrimSQLInSpanName(),
+			otelpgx.WithSpanNameFunc(QueryName),
+			otelpgx.WithDisableQuerySpanNamePrefix(),
+		),
+	)
+}
+
+// queryTracer records the latency of every query executed on the pool and
+// attributes it to the name of the query in the query files.
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{
+		name:    QueryName(data.SQL),
+		started: time.Now(),
+	})
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	metrics.ObserveQuery(start.name, time.Since(start.started), data.Err)
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"microservice/internal/tracing"
+)
+
+func TestQuerySpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(exporter, sdktrace.AlwaysSample())
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	if err := LoadQueries(); err != nil {
+		t.Fatal(err)
+	}
+	query, err := Queries.Raw("get-webhook-subscriptions")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracer := newTracer()
+	ctx, request := provider.Tracer("test").Start(context.Background(), "request")
+	for _, sql := range []string{query, "SELECT 1"} {
+		queryCtx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql})
+		tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{})
+	}
+	request.End()
+
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("expected 2 query spans and the request span, got %d spans", len(spans))
+	}
+
+	for idx, name := range []string{"get-webhook-subscriptions", unnamedQuery} {
+		span := spans[idx]
+		if span.Name != name {
+			t.Errorf("expected span %q, got %q", name, span.Name)
+		}
+		if span.SpanKind != trace.SpanKindClient {
+			t.Errorf("expected a client span, got %s", span.SpanKind)
+		}
+		if span.Parent.SpanID() != request.SpanContext().SpanID() {
+			t.Errorf("expected span %q to be a child of the request span", span.Name)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+
+	"microservice/internal/db"
+	v2 "microservice/types/v2"
+)
+
+// Channel is the PostgreSQL notification channel that is fed by the triggers
+// on the water right tables.
+const Channel = "water_rights_changes"
+
+// batchSize limits the number of change events read in a single query.
+const batchSize = 500
+
+// subscriberBuffer determines how many events may be queued for a single
+// subscriber before it is considered too slow and is disconnected.
+const subscriberBuffer = 256
+
+// reconnectDelay is the time waited before listening again after the
+// listening connection failed.
+const reconnectDelay = 5 * time.Second
+
+// pollInterval is the time after which the change events are read without a
+// notification. Events are held back while an older transaction is running
+// and are published once it has finished, even if it did not notify.
+const pollInterval = 10 * time.Second
+
+// Default is the broker used by the service.
+var Default = NewBroker()
+
+// Broker listens for notifications on [Channel] and publishes the change
+// events recorded since the last notification to all subscribers.
+type Broker struct {
+	lock        sync.Mutex
+	subscribers map[chan v2.ChangeEvent]struct{}
+	lastEvent   int64
+	closed      bool
+
+	hooks        []func()      // called for every received notification
+	publishHooks []func(int64) // called with the last event after publishing
+}
+
+// NewBroker creates a new broker without any subscribers.
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[chan v2.ChangeEvent]struct{}),
+	}
+}
+
+// Subscribe registers a new subscriber.
+// The returned channel is closed if the broker shuts down or the subscriber
+// does not keep up with the published events.
+// Subscribers need to call the returned function once they stop reading from
+// the channel.
+func (b *Broker) Subscribe() (<-chan v2.ChangeEvent, func()) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	ch := make(chan v2.ChangeEvent, subscriberBuffer)
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	b.subscribers[ch] = struct{}{}
+	return ch, func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		if _, subscribed := b.subscribers[ch]; subscribed {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// OnNotification registers a function that is called whenever the data may
+// have been changed, including notifications that did not record a change
+// event and reconnects after which notifications may have been missed.
+func (b *Broker) OnNotification(fn func()) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.hooks = append(b.hooks, fn)
+}
+
+// OnPublish registers a function that is called with the id of the last
+// published change event after the change events have been published.
+// The id is the same for all instances of the service once they published
+// the same events.
+func (b *Broker) OnPublish(fn func(lastEvent int64)) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.publishHooks = append(b.publishHooks, fn)
+}
+
+func (b *Broker) notifyHooks() {
+	b.lock.Lock()
+	hooks := slices.Clone(b.hooks)
+	b.lock.Unlock()
+
+	for _, fn := range hooks {
+		fn()
+	}
+}
+
+// Run listens for notifications until the context is canceled.
+// Once the context is canceled, all subscriber channels are closed.
+func (b *Broker) Run(ctx context.Context) {
+	defer b.close()
+
+	query, err := db.Queries.Raw("get-latest-change-event-id")
+	if err != nil {
+		slog.Error("unable to load query for change events", "error", err)
+		return
+	}
+
+	if err := db.Pool().QueryRow(ctx, query).Scan(&b.lastEvent); err != nil {
+		slog.Error("unable to determine latest change event", "error", err)
+		return
+	}
+
+	for {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("listening for change notifications failed", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (b *Broker) listen(ctx context.Context) error {
+	conn, err := db.Pool().Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// the connection is not returned into the pool as it still listens to the
+	// channel
+	defer conn.Hijack().Close(context.Background()) //nolint:errcheck
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+
+	// publishing events that may have been missed while reconnecting
+	b.notifyHooks()
+	if err := b.publish(ctx); err != nil {
+		return err
+	}
+
+	for {
+		waitCtx, cancel := context.WithTimeout(ctx, pollInterval)
+		_, err := conn.Conn().WaitForNotification(waitCtx)
+		timedOut := waitCtx.Err() != nil && ctx.Err() == nil
+		cancel()
+		if err != nil && !timedOut {
+			return err
+		}
+
+		if err == nil {
+			b.notifyHooks()
+		}
+		if err := b.publish(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// publish reads all change events recorded after the last published event
+// and sends them to the subscribers.
+func (b *Broker) publish(ctx context.Context) error {
+	for {
+		events, err := Since(ctx, b.lastEvent, batchSize)
+		if err != nil {
+			return err
+		}
+
+		b.lock.Lock()
+		for _, event := range events {
+			for ch := range b.subscribers {
+				select {
+				case ch <- event:
+				default:
+					// the subscriber is too slow. it is disconnected to allow
+					// it to resume from its last received event
+					delete(b.subscribers, ch)
+					close(ch)
+				}
+			}
+			b.lastEvent = event.ID
+		}
+		lastEvent := b.lastEvent
+		hooks := slices.Clone(b.publishHooks)
+		b.lock.Unlock()
+
+		if len(events) < batchSize {
+			for _, fn := range hooks {
+				fn(lastEvent)
+			}
+			return nil
+		}
+	}
+}
+
+func (b *Broker) close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Event returns the change event with the supplied id. An event that is not
+// recorded (e.g., the id 0) is returned without a transaction and is
+// therefore placed before all recorded events.
+func Event(ctx context.Context, eventID int64) (v2.ChangeEvent, error) {
+	query, err := db.Queries.Raw("get-change-event")
+	if err != nil {
+		return v2.ChangeEvent{}, err
+	}
+
+	var event v2.ChangeEvent
+	err = pgxscan.Get(ctx, db.Pool(), &event, query, eventID)
+	if pgxscan.NotFound(err) {
+		return v2.ChangeEvent{ID: eventID}, nil
+	}
+	return event, err
+}
+
+// Since returns up to limit change events that are delivered after the
+// supplied event.
+// The events are ordered by the transaction that recorded them. Events are
+// only returned once every older transaction has finished, as the event ids
+// are allocated before a transaction commits and a transaction committing
+// late would otherwise add events that are never read.
+func Since(ctx context.Context, eventID int64, limit int) ([]v2.ChangeEvent, error) {
+	query, err := db.Queries.Raw("get-change-events-since")
+	if err != nil {
+		return nil, err
+	}
+
+	var events []v2.ChangeEvent
+	if err := pgxscan.Select(ctx, db.Pool(), &events, query, eventID, limit); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
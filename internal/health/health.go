@@ -0,0 +1,165 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"microservice/internal"
+	"microservice/internal/configuration"
+	"microservice/internal/db"
+)
+
+// ContentType is the media type of the health responses as defined in the
+// "Health Check Response Format for HTTP APIs" draft.
+const ContentType = "application/health+json"
+
+// The possible states of the service and its components.
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// pingTimeout limits how long the database may take to answer a ping.
+const pingTimeout = 2 * time.Second
+
+// credentialWarnThreshold is the remaining lifetime of the database
+// credentials below which the readiness is reported with a warning.
+const credentialWarnThreshold = 5 * time.Minute
+
+// Response is the body of the health endpoints.
+type Response struct {
+	Status      string             `json:"status"`
+	ServiceID   string             `json:"serviceId"`
+	Description string             `json:"description,omitempty"`
+	Checks      map[string][]Check `json:"checks,omitempty"`
+}
+
+// Check contains the result of checking a single component the service
+// depends on.
+type Check struct {
+	ComponentType string    `json:"componentType,omitempty"`
+	Status        string    `json:"status"`
+	Time          time.Time `json:"time"`
+	Output        string    `json:"output,omitempty"`
+}
+
+// Liveness reports that the service is running and able to answer requests.
+func Liveness(c *gin.Context) {
+	write(c, Response{
+		Status:      StatusPass,
+		ServiceID:   internal.ServiceName,
+		Description: "liveness of the service",
+	})
+}
+
+// Readiness reports if the service is able to handle requests.
+// The service is ready once the database is reachable, the migrations have
+// been applied and the queries have been loaded.
+// If the database credentials are issued by a vault, they need to be valid as
+// well.
+func Readiness(c *gin.Context) {
+	checks := map[string][]Check{
+		"postgres:connection": {checkConnection(c)},
+		"postgres:migrations": {checkMigrations()},
+		"postgres:queries":    {checkQueries()},
+	}
+	if expiry, dynamic := configuration.Default.DatabaseCredentialsExpiry(); dynamic {
+		checks["vault:database-credentials"] = []Check{checkCredentials(expiry)}
+	}
+
+	write(c, Response{
+		Status:      aggregate(checks),
+		ServiceID:   internal.ServiceName,
+		Description: "readiness of the service",
+		Checks:      checks,
+	})
+}
+
+// Started reports if the startup of the service has been completed and the
+// requests to the api may be handled.
+func Started() bool {
+	return db.Migrated() && db.QueriesLoaded()
+}
+
+func checkConnection(ctx context.Context) Check {
+	check := Check{ComponentType: "datastore", Status: StatusPass, Time: time.Now()}
+	if !db.Connected() {
+		check.Status = StatusFail
+		check.Output = "database connection not established"
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	if err := db.Pool().Ping(ctx); err != nil {
+		check.Status = StatusFail
+		check.Output = err.Error()
+	}
+	return check
+}
+
+func checkMigrations() Check {
+	check := Check{ComponentType: "datastore", Status: StatusPass, Time: time.Now()}
+	if !db.Migrated() {
+		check.Status = StatusFail
+		check.Output = "database migrations not applied"
+	}
+	return check
+}
+
+func checkQueries() Check {
+	check := Check{ComponentType: "component", Status: StatusPass, Time: time.Now()}
+	if !db.QueriesLoaded() {
+		check.Status = StatusFail
+		check.Output = "database queries not loaded"
+	}
+	return check
+}
+
+func checkCredentials(expiry time.Time) Check {
+	check := Check{ComponentType: "component", Status: StatusPass, Time: time.Now()}
+	switch remaining := time.Until(expiry); {
+	case expiry.IsZero():
+		check.Status = StatusFail
+		check.Output = "no database credentials issued"
+	case remaining <= 0:
+		check.Status = StatusFail
+		check.Output = "database credentials expired at " + expiry.Format(time.RFC3339)
+	case remaining < credentialWarnThreshold:
+		check.Status = StatusWarn
+		check.Output = "database credentials expire at " + expiry.Format(time.RFC3339)
+	}
+	return check
+}
+
+// aggregate determines the overall status using the worst status of the
+// checks.
+func aggregate(checks map[string][]Check) string {
+	status := StatusPass
+	for _, results := range checks {
+		for _, check := range results {
+			switch check.Status {
+			case StatusFail:
+				return StatusFail
+			case StatusWarn:
+				status = StatusWarn
+			}
+		}
+	}
+	return status
+}
+
+func write(c *gin.Context, response Response) {
+	code := http.StatusOK
+	if response.Status == StatusFail {
+		code = http.StatusServiceUnavailable
+	}
+	// the json renderer keeps the content type if it has been set before
+	c.Header("Content-Type", ContentType)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(code, response)
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"microservice/internal/configuration"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	if err := configuration.Default.Initialize(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		expected string
+	}{
+		{name: "without checks", statuses: nil, expected: StatusPass},
+		{name: "passing", statuses: []string{StatusPass, StatusPass}, expected: StatusPass},
+		{name: "warning", statuses: []string{StatusPass, StatusWarn}, expected: StatusWarn},
+		{name: "failing", statuses: []string{StatusWarn, StatusFail, StatusPass}, expected: StatusFail},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checks := map[string][]Check{}
+			for _, status := range test.statuses {
+				checks["component"] = append(checks["component"], Check{Status: status})
+			}
+			if status := aggregate(checks); status != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, status)
+			}
+		})
+	}
+}
+
+func TestCheckCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		expiry   time.Time
+		expected string
+	}{
+		{name: "not issued", expiry: time.Time{}, expected: StatusFail},
+		{name: "expired", expiry: time.Now().Add(-time.Minute), expected: StatusFail},
+		{name: "expiring", expiry: time.Now().Add(time.Minute), expected: StatusWarn},
+		{name: "valid", expiry: time.Now().Add(time.Hour), expected: StatusPass},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if check := checkCredentials(test.expiry); check.Status != test.expected {
+				t.Errorf("expected %s, got %s (%s)", test.expected, check.Status, check.Output)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		status string
+		code   int
+	}{
+		{status: StatusPass, code: http.StatusOK},
+		{status: StatusWarn, code: http.StatusOK},
+		{status: StatusFail, code: http.StatusServiceUnavailable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.status, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(res)
+			write(c, Response{Status: test.status})
+
+			if res.Code != test.code {
+				t.Errorf("expected %d, got %d", test.code, res.Code)
+			}
+			if contentType := res.Header().Get("Content-Type"); contentType != ContentType {
+				t.Errorf("expected the content type %s, got %s", ContentType, contentType)
+			}
+		})
+	}
+}
+
+func TestReadinessWithoutDatabase(t *testing.T) {
+	res := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(res)
+	c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	Readiness(c)
+
+	if res.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 Service Unavailable, got %d", res.Code)
+	}
+
+	var response Response
+	if err := json.Unmarshal(res.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Status != StatusFail {
+		t.Errorf("expected the status %s, got %s", StatusFail, response.Status)
+	}
+	if checks := response.Checks["postgres:connection"]; len(checks) != 1 || checks[0].Status != StatusFail {
+		t.Errorf("expected the failing database connection to be reported, got %+v", checks)
+	}
+}
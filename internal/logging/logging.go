@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"microservice/internal/configuration"
+)
+
+// The supported output formats of the log records.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Level is the minimal level of the records written by the default logger.
+// It may be changed while the service is running.
+var Level = new(slog.LevelVar)
+
+// Setup replaces the default logger with a logger writing the records in the
+// configured format and level to the standard output.
+func Setup() error {
+	c := configuration.Default.Viper()
+
+	level, err := ParseLevel(c.GetString(configuration.ConfigurationKey_LogLevel))
+	if err != nil {
+		return err
+	}
+	Level.Set(level)
+
+	options := &slog.HandlerOptions{Level: Level}
+
+	var handler slog.Handler
+	switch format := strings.ToLower(c.GetString(configuration.ConfigurationKey_LogFormat)); format {
+	case FormatText:
+		handler = slog.NewTextHandler(os.Stdout, options)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(os.Stdout, options)
+	default:
+		return fmt.Errorf("unsupported log format: %s", format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+
+	// the level may be changed by reloading the configuration while the
+	// format is only applied during the startup
+	configuration.Default.OnReload(func() {
+		name := configuration.Default.Viper().GetString(configuration.ConfigurationKey_LogLevel)
+		level, err := ParseLevel(name)
+		if err != nil {
+			slog.Warn("ignoring invalid log level", "level", name)
+			return
+		}
+		Level.Set(level)
+	})
+	return nil
+}
+
+// ParseLevel converts the name of a log level (e.g., "debug" or "warn") into
+// the level.
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return level, fmt.Errorf("unsupported log level: %s", name)
+	}
+	return level, nil
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iancoleman/strcase"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"microservice/internal"
+)
+
+// Namespace is used as prefix for all metrics exported by the service.
+var Namespace = strcase.ToSnake(internal.ServiceName)
+
+// unmatchedRoute is used as route label for requests that did not match any
+// route to keep the cardinality of the metrics bounded.
+const unmatchedRoute = "<unmatched>"
+
+// Registry contains all metrics exported by the service.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of handled http requests per route template",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of the handled http requests per route template",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Latency of the executed database queries per named query",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"query", "success"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		queryDuration,
+	)
+}
+
+// Handler returns the handler exposing the metrics in the Prometheus
+// exposition format.
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware records the number and the latency of the handled requests.
+// The route template is used instead of the actual path to keep the
+// cardinality of the metrics bounded.
+func Middleware(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+
+	status := c.Writer.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(status)).Inc()
+	httpRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+}
+
+// ObserveQuery records the latency of a named database query.
+func ObserveQuery(name string, duration time.Duration, err error) {
+	queryDuration.WithLabelValues(name, strconv.FormatBool(err == nil)).Observe(duration.Seconds())
+}
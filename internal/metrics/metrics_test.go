@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddlewareRouteLabel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Middleware)
+	r.GET("/water-rights/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		route  string
+		status string
+	}{
+		{name: "matched", target: "/water-rights/4711", route: "/water-rights/:id", status: "204"},
+		{name: "unmatched", target: "/unknown/4711", route: unmatchedRoute, status: "404"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			counter := httpRequests.WithLabelValues(http.MethodGet, test.route, test.status)
+			before := testutil.ToFloat64(counter)
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, test.target, nil))
+
+			if count := testutil.ToFloat64(counter); count != before+1 {
+				t.Errorf("expected the request to be counted using the route %s, got %g requests", test.route, count-before)
+			}
+		})
+	}
+
+	// the actual paths must not be used as label to bound the cardinality
+	if series := testutil.CollectAndCount(httpRequests); series != len(tests) {
+		t.Errorf("expected a series per route template, got %d series", series)
+	}
+}
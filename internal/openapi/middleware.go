@@ -0,0 +1,357 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/wisdom-oss/common-go/v3/types"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// ContentType is the media type used when serving the specification.
+const ContentType = "application/yaml"
+
+var errInvalidRequest = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: http.StatusBadRequest,
+	Title:  "Invalid Request",
+	Detail: "The request does not match the api specification. Please check the listed parameters and try again",
+}
+
+var errRequestTooLarge = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.14",
+	Status: http.StatusRequestEntityTooLarge,
+	Title:  "Request Too Large",
+	Detail: "The request body exceeds the size accepted by this route",
+}
+
+// parameter is a parameter of an operation documented in the specification.
+type parameter struct {
+	name     string
+	in       string
+	required bool
+	pointer  []string // location of the parameter in the specification
+}
+
+// Serve responds with the specification.
+func (d *Document) Serve(c *gin.Context) {
+	c.Data(http.StatusOK, ContentType, d.source)
+}
+
+// Validate returns a middleware which validates the path, query and header
+// parameters and the json request body against the operation documented for
+// the matched route.
+// The prefix is the path the routes of the specification are registered
+// under (e.g., "/v2"). Requests to undocumented operations are passed on
+// without validation.
+func (d *Document) Validate(prefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path, pathParams, found := d.operationPath(strings.TrimPrefix(c.FullPath(), prefix), c.Request.Method)
+		if !found {
+			c.Next()
+			return
+		}
+
+		pathValues := make(map[string]string, len(pathParams))
+		for name, routeParam := range pathParams {
+			pathValues[name] = c.Param(routeParam)
+		}
+
+		invalid, err := d.validateRequest(c.Request, path, pathValues)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.Abort()
+			errRequestTooLarge.Emit(c)
+			return
+		}
+		if err != nil {
+			c.Abort()
+			_ = c.Error(err)
+			return
+		}
+		if len(invalid) > 0 {
+			c.Abort()
+			serviceError := errInvalidRequest
+			serviceError.Errors = invalid
+			serviceError.Emit(c)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// operationPath returns the path of the specification describing the route
+// registered with gin and the method. The path parameters of the
+// specification are mapped to the names of the route parameters.
+func (d *Document) operationPath(route, method string) (string, map[string]string, bool) {
+	paths, _ := d.doc["paths"].(map[string]any)
+	for path, item := range paths {
+		operations, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, found := operations[strings.ToLower(method)]; !found {
+			continue
+		}
+		if params, matches := matchRoute(path, route); matches {
+			return path, params, true
+		}
+	}
+	return "", nil, false
+}
+
+// matchRoute reports if the path template of the specification (e.g.
+// "/details/{id}") describes the gin route (e.g. "/details/:id").
+func matchRoute(template, route string) (map[string]string, bool) {
+	templateSegments := strings.Split(template, "/")
+	routeSegments := strings.Split(route, "/")
+	if len(templateSegments) != len(routeSegments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for idx, segment := range templateSegments {
+		routeSegment := routeSegments[idx]
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if !strings.HasPrefix(routeSegment, ":") && !strings.HasPrefix(routeSegment, "*") {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = routeSegment[1:]
+			continue
+		}
+		if segment != routeSegment {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// validateRequest returns an error for every invalid parameter of the
+// request.
+func (d *Document) validateRequest(r *http.Request, path string, pathValues map[string]string) ([]error, error) {
+	method := strings.ToLower(r.Method)
+
+	var invalid []error
+	for _, param := range d.parameters(path, method) {
+		var values []string
+		switch param.in {
+		case "path":
+			if value, found := pathValues[param.name]; found {
+				values = []string{value}
+			}
+		case "query":
+			values = r.URL.Query()[param.name]
+		case "header":
+			values = r.Header.Values(param.name)
+		default:
+			continue
+		}
+
+		subject := fmt.Sprintf("%s parameter '%s'", param.in, param.name)
+		if len(values) == 0 {
+			if param.required {
+				invalid = append(invalid, fmt.Errorf("%s is required", subject))
+			}
+			continue
+		}
+
+		schemaPointer := append(slices.Clone(param.pointer), "schema")
+		definition, found := d.lookup(schemaPointer...)
+		if !found {
+			continue
+		}
+		schema, err := d.schema(schemaPointer...)
+		if err != nil {
+			return nil, err
+		}
+		definitionMap, _ := definition.(map[string]any)
+		if err := schema.Validate(d.coerce(values, definitionMap)); err != nil {
+			invalid = append(invalid, describe(subject, err)...)
+		}
+	}
+
+	bodyErrors, err := d.validateBody(r, path, method)
+	if err != nil {
+		return nil, err
+	}
+	return append(invalid, bodyErrors...), nil
+}
+
+// validateBody validates the json request body. The body is restored to
+// allow the handlers to read it again.
+func (d *Document) validateBody(r *http.Request, path, method string) ([]error, error) {
+	pointer := []string{"paths", path, method, "requestBody"}
+	definition, found := d.lookup(pointer...)
+	if !found {
+		return nil, nil
+	}
+	schemaPointer := append(slices.Clone(pointer), "content", "application/json", "schema")
+	if _, found := d.lookup(schemaPointer...); !found {
+		return nil, nil
+	}
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return nil, err
+		}
+		_ = r.Body.Close()
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if required, _ := definition.(map[string]any)["required"].(bool); required {
+			return []error{errors.New("request body is required")}, nil
+		}
+		return nil, nil
+	}
+
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return []error{fmt.Errorf("request body is not valid json: %w", err)}, nil
+	}
+
+	schema, err := d.schema(schemaPointer...)
+	if err != nil {
+		return nil, err
+	}
+	if err := schema.Validate(value); err != nil {
+		return describe("request body", err), nil
+	}
+	return nil, nil
+}
+
+// parameters returns the parameters of the path and the operation.
+// Parameters of the operation override the parameters of the path.
+func (d *Document) parameters(path, method string) []parameter {
+	var parameters []parameter
+	for _, pointer := range [][]string{{"paths", path, "parameters"}, {"paths", path, method, "parameters"}} {
+		list, _ := d.lookup(pointer...)
+		entries, _ := list.([]any)
+		for idx, entry := range entries {
+			definition, ok := entry.(map[string]any)
+			if !ok {
+				continue
+			}
+			param := parameter{
+				pointer: append(slices.Clone(pointer), strconv.Itoa(idx)),
+			}
+			param.name, _ = definition["name"].(string)
+			param.in, _ = definition["in"].(string)
+			param.required, _ = definition["required"].(bool)
+
+			parameters = slices.DeleteFunc(parameters, func(p parameter) bool {
+				return p.name == param.name && p.in == param.in
+			})
+			parameters = append(parameters, param)
+		}
+	}
+	return parameters
+}
+
+// coerce converts the raw parameter values into the json values described by
+// the schema. Values that can not be converted are kept as strings to let the
+// validation report the mismatching type.
+func (d *Document) coerce(values []string, schema map[string]any) any {
+	schema = d.resolve(schema)
+	if slices.Contains(schemaTypes(schema), "array") {
+		items, _ := schema["items"].(map[string]any)
+		coerced := make([]any, len(values))
+		for idx, value := range values {
+			coerced[idx] = d.coerceValue(value, items)
+		}
+		return coerced
+	}
+	return d.coerceValue(values[0], schema)
+}
+
+func (d *Document) coerceValue(value string, schema map[string]any) any {
+	for _, schemaType := range schemaTypes(d.resolve(schema)) {
+		switch schemaType {
+		case "integer", "number":
+			if _, err := strconv.ParseFloat(value, 64); err == nil && json.Valid([]byte(value)) {
+				return json.Number(value)
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(value); err == nil {
+				return b
+			}
+		}
+	}
+	return value
+}
+
+// resolve follows the references to other schemas of the specification.
+func (d *Document) resolve(schema map[string]any) map[string]any {
+	for range 8 { //nolint:mnd
+		ref, ok := schema["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return schema
+		}
+		tokens := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+		for idx, token := range tokens {
+			tokens[idx] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		}
+		target, _ := d.lookup(tokens...)
+		if schema, ok = target.(map[string]any); !ok {
+			return nil
+		}
+	}
+	return schema
+}
+
+// schemaTypes returns the types allowed by the schema.
+func schemaTypes(schema map[string]any) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var types []string
+		for _, entry := range t {
+			if s, ok := entry.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// describe converts a validation error into one error per violated
+// constraint.
+func describe(subject string, err error) []error {
+	var validationError *jsonschema.ValidationError
+	if !errors.As(err, &validationError) {
+		return []error{fmt.Errorf("%s: %w", subject, err)}
+	}
+
+	printer := message.NewPrinter(language.English)
+	var errs []error
+	var walk func(*jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			location := subject
+			if len(e.InstanceLocation) > 0 {
+				location = fmt.Sprintf("%s at '/%s'", subject, strings.Join(e.InstanceLocation, "/"))
+			}
+			errs = append(errs, fmt.Errorf("%s: %s", location, e.ErrorKind.LocalizedString(printer)))
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(validationError)
+	return errs
+}
@@ -0,0 +1,144 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+
+	"microservice/resources"
+)
+
+// ErrUndocumented is returned if the specification does not describe the
+// requested operation, response or content type.
+var ErrUndocumented = errors.New("not documented in the openapi specification")
+
+// Document is a parsed OpenAPI 3.1 specification.
+// The schemas contained in the specification are compiled as JSON Schema
+// (Draft 2020-12) on their first usage.
+type Document struct {
+	url    string
+	source []byte
+	doc    map[string]any
+	lock   sync.Mutex
+
+	compiler *jsonschema.Compiler
+	schemas  map[string]*jsonschema.Schema
+}
+
+// Load parses the specification. The name is used to identify the
+// specification in error messages.
+func Load(name string, source []byte) (*Document, error) {
+	var parsed any
+	if err := yaml.Unmarshal(source, &parsed); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", name, err)
+	}
+
+	// the specification is converted into the json representation expected
+	// by the schema compiler
+	encoded, err := json.Marshal(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert %s: %w", name, err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert %s: %w", name, err)
+	}
+
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s does not contain an openapi document", name)
+	}
+
+	d := &Document{
+		url:      "file:///" + name,
+		source:   source,
+		doc:      root,
+		compiler: jsonschema.NewCompiler(),
+		schemas:  make(map[string]*jsonschema.Schema),
+	}
+	d.compiler.DefaultDraft(jsonschema.Draft2020)
+	if err := d.compiler.AddResource(d.url, root); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// LoadEmbedded parses the specification embedded into the service.
+func LoadEmbedded(name string) (*Document, error) {
+	source, err := fs.ReadFile(resources.Specifications, name)
+	if err != nil {
+		return nil, err
+	}
+	return Load(name, source)
+}
+
+// ResponseSchema returns the schema of the response with the status and
+// content type returned by the operation.
+// The path needs to be written like in the specification (e.g.,
+// "/details/{id}").
+func (d *Document) ResponseSchema(path, method string, status int, contentType string) (*jsonschema.Schema, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+
+	pointer := []string{"paths", path, strings.ToLower(method), "responses", strconv.Itoa(status), "content", mediaType, "schema"}
+	if _, found := d.lookup(pointer...); !found {
+		return nil, fmt.Errorf("%s %s response %d (%s): %w", method, path, status, mediaType, ErrUndocumented)
+	}
+	return d.schema(pointer...)
+}
+
+// lookup returns the value at the supplied location.
+func (d *Document) lookup(pointer ...string) (any, bool) {
+	var current any = d.doc
+	for _, token := range pointer {
+		switch value := current.(type) {
+		case map[string]any:
+			var found bool
+			if current, found = value[token]; !found {
+				return nil, false
+			}
+		case []any:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(value) {
+				return nil, false
+			}
+			current = value[idx]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// schema compiles the schema at the supplied location.
+func (d *Document) schema(pointer ...string) (*jsonschema.Schema, error) {
+	escaped := make([]string, len(pointer))
+	for idx, token := range pointer {
+		escaped[idx] = strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+	}
+	location := d.url + "#/" + strings.Join(escaped, "/")
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if schema, found := d.schemas[location]; found {
+		return schema, nil
+	}
+	schema, err := d.compiler.Compile(location)
+	if err != nil {
+		return nil, err
+	}
+	d.schemas[location] = schema
+	return schema, nil
+}
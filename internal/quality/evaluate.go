@@ -0,0 +1,68 @@
+package quality
+
+import (
+	"cmp"
+	"slices"
+
+	v2 "microservice/types/v2"
+)
+
+// maximalScore is the score of a water right without any issues.
+const maximalScore = 100
+
+// Evaluate applies the rules to the water rights and their usage locations.
+// It returns the scores of all water rights in the order of the water rights
+// and the issues ordered by the water right, the usage location and the rule.
+// Usage locations of water rights that are not supplied are ignored.
+func Evaluate(rules []Rule, waterRights []v2.WaterRight, locations []v2.UsageLocation) ([]v2.WaterRightScore, []v2.WaterRightIssue) { //nolint:lll
+	locationsByRight := make(map[uint64][]v2.UsageLocation)
+	for _, location := range locations {
+		waterRight := uint64(location.WaterRightID) //nolint:gosec
+		locationsByRight[waterRight] = append(locationsByRight[waterRight], location)
+	}
+	for _, locations := range locationsByRight {
+		slices.SortFunc(locations, func(a, b v2.UsageLocation) int {
+			return cmp.Compare(a.ID, b.ID)
+		})
+	}
+
+	scores := make([]v2.WaterRightScore, 0, len(waterRights))
+	issues := make([]v2.WaterRightIssue, 0)
+	for _, waterRight := range waterRights {
+		score := v2.WaterRightScore{
+			WaterRight:       waterRight.Identifiers.Database,
+			WaterRightNumber: waterRight.Identifiers.Cadenza,
+			Score:            maximalScore,
+		}
+		report := func(rule Rule, location *int, details []string) {
+			for _, detail := range details {
+				issues = append(issues, v2.WaterRightIssue{
+					WaterRight:       waterRight.Identifiers.Database,
+					WaterRightNumber: waterRight.Identifiers.Cadenza,
+					UsageLocation:    location,
+					Rule:             rule.Name,
+					Detail:           detail,
+				})
+				score.Issues++
+				score.Score -= rule.Weight
+			}
+		}
+
+		for _, rule := range rules {
+			if rule.checkWaterRight != nil {
+				report(rule, nil, rule.checkWaterRight(waterRight))
+			}
+		}
+		for _, location := range locationsByRight[waterRight.Identifiers.Database] {
+			for _, rule := range rules {
+				if rule.checkUsageLocation != nil {
+					report(rule, &location.ID, rule.checkUsageLocation(location))
+				}
+			}
+		}
+
+		score.Score = max(score.Score, 0)
+		scores = append(scores, score)
+	}
+	return scores, issues
+}
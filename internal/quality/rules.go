@@ -0,0 +1,194 @@
+// Package quality contains the rules rating the attributes of the water
+// rights and usage locations imported by the crawler.
+package quality
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"microservice/internal/configuration"
+	v2 "microservice/types/v2"
+)
+
+// The names of the rules. The names are used as keys of the rule settings in
+// the configuration.
+const (
+	RuleLandRecordExclusivity = "land-record-exclusivity"
+	RulePhRange               = "ph-range"
+	RuleValidityPeriod        = "validity-period"
+	RuleRateUnit              = "rate-unit"
+)
+
+// The bounds of the pH scale.
+const (
+	minimalPh = 0
+	maximalPh = 14
+)
+
+// Rule is a check of the attributes of a water right or its usage locations.
+// A rule returns a description of every issue it found.
+type Rule struct {
+	v2.QualityRule
+
+	checkWaterRight    func(v2.WaterRight) []string
+	checkUsageLocation func(v2.UsageLocation) []string
+}
+
+// rules contains the available rules with their default weights.
+var rules = []Rule{
+	{
+		QualityRule: v2.QualityRule{
+			Name:        RuleValidityPeriod,
+			Description: "the end of the validity of the water right lies before its start",
+			Weight:      25, //nolint:mnd
+		},
+		checkWaterRight: validityPeriod,
+	},
+	{
+		QualityRule: v2.QualityRule{
+			Name:        RuleLandRecordExclusivity,
+			Description: "the land record sets the district or field as well as the mutually exclusive fallback",
+			Weight:      10, //nolint:mnd
+		},
+		checkUsageLocation: landRecordExclusivity,
+	},
+	{
+		QualityRule: v2.QualityRule{
+			Name:        RulePhRange,
+			Description: "the lower pH value is larger than the upper pH value or the range exceeds the pH scale",
+			Weight:      10, //nolint:mnd
+		},
+		checkUsageLocation: phRange,
+	},
+	{
+		QualityRule: v2.QualityRule{
+			Name:        RuleRateUnit,
+			Description: "a rate has no unit or a unit that can not be converted into cubic meters",
+			Weight:      5, //nolint:mnd
+		},
+		checkUsageLocation: rateUnits,
+	},
+}
+
+// ruleSettings are the settings of a rule in the configuration. Settings
+// that are not configured keep the defaults of the rule.
+type ruleSettings struct {
+	Enabled *bool    `mapstructure:"enabled"`
+	Weight  *float64 `mapstructure:"weight"`
+}
+
+// Rules returns the enabled rules using the weights set in the configuration.
+// All rules are enabled by default. A rule with a weight of zero reports its
+// issues without lowering the scores.
+func Rules() []Rule {
+	var settings map[string]ruleSettings
+	config := configuration.Default.Viper()
+	if err := config.UnmarshalKey(configuration.ConfigurationKey_QualityRules, &settings); err != nil {
+		// the default settings are used if the settings cannot be read
+		settings = nil
+	}
+
+	configured := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		setting := settings[rule.Name]
+		if setting.Enabled != nil && !*setting.Enabled {
+			continue
+		}
+		if setting.Weight != nil {
+			rule.Weight = max(*setting.Weight, 0)
+		}
+		configured = append(configured, rule)
+	}
+	return configured
+}
+
+func validityPeriod(waterRight v2.WaterRight) []string {
+	from, until := waterRight.Validity.From, waterRight.Validity.Until
+	if !from.Valid || !until.Valid || from.InfinityModifier != pgtype.Finite || until.InfinityModifier != pgtype.Finite {
+		return nil
+	}
+	if !until.Time.Before(from.Time) {
+		return nil
+	}
+	return []string{fmt.Sprintf("the water right is valid until %s but only becomes valid on %s",
+		until.Time.Format("2006-01-02"), from.Time.Format("2006-01-02"))}
+}
+
+// landRecordExclusivity does not describe the values of the land record as
+// they contain personal data.
+func landRecordExclusivity(location v2.UsageLocation) []string {
+	record := location.LandRecord
+	if record == nil || record.Fallback == nil || (record.District == nil && record.Field == nil) {
+		return nil
+	}
+	return []string{"the land record sets the district or field as well as the fallback"}
+}
+
+func phRange(location v2.UsageLocation) []string {
+	values := location.PhValues
+	if values == nil || !values.Valid {
+		return nil
+	}
+
+	lowerBounded := values.LowerType != pgtype.Unbounded && values.LowerType != pgtype.Empty
+	upperBounded := values.UpperType != pgtype.Unbounded && values.UpperType != pgtype.Empty
+
+	var issues []string
+	if lowerBounded && upperBounded && values.Lower > values.Upper {
+		issues = append(issues, fmt.Sprintf("the lower pH value %g is larger than the upper pH value %g",
+			values.Lower, values.Upper))
+	}
+	if (lowerBounded && (values.Lower < minimalPh || values.Lower > maximalPh)) ||
+		(upperBounded && (values.Upper < minimalPh || values.Upper > maximalPh)) {
+		issues = append(issues, fmt.Sprintf("the pH range %s exceeds the pH scale", formatRange(*values)))
+	}
+	return issues
+}
+
+func rateUnits(location v2.UsageLocation) []string {
+	categories := []struct {
+		name  string
+		rates []v2.Rate
+	}{
+		{"withdrawal", location.Rates.Withdrawal},
+		{"pumping", location.Rates.Pumping},
+		{"injection", location.Rates.Injection},
+		{"wasteWaterFlow", location.Rates.WasteWater},
+		{"fluidDischarges", location.Rates.FluidDischarges},
+		{"rainSupplement", location.Rates.RainSupplements},
+	}
+
+	var issues []string
+	for _, category := range categories {
+		for idx, rate := range category.rates {
+			switch {
+			case rate.Unit == nil:
+				issues = append(issues, fmt.Sprintf("the %s rate %d has no unit", category.name, idx+1))
+			case !rate.KnownUnit():
+				issues = append(issues, fmt.Sprintf("the %s rate %d uses the unknown unit '%s'",
+					category.name, idx+1, *rate.Unit))
+			}
+		}
+	}
+	return issues
+}
+
+// formatRange formats the range using the notation of PostgreSQL (e.g.
+// "[6.5,8.5]").
+func formatRange(values pgtype.Range[float64]) string {
+	lower, upper := "(", ")"
+	if values.LowerType == pgtype.Inclusive {
+		lower = "["
+	}
+	if values.UpperType == pgtype.Inclusive {
+		upper = "]"
+	}
+	if values.LowerType != pgtype.Unbounded {
+		lower += fmt.Sprintf("%g", values.Lower)
+	}
+	if values.UpperType != pgtype.Unbounded {
+		upper = fmt.Sprintf("%g", values.Upper) + upper
+	}
+	return lower + "," + upper
+}
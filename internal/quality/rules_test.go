@@ -0,0 +1,194 @@
+package quality
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"microservice/internal/configuration"
+	"microservice/internal/store/storetest"
+	v2 "microservice/types/v2"
+)
+
+func TestMain(m *testing.M) {
+	storetest.Main(m)
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]any
+		expected map[string]float64
+	}{
+		{
+			name: "defaults",
+			expected: map[string]float64{
+				RuleValidityPeriod: 25, RuleLandRecordExclusivity: 10, RulePhRange: 10, RuleRateUnit: 5,
+			},
+		},
+		{
+			name: "disabled and weighted",
+			settings: map[string]any{
+				RulePhRange:        map[string]any{"enabled": false},
+				RuleRateUnit:       map[string]any{"weight": 0},
+				RuleValidityPeriod: map[string]any{"enabled": true, "weight": 40},
+			},
+			expected: map[string]float64{
+				RuleValidityPeriod: 40, RuleLandRecordExclusivity: 10, RuleRateUnit: 0,
+			},
+		},
+		{
+			name:     "negative weight",
+			settings: map[string]any{RuleRateUnit: map[string]any{"weight": -5}},
+			expected: map[string]float64{
+				RuleValidityPeriod: 25, RuleLandRecordExclusivity: 10, RulePhRange: 10, RuleRateUnit: 0,
+			},
+		},
+	}
+
+	config := configuration.Default.Viper()
+	t.Cleanup(func() { config.Set(configuration.ConfigurationKey_QualityRules, nil) })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Set(configuration.ConfigurationKey_QualityRules, tt.settings)
+
+			weights := make(map[string]float64)
+			for _, rule := range Rules() {
+				weights[rule.Name] = rule.Weight
+			}
+			if len(weights) != len(tt.expected) {
+				t.Fatalf("expected the rules %v, got %v", tt.expected, weights)
+			}
+			for name, weight := range tt.expected {
+				if actual, found := weights[name]; !found || actual != weight {
+					t.Errorf("expected the rule %s with the weight %g, got %v", name, weight, weights)
+				}
+			}
+		})
+	}
+}
+
+func TestValidityPeriod(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   pgtype.Date
+		until  pgtype.Date
+		issues int
+	}{
+		{"valid", storetest.Date(2020, time.January, 1), storetest.Date(2030, time.January, 1), 0},
+		{"single day", storetest.Date(2020, time.January, 1), storetest.Date(2020, time.January, 1), 0},
+		{"ends before start", storetest.Date(2030, time.January, 1), storetest.Date(2020, time.January, 1), 1},
+		{"no end", storetest.Date(2020, time.January, 1), pgtype.Date{}, 0},
+		{"infinite end", storetest.Date(2020, time.January, 1),
+			pgtype.Date{InfinityModifier: pgtype.NegativeInfinity, Valid: true}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var waterRight v2.WaterRight
+			waterRight.Validity.From, waterRight.Validity.Until = tt.from, tt.until
+
+			if issues := validityPeriod(waterRight); len(issues) != tt.issues {
+				t.Errorf("expected %d issues, got %v", tt.issues, issues)
+			}
+		})
+	}
+}
+
+func TestLandRecordExclusivity(t *testing.T) {
+	fallback := storetest.Ptr("Gemarkung Hasbergen")
+	tests := []struct {
+		name   string
+		record *v2.LandRecord
+		issues int
+	}{
+		{"no land record", nil, 0},
+		{"district and field", &v2.LandRecord{District: storetest.Ptr("Gaste"), Field: storetest.Ptr(int64(3))}, 0},
+		{"fallback", &v2.LandRecord{Fallback: fallback}, 0},
+		{"district and fallback", &v2.LandRecord{District: storetest.Ptr("Gaste"), Fallback: fallback}, 1},
+		{"field and fallback", &v2.LandRecord{Field: storetest.Ptr(int64(3)), Fallback: fallback}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := landRecordExclusivity(v2.UsageLocation{LandRecord: tt.record})
+			if len(issues) != tt.issues {
+				t.Errorf("expected %d issues, got %v", tt.issues, issues)
+			}
+		})
+	}
+}
+
+func TestPhRange(t *testing.T) {
+	inclusive := func(lower, upper float64) *pgtype.Range[float64] {
+		return &pgtype.Range[float64]{
+			Lower: lower, Upper: upper, LowerType: pgtype.Inclusive, UpperType: pgtype.Inclusive, Valid: true,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		values   *pgtype.Range[float64]
+		expected []string
+	}{
+		{"no values", nil, nil},
+		{"valid", inclusive(6.5, 8.5), nil},
+		{"inverted", inclusive(8.5, 6.5), []string{"the lower pH value 8.5 is larger than the upper pH value 6.5"}},
+		{"exceeds the scale", inclusive(6.5, 15), []string{"the pH range [6.5,15] exceeds the pH scale"}},
+		{"inverted and exceeding", inclusive(15, 6.5), []string{
+			"the lower pH value 15 is larger than the upper pH value 6.5",
+			"the pH range [15,6.5] exceeds the pH scale",
+		}},
+		{"unbounded", &pgtype.Range[float64]{
+			Lower: 6.5, LowerType: pgtype.Exclusive, UpperType: pgtype.Unbounded, Valid: true,
+		}, nil},
+		{"unbounded beyond the scale", &pgtype.Range[float64]{
+			Lower: -1, LowerType: pgtype.Exclusive, UpperType: pgtype.Unbounded, Valid: true,
+		}, []string{"the pH range (-1,) exceeds the pH scale"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := phRange(v2.UsageLocation{PhValues: tt.values})
+			if !slices.Equal(issues, tt.expected) {
+				t.Errorf("expected the issues %q, got %q", tt.expected, issues)
+			}
+		})
+	}
+}
+
+func TestRateUnits(t *testing.T) {
+	tests := []struct {
+		name       string
+		withdrawal []v2.Rate
+		pumping    []v2.Rate
+		expected   []string
+	}{
+		{name: "no rates"},
+		{name: "known units", withdrawal: []v2.Rate{{Unit: storetest.Ptr("m³")}, {Unit: storetest.Ptr("l")}}},
+		{
+			name:     "missing unit",
+			pumping:  []v2.Rate{{Unit: storetest.Ptr("m³")}, {}},
+			expected: []string{"the pumping rate 2 has no unit"},
+		},
+		{
+			name:       "unknown unit",
+			withdrawal: []v2.Rate{{Unit: storetest.Ptr("gallons")}},
+			expected:   []string{"the withdrawal rate 1 uses the unknown unit 'gallons'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var location v2.UsageLocation
+			location.Rates.Withdrawal, location.Rates.Pumping = tt.withdrawal, tt.pumping
+
+			if issues := rateUnits(location); !slices.Equal(issues, tt.expected) {
+				t.Errorf("expected the issues %q, got %q", tt.expected, issues)
+			}
+		})
+	}
+}
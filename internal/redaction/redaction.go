@@ -0,0 +1,99 @@
+package redaction
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	jwtMiddleware "github.com/wisdom-oss/common-go/v3/middleware/gin/jwt"
+
+	"microservice/internal/configuration"
+)
+
+// ScopePersonalData is the scope that permits reading the personal data
+// stored in the water rights and usage locations.
+const ScopePersonalData = "water-rights:personal-data"
+
+// The supported modes for redacting the personal data.
+const (
+	// ModeNull removes the personal data from the responses
+	ModeNull = "null"
+
+	// ModePseudonymize replaces the personal data with a stable pseudonym
+	// which allows correlating the values without revealing them
+	ModePseudonymize = "pseudonymize"
+)
+
+// pseudonymPrefix marks values that have been pseudonymized.
+const pseudonymPrefix = "pseudonym:"
+
+// pseudonymLength determines how many hex characters of the keyed hash are
+// used for a pseudonym.
+const pseudonymLength = 16
+
+// Redactor replaces personal data with either nothing or a pseudonym.
+// A nil Redactor returns all values unchanged.
+type Redactor struct {
+	mode string
+	key  []byte
+}
+
+// For returns the redactor that needs to be applied to the responses of the
+// request.
+// It returns nil if the caller is permitted to read the personal data or the
+// redaction has been disabled in the configuration.
+func For(c *gin.Context) *Redactor {
+	config := configuration.Default.Viper()
+	if !configuration.RedactionEnabled(config) {
+		return nil
+	}
+
+	if c.GetBool(jwtMiddleware.KeyAdministrator) {
+		return nil
+	}
+
+	if slices.Contains(c.GetStringSlice(jwtMiddleware.KeyTokenPermissions), ScopePersonalData) {
+		return nil
+	}
+
+	// the configuration validation ensures that a key is set for the
+	// pseudonymization
+	return &Redactor{
+		mode: config.GetString(configuration.ConfigurationKey_RedactionMode),
+		key:  []byte(config.GetString(configuration.ConfigurationKey_RedactionPseudonymKey)),
+	}
+}
+
+// Enabled reports if the personal data needs to be redacted.
+func (r *Redactor) Enabled() bool {
+	return r != nil
+}
+
+// Mode returns how the personal data is redacted or an empty string if the
+// personal data is not redacted.
+func (r *Redactor) Mode() string {
+	if r == nil {
+		return ""
+	}
+	return r.mode
+}
+
+// Redact returns the value that may be output in place of the supplied
+// value.
+func (r *Redactor) Redact(value *string) *string {
+	if r == nil || value == nil {
+		return value
+	}
+
+	if r.mode != ModePseudonymize {
+		return nil
+	}
+
+	h := hmac.New(sha256.New, r.key)
+	h.Write([]byte(*value))
+	pseudonym := pseudonymPrefix + hex.EncodeToString(h.Sum(nil))[:pseudonymLength]
+	return &pseudonym
+}
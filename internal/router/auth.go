@@ -0,0 +1,210 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/wisdom-oss/common-go/v3/types"
+
+	jwtMiddleware "github.com/wisdom-oss/common-go/v3/middleware/gin/jwt"
+
+	"microservice/internal"
+	"microservice/internal/configuration"
+)
+
+// scopeAdministrator is the scope that grants access to all resources.
+const scopeAdministrator = "*:*"
+
+// verificationFailure is the value the validator panics with if the
+// signature of a token cannot be verified.
+const verificationFailure = "unable to verify jwt"
+
+// ErrNoOidcAuthority is returned if authorization is required but no OpenID
+// Connect authority has been configured.
+var ErrNoOidcAuthority = errors.New("authorization required but no oidc authority configured")
+
+// ErrNoOidcAudience is returned if authorization is required but no audience
+// has been configured.
+var ErrNoOidcAudience = errors.New("authorization required but no oidc audience configured")
+
+var (
+	errMissingAuthorization = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc6750.html#section-3.1",
+		Status: http.StatusUnauthorized,
+		Title:  "Missing Authorization",
+		Detail: "The request did not contain a bearer token in the 'Authorization' header. Please check your request.",
+	}
+
+	errMultipleCredentials = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc6750.html#section-3.1",
+		Status: http.StatusBadRequest,
+		Title:  "Multiple Credentials supplied",
+		Detail: "The request contained multiple credentials. Due to security reasons, this is not supported and the request has been rejected", //nolint:lll
+	}
+
+	errInvalidSignature = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc6750.html#section-3.1",
+		Status: http.StatusUnauthorized,
+		Title:  "JSON Web Token Signature Invalid",
+		Detail: "The signature of the JSON Web Token could not be verified using the keys published by the authority",
+	}
+
+	errInvalidScopes = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc6750.html#section-3.1",
+		Status: http.StatusUnauthorized,
+		Title:  "Invalid Scopes",
+		Detail: "The JSON Web Token does not contain a list of scopes identifying the permissions of the token",
+	}
+)
+
+// validator is used to validate the bearer tokens. It is only set if the
+// configuration requires an authorization of the requests.
+var validator *jwtMiddleware.Validator
+
+var scopeRequirer jwtMiddleware.ScopeRequirer
+
+// configureAuthentication installs the validation of bearer tokens if the
+// configuration requires the authorization of requests.
+// The signing keys are discovered using the OpenID Connect discovery document
+// published by the configured authority. Only tokens issued for the
+// configured audience are accepted, as the authority issues tokens for other
+// clients using the same keys.
+func configureAuthentication(r *gin.Engine) error {
+	c := configuration.Default.Viper()
+	if !c.GetBool(configuration.ConfigurationKey_AuthorizationRequired) {
+		return nil
+	}
+
+	authority := strings.TrimSpace(c.GetString(configuration.ConfigurationKey_OidcAuthority))
+	if authority == "" {
+		return ErrNoOidcAuthority
+	}
+
+	audience := strings.TrimSpace(c.GetString(configuration.ConfigurationKey_OidcAudience))
+	if audience == "" {
+		return ErrNoOidcAudience
+	}
+
+	// the discovery document is expected relative to the authority
+	if !strings.HasSuffix(authority, "/") {
+		authority += "/"
+	}
+
+	v := &jwtMiddleware.Validator{}
+	if err := v.Discover(authority); err != nil {
+		return err
+	}
+	v.RequireAudiences([]string{audience})
+
+	validator = v
+	scopeRequirer.Configure(internal.ServiceName)
+	r.Use(authenticate)
+	return nil
+}
+
+// AuthorizationRequired reports if the requests to the service need to be
+// authorized.
+func AuthorizationRequired() bool {
+	return validator != nil
+}
+
+// RequireRead rejects requests that are not permitted to read the water
+// rights.
+// It lets all requests pass if no authorization is required.
+func RequireRead(c *gin.Context) {
+	if validator == nil {
+		c.Next()
+		return
+	}
+	scopeRequirer.RequireRead(c)
+}
+
+// RequireAdministrator rejects requests that have not been issued for an
+// administrator.
+// It lets all requests pass if no authorization is required.
+func RequireAdministrator(c *gin.Context) {
+	if validator == nil {
+		c.Next()
+		return
+	}
+	scopeRequirer.RequireAdministrator(c)
+}
+
+// authenticate validates the bearer token and stores the subject and the
+// scopes of the token using the keys of the common middleware to allow the
+// usage of the [jwtMiddleware.ScopeRequirer].
+func authenticate(c *gin.Context) {
+	headers := c.Request.Header.Values("Authorization")
+	switch {
+	case len(headers) == 0:
+		c.Header("WWW-Authenticate", "Bearer")
+		c.Abort()
+		errMissingAuthorization.Emit(c)
+		return
+	case len(headers) > 1:
+		c.Abort()
+		errMultipleCredentials.Emit(c)
+		return
+	}
+
+	scheme, _, _ := strings.Cut(strings.TrimSpace(headers[0]), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.Abort()
+		errMissingAuthorization.Emit(c)
+		return
+	}
+
+	token, serviceErr := parseToken(c.Request)
+	if serviceErr != nil {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.Abort()
+		serviceErr.Emit(c)
+		return
+	}
+
+	rawScopes, isArray := token.PrivateClaims()["scopes"].([]any)
+	if !isArray {
+		c.Abort()
+		errInvalidScopes.Emit(c)
+		return
+	}
+
+	scopes := make([]string, len(rawScopes))
+	for idx, rawScope := range rawScopes {
+		scope, ok := rawScope.(string)
+		if !ok {
+			c.Abort()
+			errInvalidScopes.Emit(c)
+			return
+		}
+		scopes[idx] = scope
+	}
+
+	c.Set(jwtMiddleware.KeyTokenValidated, true)
+	c.Set(jwtMiddleware.KeyTokenPermissions, scopes)
+	c.Set(jwtMiddleware.KeyTokenSubject, token.Subject())
+	c.Set(jwtMiddleware.KeyAdministrator, slices.Contains(scopes, scopeAdministrator))
+	c.Set(internal.KeyTokenClaims, token.PrivateClaims())
+	c.Next()
+}
+
+// parseToken parses and validates the token contained in the request.
+// The validator panics if the signature of the token cannot be verified which
+// is converted into an error response here. Other panics are not recovered.
+func parseToken(r *http.Request) (token jwt.Token, serviceErr *types.ServiceError) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if recovered != verificationFailure {
+				panic(recovered)
+			}
+			token = nil
+			serviceErr = &errInvalidSignature
+		}
+	}()
+	return validator.ParseHTTPRequest(r)
+}
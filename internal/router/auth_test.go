@@ -0,0 +1,207 @@
+package router
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+
+	"microservice/internal"
+	"microservice/internal/configuration"
+)
+
+const testAudience = "water-rights-api"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	if err := configuration.Default.Initialize(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// authority is a stand-in for an OpenID Connect authority publishing the
+// discovery document and the signing keys.
+type authority struct {
+	server *httptest.Server
+	key    jwk.Key
+}
+
+func newAuthority(t *testing.T) *authority {
+	t.Helper()
+
+	a := &authority{key: newSigningKey(t, "authority-key")}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   a.server.URL,
+			"jwks_uri": a.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		public, err := a.key.PublicKey()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		set := jwk.NewSet()
+		_ = set.AddKey(public)
+		_ = json.NewEncoder(w).Encode(set)
+	})
+	a.server = httptest.NewServer(mux)
+	t.Cleanup(a.server.Close)
+	return a
+}
+
+func newSigningKey(t *testing.T, keyID string) jwk.Key {
+	t.Helper()
+
+	raw, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := jwk.FromRaw(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = key.Set(jwk.KeyIDKey, keyID)
+	_ = key.Set(jwk.AlgorithmKey, jwa.RS256)
+	return key
+}
+
+// token issues a signed token using the supplied key.
+func (a *authority) token(t *testing.T, key jwk.Key, audience string, scopes ...string) string {
+	t.Helper()
+
+	token, err := jwt.NewBuilder().
+		Issuer(a.server.URL).
+		Subject("test-user").
+		Audience([]string{audience}).
+		NotBefore(time.Now().Add(-time.Minute)).
+		Expiration(time.Now().Add(time.Hour)).
+		Claim("scopes", scopes).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.RS256, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(signed)
+}
+
+// newAuthenticatedEngine configures the authentication against the
+// authority and returns an engine requiring the read scope.
+func newAuthenticatedEngine(t *testing.T, a *authority) *gin.Engine {
+	t.Helper()
+
+	c := configuration.Default.Viper()
+	c.Set(configuration.ConfigurationKey_AuthorizationRequired, true)
+	c.Set(configuration.ConfigurationKey_OidcAuthority, a.server.URL)
+	c.Set(configuration.ConfigurationKey_OidcAudience, testAudience)
+	t.Cleanup(func() {
+		c.Set(configuration.ConfigurationKey_AuthorizationRequired, false)
+		validator = nil
+	})
+
+	r := gin.New()
+	if err := configureAuthentication(r); err != nil {
+		t.Fatal(err)
+	}
+	r.GET("/", RequireRead, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func TestAuthentication(t *testing.T) {
+	a := newAuthority(t)
+	r := newAuthenticatedEngine(t, a)
+	readScope := internal.ServiceName + ":read"
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{"valid token", "Bearer " + a.token(t, a.key, testAudience, readScope), http.StatusNoContent},
+		{"missing token", "", http.StatusUnauthorized},
+		{"other audience", "Bearer " + a.token(t, a.key, "other-client", readScope), http.StatusUnauthorized},
+		{"unknown key", "Bearer " + a.token(t, newSigningKey(t, "authority-key"), testAudience, readScope),
+			http.StatusUnauthorized},
+		{"missing scope", "Bearer " + a.token(t, a.key, testAudience, "other-service:read"), http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			res := httptest.NewRecorder()
+			r.ServeHTTP(res, req)
+
+			if res.Code != test.status {
+				t.Errorf("expected status %d, got %d: %s", test.status, res.Code, res.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthenticationRequiresAudience(t *testing.T) {
+	a := newAuthority(t)
+	c := configuration.Default.Viper()
+	c.Set(configuration.ConfigurationKey_AuthorizationRequired, true)
+	c.Set(configuration.ConfigurationKey_OidcAuthority, a.server.URL)
+	c.Set(configuration.ConfigurationKey_OidcAudience, "")
+	t.Cleanup(func() {
+		c.Set(configuration.ConfigurationKey_AuthorizationRequired, false)
+		validator = nil
+	})
+
+	if err := configureAuthentication(gin.New()); !errors.Is(err, ErrNoOidcAudience) {
+		t.Errorf("expected %v, got %v", ErrNoOidcAudience, err)
+	}
+}
+
+func TestParseTokenRecoversVerificationFailures(t *testing.T) {
+	a := newAuthority(t)
+	newAuthenticatedEngine(t, a)
+
+	forged := a.token(t, newSigningKey(t, "authority-key"), testAudience)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+forged)
+
+	token, serviceErr := parseToken(req)
+	if token != nil || serviceErr == nil || serviceErr.Title != errInvalidSignature.Title {
+		t.Errorf("expected the invalid signature error, got %v", serviceErr)
+	}
+}
+
+func TestParseTokenDoesNotRecoverOtherPanics(t *testing.T) {
+	// a missing validator causes a nil pointer dereference instead of a
+	// verification failure
+	validator = nil
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected the panic to be passed on")
+		}
+	}()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	_, _ = parseToken(req)
+}
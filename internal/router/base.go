@@ -9,8 +9,13 @@ import (
 	"github.com/thanhpk/randstr"
 	"github.com/wisdom-oss/common-go/v3/middleware/gin/recoverer"
 	"github.com/wisdom-oss/common-go/v3/types"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	errorHandler "github.com/wisdom-oss/common-go/v3/middleware/gin/error-handler"
+
+	"microservice/internal"
+	"microservice/internal/health"
+	"microservice/internal/metrics"
 )
 
 // requestIDLength determines how long the generated request id will be.
@@ -34,11 +39,30 @@ var ErrRouteNotFound = types.ServiceError{
 	Detail: "The requested path does not exist. Please check the documentation and your request",
 }
 
-func prepareRouter() *gin.Engine {
+// ErrServiceStarting is used if a request is received before the database has
+// been initialized.
+var ErrServiceStarting = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110.html#section-15.6.4",
+	Status: http.StatusServiceUnavailable,
+	Title:  "Service Starting",
+	Detail: "The service is still starting up and is not yet able to handle requests. Please try again later",
+}
+
+// retryAfterStartup is the number of seconds clients are asked to wait before
+// retrying a request rejected during the startup.
+const retryAfterStartup = "5"
+
+func prepareRouter(public map[string]gin.HandlerFunc) (*gin.Engine, error) {
 	r := gin.New()
 	r.HandleMethodNotAllowed = true
 	r.UseH2C = true
 	r.RedirectFixedPath = true
+	// passing the request context into the database queries to allow the
+	// query spans to be attached to the request span
+	r.ContextWithFallback = true
+	r.Use(accessLog)
+	r.Use(metrics.Middleware)
+	r.Use(otelgin.Middleware(internal.ServiceName))
 	r.Use(gzip.Gzip(gzip.BestCompression))
 	r.Use(errorHandler.Handler)
 	r.Use(gin.CustomRecovery(recoverer.RecoveryHandler))
@@ -47,6 +71,25 @@ func prepareRouter() *gin.Engine {
 			return randstr.Base62(requestIDLength)
 		}),
 	))
+	r.Use(problemRequestID)
+
+	// the metrics and health endpoints as well as the public handlers are
+	// registered before the authentication is configured to allow accessing
+	// them without a token
+	r.GET("/metrics", metrics.Handler())
+	r.GET("/healthz", health.Liveness)
+	r.GET("/readyz", health.Readiness)
+	for path, handler := range public {
+		r.GET(path, handler)
+	}
+
+	// the http server is started before the database has been initialized to
+	// answer the health checks. all other requests are rejected until then
+	r.Use(requireStartup)
+
+	if err := configureAuthentication(r); err != nil {
+		return nil, err
+	}
 
 	r.NoMethod(func(c *gin.Context) {
 		ErrMethodNotAllowed.Emit(c)
@@ -56,5 +99,27 @@ func prepareRouter() *gin.Engine {
 		ErrRouteNotFound.Emit(c)
 	})
 
-	return r
+	return r, nil
+}
+
+func requireStartup(c *gin.Context) {
+	if !health.Started() {
+		c.Header("Retry-After", retryAfterStartup)
+		c.Abort()
+		ErrServiceStarting.Emit(c)
+		return
+	}
+	c.Next()
+}
+
+// LimitBody returns a middleware limiting the size of the request bodies to
+// the number of bytes. Reading beyond the limit fails with a
+// [*http.MaxBytesError].
+func LimitBody(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+		c.Next()
+	}
 }
@@ -8,7 +8,12 @@ import (
 
 // GenerateRouter returns a new [*gin.Engine] which has been configured
 // for running in development environments.
-func GenerateRouter() (*gin.Engine, error) {
-	r := prepareRouter()
+// The public handlers are served for GET requests on their path without
+// requiring a token.
+func GenerateRouter(public map[string]gin.HandlerFunc) (*gin.Engine, error) {
+	r, err := prepareRouter(public)
+	if err != nil {
+		return nil, err
+	}
 	return r, nil
 }
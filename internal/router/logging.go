@@ -0,0 +1,129 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/wisdom-oss/common-go/v3/types"
+
+	jwtMiddleware "github.com/wisdom-oss/common-go/v3/middleware/gin/jwt"
+)
+
+// problemContentType is the media type of the error responses.
+const problemContentType = "application/problem+json"
+
+// probeRoutes are requested periodically by the infrastructure. Successful
+// requests to them are only logged at the debug level.
+var probeRoutes = []string{"/healthz", "/readyz", "/metrics"}
+
+// accessLog writes a structured record for every handled request.
+// Requests failing due to a server error are logged as error, all other
+// requests as information.
+func accessLog(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	status := c.Writer.Status()
+	level := slog.LevelInfo
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = slog.LevelError
+	case slices.Contains(probeRoutes, c.FullPath()):
+		level = slog.LevelDebug
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", c.Request.Method),
+		slog.String("route", c.FullPath()),
+		slog.String("path", c.Request.URL.Path),
+		slog.Int("status", status),
+		slog.Duration("latency", time.Since(start)),
+		slog.Int("bytes", max(c.Writer.Size(), 0)),
+		slog.String("requestId", requestid.Get(c)),
+	}
+	if subject := c.GetString(jwtMiddleware.KeyTokenSubject); subject != "" {
+		attrs = append(attrs, slog.String("subject", subject))
+	}
+	if len(c.Errors) > 0 {
+		attrs = append(attrs, slog.String("errors", c.Errors.String()))
+	}
+
+	slog.LogAttrs(c.Request.Context(), level, "handled request", attrs...)
+}
+
+// problemRequestID adds the id of the request to all error responses to
+// allow users to quote it when reporting a problem.
+// The problem details are held back until the handlers have finished and are
+// emitted together with the id of the request afterward.
+// It needs to be installed after the request id has been generated.
+func problemRequestID(c *gin.Context) {
+	w := &problemWriter{ResponseWriter: c.Writer}
+	c.Writer = w
+	c.Next()
+
+	if w.problem.Len() == 0 {
+		return
+	}
+
+	var serviceErr types.ServiceError
+	if err := json.Unmarshal(w.problem.Bytes(), &serviceErr); err != nil {
+		// the body is passed on unchanged if it does not contain problem
+		// details
+		_, _ = w.ResponseWriter.Write(w.problem.Bytes())
+		return
+	}
+
+	body, err := json.Marshal(problemDetails{ServiceError: serviceErr, RequestID: requestid.Get(c)})
+	if err != nil {
+		_, _ = w.ResponseWriter.Write(w.problem.Bytes())
+		return
+	}
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// problemDetails extends the problem details with the id of the request.
+type problemDetails struct {
+	types.ServiceError
+	RequestID string
+}
+
+func (p problemDetails) MarshalJSON() ([]byte, error) {
+	encoded, err := json.Marshal(p.ServiceError)
+	if err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &members); err != nil {
+		return nil, err
+	}
+	if members["requestId"], err = json.Marshal(p.RequestID); err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// problemWriter holds back the problem details written into the response.
+// All other responses are written directly.
+type problemWriter struct {
+	gin.ResponseWriter
+	problem bytes.Buffer
+}
+
+func (w *problemWriter) Write(data []byte) (int, error) {
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), problemContentType) {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.problem.Write(data)
+}
+
+func (w *problemWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,62 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/wisdom-oss/common-go/v3/types"
+)
+
+func TestProblemRequestID(t *testing.T) {
+	r := gin.New()
+	r.Use(requestid.New(requestid.WithGenerator(func() string { return "test-request" })))
+	r.Use(problemRequestID)
+	r.GET("/problem", func(c *gin.Context) {
+		c.Abort()
+		ErrRouteNotFound.Emit(c)
+	})
+	r.GET("/chunked-problem", func(c *gin.Context) {
+		body, _ := json.Marshal(ErrRouteNotFound)
+		c.Header("Content-Type", types.ErrorContentType)
+		c.Status(http.StatusNotFound)
+		_, _ = c.Writer.Write(body[:5])
+		_, _ = c.Writer.WriteString(string(body[5:]))
+	})
+	r.GET("/data", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"requestId": "unchanged"})
+	})
+
+	for _, path := range []string{"/problem", "/chunked-problem"} {
+		t.Run(path, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			r.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+
+			if res.Code != http.StatusNotFound {
+				t.Errorf("expected status %d, got %d", http.StatusNotFound, res.Code)
+			}
+
+			var problem struct {
+				Title     string `json:"title"`
+				RequestID string `json:"requestId"`
+			}
+			if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("invalid problem details %q: %v", res.Body.String(), err)
+			}
+			if problem.Title != ErrRouteNotFound.Title || problem.RequestID != "test-request" {
+				t.Errorf("unexpected problem details %s", res.Body.String())
+			}
+		})
+	}
+
+	t.Run("/data", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/data", nil))
+		if res.Body.String() != `{"requestId":"unchanged"}` {
+			t.Errorf("expected the response to be unchanged, got %s", res.Body.String())
+		}
+	})
+}
@@ -9,8 +9,13 @@ import (
 // GenerateRouter returns a new [*gin.Engine] which has been configured
 // to run in release scenarios.
 // This enables security hardening and decreases the default logging level.
-func GenerateRouter() (*gin.Engine, error) {
-	r := prepareRouter()
+// The public handlers are served for GET requests on their path without
+// requiring a token.
+func GenerateRouter(public map[string]gin.HandlerFunc) (*gin.Engine, error) {
+	r, err := prepareRouter(public)
+	if err != nil {
+		return nil, err
+	}
 	gin.SetMode(gin.ReleaseMode)
 	return r, nil
 
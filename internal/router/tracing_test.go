@@ -0,0 +1,92 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"microservice/internal/tracing"
+)
+
+// tracedRouter returns a router recording the spans into the returned
+// exporter.
+func tracedRouter(t *testing.T) (http.Handler, *sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(exporter, sdktrace.AlwaysSample())
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	// tracing is disabled in the configuration which only sets up the
+	// propagation of the trace context
+	if _, err := tracing.Setup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := prepareRouter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, provider, exporter
+}
+
+func TestServerSpan(t *testing.T) {
+	r, provider, exporter := tracedRouter(t)
+
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected a single span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "GET /healthz" {
+		t.Errorf("expected the span to be named after the route, got %q", span.Name)
+	}
+	if span.SpanKind != trace.SpanKindServer {
+		t.Errorf("expected a server span, got %s", span.SpanKind)
+	}
+	if span.Parent.IsValid() {
+		t.Error("expected a root span without an inbound trace context")
+	}
+}
+
+func TestTraceContextPropagation(t *testing.T) {
+	r, provider, exporter := tracedRouter(t)
+
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected a single span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.SpanContext.TraceID().String() != traceID {
+		t.Errorf("expected the trace %s to be continued, got %s", traceID, span.SpanContext.TraceID())
+	}
+	if span.Parent.SpanID().String() != spanID || !span.Parent.IsRemote() {
+		t.Errorf("expected the remote span %s as parent, got %s", spanID, span.Parent.SpanID())
+	}
+}
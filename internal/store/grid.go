@@ -0,0 +1,75 @@
+package store
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// The cells are computed for every usage location like the grid queries of
+// the database and match the cells of the ST_SquareGrid and ST_HexagonGrid
+// functions of PostGIS.
+// The hexagons are flat-topped and every second column is shifted upwards by
+// half the height of a hexagon.
+
+// hexagonX and hexagonY contain the vertices of a hexagon relative to its
+// center in multiples of the edge length and the height.
+var (
+	hexagonX = []float64{-1.0, -0.5, 0.5, 1.0, 0.5, -0.5, -1.0}
+	hexagonY = []float64{0.0, -0.5, -0.5, 0.0, 0.5, 0.5, 0.0}
+)
+
+// cell returns the column and row of the cell containing the point.
+func (g Grid) cell(x, y float64) (int64, int64) {
+	if g.Shape != GridHexagon {
+		return int64(math.Floor(x / g.CellSize)), int64(math.Floor(y / g.CellSize))
+	}
+
+	// the hexagons contain the points closest to their centers. therefore,
+	// the closest center of the neighbouring columns is searched
+	var column, row int64
+	closest := math.Inf(1)
+	approximate := int64(math.Round(x / (1.5 * g.CellSize))) //nolint:mnd
+	for i := approximate - 1; i <= approximate+1; i++ {
+		centerX, offsetY := g.hexagonOffset(i)
+		j := int64(math.Round((y - offsetY) / g.hexagonHeight()))
+		for candidate := j - 1; candidate <= j+1; candidate++ {
+			centerY := offsetY + float64(candidate)*g.hexagonHeight()
+			if distance := math.Hypot(x-centerX, y-centerY); distance < closest {
+				closest = distance
+				column, row = i, candidate
+			}
+		}
+	}
+	return column, row
+}
+
+// polygon returns the polygon of the cell in EPSG:25832.
+func (g Grid) polygon(column, row int64) geom.T {
+	var coords []float64
+	if g.Shape != GridHexagon {
+		x, y := float64(column)*g.CellSize, float64(row)*g.CellSize
+		coords = []float64{x, y, x, y + g.CellSize, x + g.CellSize, y + g.CellSize, x + g.CellSize, y, x, y}
+	} else {
+		centerX, offsetY := g.hexagonOffset(column)
+		centerY := offsetY + float64(row)*g.hexagonHeight()
+		for idx := range hexagonX {
+			coords = append(coords, centerX+g.CellSize*hexagonX[idx], centerY+g.hexagonHeight()*hexagonY[idx])
+		}
+	}
+	return geom.NewPolygonFlat(geom.XY, coords, []int{len(coords)}).SetSRID(storageCRS)
+}
+
+func (g Grid) hexagonHeight() float64 {
+	return g.CellSize * math.Sqrt(3) //nolint:mnd
+}
+
+// hexagonOffset returns the horizontal center of the column and the vertical
+// shift of its hexagons.
+func (g Grid) hexagonOffset(column int64) (float64, float64) {
+	var offsetY float64
+	if column%2 != 0 {
+		offsetY = g.hexagonHeight() / 2 //nolint:mnd
+	}
+	return 1.5 * g.CellSize * float64(column), offsetY //nolint:mnd
+}
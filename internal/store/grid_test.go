@@ -0,0 +1,28 @@
+package store
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestGridCellContainsPoint(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2)) //nolint:gosec
+
+	for _, shape := range []string{GridSquare, GridHexagon} {
+		for _, size := range []float64{100, 1000, 100000} {
+			grid := Grid{CellSize: size, Shape: shape}
+			for range 1000 {
+				x := 350000 + random.Float64()*200000
+				y := 5700000 + random.Float64()*200000
+
+				column, row := grid.cell(x, y)
+				polygon := grid.polygon(column, row).(*geom.Polygon)
+				if !withinPolygon(geom.Coord{x, y}, polygon) {
+					t.Fatalf("%s cell %d/%d of size %v does not contain the point %v, %v", shape, column, row, size, x, y)
+				}
+			}
+		}
+	}
+}
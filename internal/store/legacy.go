@@ -0,0 +1,194 @@
+package store
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"microservice/types"
+	v2 "microservice/types/v2"
+)
+
+// This file converts the water rights and usage locations into the
+// representation used by the first version of the api. It is only required
+// by stores that do not read the legacy representation from the database.
+
+func legacyWaterRight(r v2.WaterRight) types.WaterRight {
+	return types.WaterRight{
+		ID:                   pgtype.Int8{Int64: int64(r.Identifiers.Database), Valid: true}, //nolint:gosec
+		WaterRightNumber:     pgtype.Int8{Int64: int64(r.Identifiers.Cadenza), Valid: true},  //nolint:gosec
+		Holder:               legacyText(r.Holder),
+		ValidFrom:            legacyDate(r.Validity.From),
+		ValidUntil:           legacyDate(r.Validity.Until),
+		Status:               legacyText(r.Status),
+		LegalTitle:           legacyText(r.LegalTitle),
+		WaterAuthority:       legacyText(r.Authorities.Water),
+		RegisteringAuthority: legacyText(r.Authorities.Registering),
+		GrantingAuthority:    legacyText(r.Authorities.Granting),
+		InitiallyGranted:     r.InitiallyGranted,
+		LastChange:           r.LastChange,
+		FileReference:        legacyText(r.Identifiers.File),
+		ExternalIdentifier:   legacyText(r.Identifiers.External),
+		Subject:              legacyText(r.Subject),
+		Address:              legacyText(r.Address),
+		LegalDepartments:     r.LegalDepartments,
+		Annotation:           legacyText(r.Annotation),
+	}
+}
+
+func legacyUsageLocation(l v2.UsageLocation) types.UsageLocation {
+	location := types.UsageLocation{
+		ID:                     pgtype.Int8{Int64: int64(l.ID), Valid: true},
+		LocationNumber:         &pgtype.Int8{Int64: int64(l.CadenzaID), Valid: true},
+		SerialID:               legacyText(l.Serial),
+		WaterRightID:           pgtype.Int8{Int64: int64(l.WaterRightID), Valid: true},
+		Active:                 legacyBool(l.Active),
+		Real:                   legacyBool(l.Real),
+		Name:                   legacyText(l.Name),
+		LegalPurpose:           l.LegalPurpose,
+		MapExcerpt:             legacyKeyedValue(l.MapExcerpt),
+		MunicipalArea:          legacyKeyedValue(l.MunicipalArea),
+		County:                 legacyText(l.County),
+		Plot:                   legacyText(l.Plot),
+		MaintenanceAssociation: legacyKeyedValue(l.Maintenance),
+		EUSurveyArea:           legacyKeyedValue(l.SurveyArea),
+		CatchmentAreaCode:      legacyKeyedValue(l.CatchmentArea),
+		RegulationCitation:     legacyText(l.RegulationCitation),
+		WithdrawalRates:        legacyRates(l.Rates.Withdrawal),
+		PumpingRates:           legacyRates(l.Rates.Pumping),
+		InjectionRates:         legacyRates(l.Rates.Injection),
+		WasteWaterFlowVolume:   legacyRates(l.Rates.WasteWater),
+		RiverBasin:             legacyText(l.RiverBasin),
+		GroundwaterBody:        legacyText(l.GroundwaterBody),
+		WaterBody:              legacyText(l.WaterBody),
+		FloodArea:              legacyText(l.FloodArea),
+		WaterProtectionArea:    legacyText(l.WaterProtectionArea),
+		FluidDischarge:         legacyRates(l.Rates.FluidDischarges),
+		RainSupplement:         legacyRates(l.Rates.RainSupplements),
+		IrrigationArea:         legacyQuantityPointer(l.IrrigationArea),
+		Location:               l.Geometry,
+	}
+
+	if l.LegalDepartment != nil {
+		location.LegalDepartment = pgtype.Text{String: *l.LegalDepartment, Valid: true}
+	}
+
+	if l.LandRecord != nil {
+		location.LandRecord = &types.LandRecord{
+			District: legacyText(l.LandRecord.District),
+			Fallback: legacyText(l.LandRecord.Fallback),
+		}
+		if l.LandRecord.Field != nil {
+			location.LandRecord.Field = &pgtype.Int8{Int64: *l.LandRecord.Field, Valid: true}
+		}
+	}
+
+	if l.DamTargetLevels != nil {
+		location.DamTargetLevels = &types.DamTarget{
+			Default: legacyQuantityPointer(l.DamTargetLevels.Default),
+			Steady:  legacyQuantityPointer(l.DamTargetLevels.Steady),
+			Max:     legacyQuantityPointer(l.DamTargetLevels.Max),
+		}
+	}
+
+	if l.PhValues != nil {
+		location.PHValues = &pgtype.Range[pgtype.Numeric]{
+			Lower:     legacyNumeric(&l.PhValues.Lower),
+			Upper:     legacyNumeric(&l.PhValues.Upper),
+			LowerType: l.PhValues.LowerType,
+			UpperType: l.PhValues.UpperType,
+			Valid:     l.PhValues.Valid,
+		}
+	}
+
+	for _, limit := range l.InjectionLimits {
+		location.InjectionLimits = append(location.InjectionLimits, types.InjectionLimit{
+			Substance: derefText(legacyText(limit.Substance)),
+			Quantity:  legacyQuantity(limit.Quantity),
+		})
+	}
+
+	return location
+}
+
+func legacyRates(rates []v2.Rate) []types.Rate {
+	if rates == nil {
+		return nil
+	}
+
+	legacy := make([]types.Rate, len(rates))
+	for idx, rate := range rates {
+		value := legacyNumeric(rate.Value)
+		per := rate.Per
+		legacy[idx] = types.Rate{
+			Value: &value,
+			Unit:  legacyText(rate.Unit),
+			Per:   &per,
+		}
+	}
+	return legacy
+}
+
+func legacyQuantity(q v2.Quantity) types.Quantity {
+	return types.Quantity{
+		Value: legacyNumeric(q.Value),
+		Unit:  derefText(legacyText(q.Unit)),
+	}
+}
+
+func legacyQuantityPointer(q *v2.Quantity) *types.Quantity {
+	if q == nil {
+		return nil
+	}
+	quantity := legacyQuantity(*q)
+	return &quantity
+}
+
+func legacyKeyedValue(v *v2.NumericKeyedValue) *types.NumericKeyedValue {
+	if v == nil {
+		return nil
+	}
+
+	value := &types.NumericKeyedValue{Value: legacyText(v.Value)}
+	if v.Key != nil {
+		value.Key = &pgtype.Numeric{Int: big.NewInt(*v.Key), Valid: true}
+	}
+	return value
+}
+
+func legacyNumeric(f *float64) pgtype.Numeric {
+	var n pgtype.Numeric
+	if f != nil {
+		_ = n.Scan(strconv.FormatFloat(*f, 'f', -1, 64))
+	}
+	return n
+}
+
+func legacyText(s *string) *pgtype.Text {
+	if s == nil {
+		return nil
+	}
+	return &pgtype.Text{String: *s, Valid: true}
+}
+
+func derefText(t *pgtype.Text) pgtype.Text {
+	if t == nil {
+		return pgtype.Text{}
+	}
+	return *t
+}
+
+func legacyBool(b *bool) *pgtype.Bool {
+	if b == nil {
+		return nil
+	}
+	return &pgtype.Bool{Bool: *b, Valid: true}
+}
+
+func legacyDate(d pgtype.Date) *pgtype.Date {
+	if !d.Valid {
+		return nil
+	}
+	return &d
+}
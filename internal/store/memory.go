@@ -0,0 +1,680 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+	"github.com/twpayne/go-geom/xy"
+	"github.com/wroge/wgs84/v2"
+
+	"microservice/internal/access"
+	"microservice/types"
+	v2 "microservice/types/v2"
+)
+
+// storageCRS is the reference system the usage locations are stored in.
+const storageCRS = 25832
+
+// Memory keeps the water rights and usage locations in memory.
+// It evaluates the access policy and computes the withdrawals like the
+// database and allows running the handlers without a database.
+type Memory struct {
+	lock      sync.RWMutex
+	rights    map[uint64]v2.WaterRight // by their database id
+	current   map[uint64]uint64        // water right number to database id
+	locations []v2.UsageLocation
+	layers    map[string]referenceLayer
+	changed   time.Time
+}
+
+// referenceLayer is an uploaded reference layer together with its areas.
+type referenceLayer struct {
+	layer v2.ReferenceLayer
+	areas []ReferenceArea
+}
+
+// NewMemory creates an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		rights:  make(map[uint64]v2.WaterRight),
+		current: make(map[uint64]uint64),
+		layers:  make(map[string]referenceLayer),
+		changed: time.Now(),
+	}
+}
+
+// AddWaterRight stores a version of a water right.
+// If current is set, the version replaces the current version of the water
+// right number.
+func (m *Memory) AddWaterRight(waterRight v2.WaterRight, current bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.rights[waterRight.Identifiers.Database] = waterRight
+	if current {
+		m.current[waterRight.Identifiers.Cadenza] = waterRight.Identifiers.Database
+	}
+	m.changed = time.Now()
+}
+
+// RetireWaterRight removes the current version of the water right number.
+func (m *Memory) RetireWaterRight(waterRightNumber uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.current, waterRightNumber)
+	m.changed = time.Now()
+}
+
+// AddUsageLocation stores a usage location. The water right referenced by
+// the usage location needs to be added before.
+func (m *Memory) AddUsageLocation(location v2.UsageLocation) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, found := m.rights[uint64(location.WaterRightID)]; !found { //nolint:gosec
+		return fmt.Errorf("unknown water right %d referenced by usage location %d", location.WaterRightID, location.ID)
+	}
+
+	m.locations = append(m.locations, location)
+	m.changed = time.Now()
+	return nil
+}
+
+// AddReferenceLayer stores the areas of a reference layer. An existing layer
+// with the same name is replaced.
+// The areas may use different reference systems as the usage locations are
+// transformed into the reference system of the area they are compared to.
+func (m *Memory) AddReferenceLayer(name string, areas []ReferenceArea) {
+	srid := storageCRS
+	if len(areas) > 0 && areas[0].Geometry != nil {
+		srid = areas[0].Geometry.SRID()
+	}
+	m.storeReferenceLayer(name, srid, areas)
+}
+
+func (m *Memory) storeReferenceLayer(name string, srid int, areas []ReferenceArea) v2.ReferenceLayer {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	layer := referenceLayer{
+		layer: v2.ReferenceLayer{
+			Name:       name,
+			SourceSRID: srid,
+			Areas:      int64(len(areas)),
+			Uploaded:   time.Now(),
+		},
+		areas: slices.Clone(areas),
+	}
+	m.layers[name] = layer
+	return layer.layer
+}
+
+func (m *Memory) UsageLocations(_ context.Context, restriction access.Restriction) ([]v2.UsageLocation, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.visibleLocations(restriction, func(v2.UsageLocation) bool { return true }), nil
+}
+
+func (m *Memory) WaterRight(_ context.Context, id string, restriction access.Restriction) (v2.WaterRight, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	waterRight, found := m.lookup(id)
+	if !found || !m.rightVisible(waterRight, restriction) {
+		return v2.WaterRight{}, ErrNotFound
+	}
+	return waterRight, nil
+}
+
+func (m *Memory) WaterRights(_ context.Context, restriction access.Restriction) ([]v2.WaterRight, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	waterRights := make([]v2.WaterRight, 0, len(m.rights))
+	for _, waterRight := range m.rights {
+		if m.rightVisible(waterRight, restriction) {
+			waterRights = append(waterRights, waterRight)
+		}
+	}
+	slices.SortFunc(waterRights, func(a, b v2.WaterRight) int {
+		return cmp.Compare(a.Identifiers.Database, b.Identifiers.Database)
+	})
+	return waterRights, nil
+}
+
+func (m *Memory) WaterRightUsageLocations(_ context.Context, waterRight uint64, restriction access.Restriction) ([]v2.UsageLocation, error) { //nolint:lll
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.visibleLocations(restriction, func(l v2.UsageLocation) bool {
+		return uint64(l.WaterRightID) == waterRight //nolint:gosec
+	}), nil
+}
+
+func (m *Memory) LegacyUsageLocations(ctx context.Context, restriction access.Restriction) ([]types.UsageLocation, error) { //nolint:lll
+	locations, err := m.UsageLocations(ctx, restriction)
+	return legacyUsageLocations(locations), err
+}
+
+func (m *Memory) LegacyWaterRight(ctx context.Context, id string, restriction access.Restriction) (types.WaterRight, error) { //nolint:lll
+	waterRight, err := m.WaterRight(ctx, id, restriction)
+	if err != nil {
+		return types.WaterRight{}, err
+	}
+	return legacyWaterRight(waterRight), nil
+}
+
+func (m *Memory) LegacyWaterRightUsageLocations(ctx context.Context, waterRight int64, restriction access.Restriction) ([]types.UsageLocation, error) { //nolint:lll
+	locations, err := m.WaterRightUsageLocations(ctx, uint64(waterRight), restriction) //nolint:gosec
+	return legacyUsageLocations(locations), err
+}
+
+func (m *Memory) NearestUsageLocations(_ context.Context, query NeighbourQuery, restriction access.Restriction) ([]Neighbour, error) { //nolint:lll
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	x, y, _ := wgs84.Transform(wgs84.EPSG(4326), wgs84.EPSG(storageCRS))(query.Longitude, query.Latitude, 0) //nolint:mnd
+
+	// only the usage locations of the current water rights are searched
+	currentVersions := m.currentVersions()
+	neighbours := make([]Neighbour, 0)
+	for _, location := range m.visibleLocations(restriction, func(l v2.UsageLocation) bool {
+		return currentVersions[uint64(l.WaterRightID)] && query.matches(l) //nolint:gosec
+	}) {
+		distance, ok := distanceTo(location.Geometry, x, y)
+		if !ok || (query.MaxDistance != nil && distance > *query.MaxDistance) {
+			continue
+		}
+		neighbours = append(neighbours, Neighbour{UsageLocation: location, Distance: distance})
+	}
+
+	slices.SortFunc(neighbours, func(a, b Neighbour) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.ID, b.ID))
+	})
+
+	start := min(query.Offset, len(neighbours))
+	end := min(start+query.Limit, len(neighbours))
+	return neighbours[start:end], nil
+}
+
+func (m *Memory) WithdrawalRange(_ context.Context, area geom.T, restriction access.Restriction) (WithdrawalRange, error) { //nolint:lll
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var withdrawal WithdrawalRange
+	for _, location := range m.activeLocations(restriction) {
+		if location.Geometry == nil || !within(location.Geometry, area) {
+			continue
+		}
+		minimal, maximal := location.WithdrawalBounds()
+		withdrawal.Minimal += minimal
+		withdrawal.Maximal += maximal
+	}
+	return withdrawal, nil
+}
+
+func (m *Memory) WithdrawalsWithin(_ context.Context, area Circle, restriction access.Restriction) ([]LocationWithdrawal, error) { //nolint:lll
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	x, y, _ := wgs84.Transform(wgs84.EPSG(4326), wgs84.EPSG(storageCRS))(area.Longitude, area.Latitude, 0) //nolint:mnd
+
+	withdrawals := make([]LocationWithdrawal, 0)
+	for _, location := range m.activeLocations(restriction) {
+		distance, ok := distanceTo(location.Geometry, x, y)
+		if !ok || distance > area.Radius {
+			continue
+		}
+		minimal, maximal := location.WithdrawalBounds()
+		withdrawals = append(withdrawals, LocationWithdrawal{
+			Neighbour:         Neighbour{UsageLocation: location, Distance: distance},
+			MinimalWithdrawal: minimal,
+			MaximalWithdrawal: maximal,
+		})
+	}
+
+	slices.SortFunc(withdrawals, func(a, b LocationWithdrawal) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.ID, b.ID))
+	})
+	return withdrawals, nil
+}
+
+func (m *Memory) MunicipalWithdrawals(_ context.Context, restriction access.Restriction) ([]v2.WithdrawalGroup, error) { //nolint:lll
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	groups := make(map[int64]*v2.WithdrawalGroup)
+	for _, location := range m.activeLocations(restriction) {
+		if location.MunicipalArea == nil || location.MunicipalArea.Key == nil {
+			continue
+		}
+
+		key := *location.MunicipalArea.Key
+		group, found := groups[key]
+		if !found {
+			group = &v2.WithdrawalGroup{Key: "0" + strconv.FormatInt(key, 10)}
+			groups[key] = group
+		}
+
+		name := location.MunicipalArea.Value
+		if name != nil && (group.Name == nil || *name < *group.Name) {
+			group.Name = name
+		}
+
+		group.UsageLocations++
+		minimal, maximal := location.WithdrawalBounds()
+		group.MinimalWithdrawal += minimal
+		group.MaximalWithdrawal += maximal
+	}
+
+	keys := make([]int64, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	summary := make([]v2.WithdrawalGroup, 0, len(keys))
+	for _, key := range keys {
+		summary = append(summary, *groups[key])
+	}
+	return summary, nil
+}
+
+func (m *Memory) GridWithdrawals(_ context.Context, grid Grid, restriction access.Restriction) ([]v2.GridCell, error) { //nolint:lll
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	type index struct{ column, row int64 }
+	cells := make(map[index]*v2.GridCell)
+	for _, location := range m.activeLocations(restriction) {
+		p, ok := location.Geometry.(*geom.Point)
+		if !ok || p.Empty() {
+			continue
+		}
+
+		x, y := p.X(), p.Y()
+		if p.SRID() != storageCRS {
+			x, y, _ = wgs84.Transform(wgs84.EPSG(p.SRID()), wgs84.EPSG(storageCRS))(x, y, 0)
+		}
+
+		column, row := grid.cell(x, y)
+		cell, found := cells[index{column, row}]
+		if !found {
+			cell = &v2.GridCell{Column: column, Row: row, Geometry: grid.polygon(column, row)}
+			cells[index{column, row}] = cell
+		}
+
+		cell.UsageLocations++
+		minimal, maximal := location.WithdrawalBounds()
+		cell.MinimalWithdrawal += minimal
+		cell.MaximalWithdrawal += maximal
+	}
+
+	sorted := make([]v2.GridCell, 0, len(cells))
+	for _, cell := range cells {
+		sorted = append(sorted, *cell)
+	}
+	slices.SortFunc(sorted, func(a, b v2.GridCell) int {
+		return cmp.Or(cmp.Compare(a.Column, b.Column), cmp.Compare(a.Row, b.Row))
+	})
+	return sorted, nil
+}
+
+func (m *Memory) LayerWithdrawals(_ context.Context, layer string, restriction access.Restriction) ([]v2.WithdrawalGroup, error) { //nolint:lll
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	referenceLayer, found := m.layers[layer]
+	if !found {
+		return nil, ErrUnknownLayer
+	}
+
+	locations := m.activeLocations(restriction)
+	groups := make([]v2.WithdrawalGroup, 0, len(referenceLayer.areas))
+	for _, area := range referenceLayer.areas {
+		group := v2.WithdrawalGroup{Key: area.Key, Name: area.Name}
+		for _, location := range locations {
+			if location.Geometry == nil || !within(location.Geometry, area.Geometry) {
+				continue
+			}
+			group.UsageLocations++
+			minimal, maximal := location.WithdrawalBounds()
+			group.MinimalWithdrawal += minimal
+			group.MaximalWithdrawal += maximal
+		}
+		groups = append(groups, group)
+	}
+
+	slices.SortFunc(groups, func(a, b v2.WithdrawalGroup) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return groups, nil
+}
+
+func (m *Memory) ReferenceLayers(context.Context) ([]v2.ReferenceLayer, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	layers := make([]v2.ReferenceLayer, 0, len(m.layers))
+	for _, layer := range m.layers {
+		layers = append(layers, layer.layer)
+	}
+	slices.SortFunc(layers, func(a, b v2.ReferenceLayer) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return layers, nil
+}
+
+func (m *Memory) ReplaceReferenceLayer(_ context.Context, name string, srid int, areas []ReferenceArea) (v2.ReferenceLayer, error) { //nolint:lll
+	for idx, area := range areas {
+		if area.Geometry == nil || area.Geometry.SRID() != srid {
+			return v2.ReferenceLayer{}, fmt.Errorf("%w: area %d does not use the reference system %d",
+				ErrInvalidLayer, idx, srid)
+		}
+	}
+	return m.storeReferenceLayer(name, srid, areas), nil
+}
+
+func (m *Memory) DeleteReferenceLayer(_ context.Context, name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, found := m.layers[name]; !found {
+		return ErrUnknownLayer
+	}
+	delete(m.layers, name)
+	return nil
+}
+
+func (m *Memory) LocationIssues(_ context.Context, checks LocationChecks, restriction access.Restriction) ([]v2.LocationIssue, error) { //nolint:lll
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	issues := make([]v2.LocationIssue, 0)
+	report := func(location v2.UsageLocation, check, detail string) {
+		issues = append(issues, v2.LocationIssue{
+			UsageLocation: location.ID,
+			WaterRight:    location.WaterRightID,
+			Check:         check,
+			Detail:        detail,
+		})
+	}
+
+	// points contains the usage locations with a valid point in the
+	// reference system the usage locations are stored in
+	type point struct {
+		location v2.UsageLocation
+		x, y     float64
+	}
+	var points []point
+	currentVersions := m.currentVersions()
+	for _, location := range m.visibleLocations(restriction, func(l v2.UsageLocation) bool {
+		return currentVersions[uint64(l.WaterRightID)] //nolint:gosec
+	}) {
+		if location.Geometry == nil {
+			report(location, v2.CheckMissingGeometry, "the usage location has no geometry")
+			continue
+		}
+
+		p, ok := location.Geometry.(*geom.Point)
+		switch {
+		case !ok:
+			report(location, v2.CheckInvalidGeometry, "the geometry is not a point")
+			continue
+		case p.Empty():
+			report(location, v2.CheckInvalidGeometry, "the point is empty")
+			continue
+		case !finite(p.X()) || !finite(p.Y()):
+			report(location, v2.CheckInvalidGeometry, "the point has invalid coordinates")
+			continue
+		case p.X() == 0 && p.Y() == 0:
+			report(location, v2.CheckInvalidGeometry, "the point is located at (0, 0)")
+			continue
+		}
+
+		x, y := p.X(), p.Y()
+		if p.SRID() != storageCRS {
+			x, y, _ = wgs84.Transform(wgs84.EPSG(p.SRID()), wgs84.EPSG(storageCRS))(x, y, 0)
+		}
+		points = append(points, point{location: location, x: x, y: y})
+
+		longitude, latitude, _ := wgs84.Transform(wgs84.EPSG(storageCRS), wgs84.EPSG(4326))(x, y, 0) //nolint:mnd
+		if longitude < checks.Extent[0] || longitude > checks.Extent[2] ||
+			latitude < checks.Extent[1] || latitude > checks.Extent[3] {
+			report(location, v2.CheckOutsideExtent, fmt.Sprintf(
+				"the point (%.6f, %.6f) is located outside of the expected extent", longitude, latitude))
+		}
+
+		// the keys of the reference layer may omit the leading zero of the
+		// municipality keys
+		if location.MunicipalArea != nil && location.MunicipalArea.Key != nil {
+			key := strconv.FormatInt(*location.MunicipalArea.Key, 10)
+			var keys []string
+			matches := false
+			for _, area := range m.layers[checks.MunicipalityLayer].areas {
+				if !within(location.Geometry, area.Geometry) {
+					continue
+				}
+				keys = append(keys, area.Key)
+				matches = matches || strings.TrimLeft(area.Key, "0") == key
+			}
+			if len(keys) > 0 && !matches {
+				slices.Sort(keys)
+				report(location, v2.CheckMunicipalityMismatch, fmt.Sprintf(
+					"the municipality key 0%s does not match the municipalities containing the point (%s)",
+					key, strings.Join(keys, ", ")))
+			}
+		}
+	}
+
+	for _, p := range points {
+		var duplicates []int
+		for _, other := range points {
+			if other.location.ID != p.location.ID && math.Hypot(p.x-other.x, p.y-other.y) <= checks.DuplicateDistance {
+				duplicates = append(duplicates, other.location.ID)
+			}
+		}
+		if len(duplicates) == 0 {
+			continue
+		}
+
+		slices.Sort(duplicates)
+		ids := make([]string, len(duplicates))
+		for idx, id := range duplicates {
+			ids[idx] = strconv.Itoa(id)
+		}
+		report(p.location, v2.CheckDuplicateLocation, fmt.Sprintf(
+			"the point is within %s m of the usage locations %s",
+			strconv.FormatFloat(checks.DuplicateDistance, 'f', -1, 64), strings.Join(ids, ", ")))
+	}
+
+	slices.SortStableFunc(issues, func(a, b v2.LocationIssue) int {
+		return cmp.Or(cmp.Compare(a.UsageLocation, b.UsageLocation), cmp.Compare(a.Check, b.Check))
+	})
+	return issues, nil
+}
+
+// Freshness returns the time of the last change as the withdrawals are
+// computed for every request.
+func (m *Memory) Freshness(context.Context) (time.Time, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.changed, nil
+}
+
+// lookup returns the water right with the database id or the current version
+// of the water right number. The lock needs to be held.
+func (m *Memory) lookup(id string) (v2.WaterRight, bool) {
+	number, err := strconv.ParseUint(strings.TrimSpace(id), 10, 64)
+	if err != nil {
+		return v2.WaterRight{}, false
+	}
+
+	if waterRight, found := m.rights[number]; found {
+		return waterRight, true
+	}
+	if version, found := m.current[number]; found {
+		return m.rights[version], true
+	}
+	return v2.WaterRight{}, false
+}
+
+func (m *Memory) rightVisible(waterRight v2.WaterRight, restriction access.Restriction) bool {
+	return restriction.Permits(waterRight.LegalDepartments, waterRight.Authorities.Water)
+}
+
+// visibleLocations returns copies of the visible usage locations matching the
+// filter. The lock needs to be held.
+func (m *Memory) visibleLocations(restriction access.Restriction, filter func(v2.UsageLocation) bool) []v2.UsageLocation {
+	var locations []v2.UsageLocation
+	for _, location := range m.locations {
+		if !filter(location) || !m.locationVisible(location, restriction) {
+			continue
+		}
+		// the geometries are reprojected in place while marshalling the
+		// locations and are therefore copied
+		location.Geometry = cloneGeometry(location.Geometry)
+		locations = append(locations, location)
+	}
+	return locations
+}
+
+func (m *Memory) locationVisible(location v2.UsageLocation, restriction access.Restriction) bool {
+	var departments []string
+	if location.LegalDepartment != nil {
+		departments = []string{*location.LegalDepartment}
+	}
+	waterRight := m.rights[uint64(location.WaterRightID)] //nolint:gosec
+	return restriction.Permits(departments, waterRight.Authorities.Water)
+}
+
+// activeLocations returns the visible active usage locations of the current
+// water rights. The lock needs to be held.
+func (m *Memory) activeLocations(restriction access.Restriction) []v2.UsageLocation {
+	currentVersions := m.currentVersions()
+	return m.visibleLocations(restriction, func(l v2.UsageLocation) bool {
+		return l.Active != nil && *l.Active && currentVersions[uint64(l.WaterRightID)] //nolint:gosec
+	})
+}
+
+// currentVersions returns the database ids of the current versions of the
+// water rights. The lock needs to be held.
+func (m *Memory) currentVersions() map[uint64]bool {
+	currentVersions := make(map[uint64]bool, len(m.current))
+	for _, version := range m.current {
+		currentVersions[version] = true
+	}
+	return currentVersions
+}
+
+// matches reports if the usage location matches the filters of the query.
+func (query NeighbourQuery) matches(location v2.UsageLocation) bool {
+	if query.Active != nil && (location.Active == nil || *location.Active != *query.Active) {
+		return false
+	}
+	if query.Virtual != nil && (location.Real == nil || *location.Real == *query.Virtual) {
+		return false
+	}
+	if len(query.LegalDepartments) > 0 &&
+		(location.LegalDepartment == nil || !slices.Contains(query.LegalDepartments, *location.LegalDepartment)) {
+		return false
+	}
+	return true
+}
+
+func legacyUsageLocations(locations []v2.UsageLocation) []types.UsageLocation {
+	legacy := make([]types.UsageLocation, len(locations))
+	for idx, location := range locations {
+		legacy[idx] = legacyUsageLocation(location)
+	}
+	return legacy
+}
+
+// within reports if the point lies within the polygonal area. The point is
+// transformed into the reference system of the area.
+func within(point geom.T, area geom.T) bool {
+	p, ok := point.(*geom.Point)
+	if !ok || p.Empty() {
+		return false
+	}
+
+	coord := p.Coords()
+	if p.SRID() != area.SRID() {
+		transform := wgs84.Transform(wgs84.EPSG(p.SRID()), wgs84.EPSG(area.SRID()))
+		x, y, _ := transform(coord.X(), coord.Y(), 0)
+		coord = geom.Coord{x, y}
+	}
+
+	switch a := area.(type) {
+	case *geom.Polygon:
+		return withinPolygon(coord, a)
+	case *geom.MultiPolygon:
+		for idx := range a.NumPolygons() {
+			if withinPolygon(coord, a.Polygon(idx)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// distanceTo returns the distance between the point and the coordinates in
+// the reference system the usage locations are stored in.
+func distanceTo(point geom.T, x, y float64) (float64, bool) {
+	p, ok := point.(*geom.Point)
+	if !ok || p.Empty() {
+		return 0, false
+	}
+
+	px, py := p.X(), p.Y()
+	if p.SRID() != storageCRS {
+		px, py, _ = wgs84.Transform(wgs84.EPSG(p.SRID()), wgs84.EPSG(storageCRS))(px, py, 0)
+	}
+	return math.Hypot(px-x, py-y), true
+}
+
+// finite reports if the coordinate is neither infinite nor not a number.
+func finite(coordinate float64) bool {
+	return !math.IsInf(coordinate, 0) && !math.IsNaN(coordinate)
+}
+
+func withinPolygon(coord geom.Coord, polygon *geom.Polygon) bool {
+	if polygon.NumLinearRings() == 0 {
+		return false
+	}
+	if !xy.IsPointInRing(polygon.Layout(), coord, polygon.LinearRing(0).FlatCoords()) {
+		return false
+	}
+	for idx := 1; idx < polygon.NumLinearRings(); idx++ {
+		if xy.IsPointInRing(polygon.Layout(), coord, polygon.LinearRing(idx).FlatCoords()) {
+			return false
+		}
+	}
+	return true
+}
+
+func cloneGeometry(g geom.T) geom.T {
+	if g == nil {
+		return nil
+	}
+	encoded, err := ewkb.Marshal(g, ewkb.NDR)
+	if err != nil {
+		return g
+	}
+	clone, err := ewkb.Unmarshal(encoded)
+	if err != nil {
+		return g
+	}
+	return clone
+}
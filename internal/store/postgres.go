@@ -0,0 +1,265 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/twpayne/go-geom"
+
+	"microservice/internal/access"
+	"microservice/internal/db"
+	"microservice/internal/views"
+	"microservice/types"
+	v2 "microservice/types/v2"
+)
+
+// Postgres reads the water rights from the database using the shared
+// connection pool and the loaded queries.
+type Postgres struct{}
+
+// NewPostgres creates a store reading from the database.
+// The database connection is only used once the first method is called and
+// may therefore be set up after creating the store.
+func NewPostgres() *Postgres {
+	return &Postgres{}
+}
+
+func (p *Postgres) UsageLocations(ctx context.Context, restriction access.Restriction) ([]v2.UsageLocation, error) {
+	var locations []v2.UsageLocation
+	if err := p.selectAll(ctx, &locations, "get-locations", restriction.Arg()); err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+func (p *Postgres) WaterRight(ctx context.Context, id string, restriction access.Restriction) (v2.WaterRight, error) {
+	var waterRight v2.WaterRight
+	err := p.get(ctx, &waterRight, "v2_get-water-right", id, restriction.Arg())
+	return waterRight, err
+}
+
+func (p *Postgres) WaterRights(ctx context.Context, restriction access.Restriction) ([]v2.WaterRight, error) {
+	waterRights := make([]v2.WaterRight, 0)
+	if err := p.selectAll(ctx, &waterRights, "v2_get-water-rights", restriction.Arg()); err != nil {
+		return nil, err
+	}
+	return waterRights, nil
+}
+
+func (p *Postgres) WaterRightUsageLocations(ctx context.Context, waterRight uint64, restriction access.Restriction) ([]v2.UsageLocation, error) { //nolint:lll
+	var locations []v2.UsageLocation
+	if err := p.selectAll(ctx, &locations, "v2_get-water-right-usage-locations", waterRight, restriction.Arg()); err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+func (p *Postgres) LegacyUsageLocations(ctx context.Context, restriction access.Restriction) ([]types.UsageLocation, error) { //nolint:lll
+	var locations []types.UsageLocation
+	if err := p.selectAll(ctx, &locations, "get-locations", restriction.Arg()); err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+func (p *Postgres) LegacyWaterRight(ctx context.Context, id string, restriction access.Restriction) (types.WaterRight, error) { //nolint:lll
+	var waterRight types.WaterRight
+	err := p.get(ctx, &waterRight, "v2_get-water-right", id, restriction.Arg())
+	return waterRight, err
+}
+
+func (p *Postgres) LegacyWaterRightUsageLocations(ctx context.Context, waterRight int64, restriction access.Restriction) ([]types.UsageLocation, error) { //nolint:lll
+	var locations []types.UsageLocation
+	if err := p.selectAll(ctx, &locations, "v2_get-water-right-usage-locations", waterRight, restriction.Arg()); err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+func (p *Postgres) NearestUsageLocations(ctx context.Context, query NeighbourQuery, restriction access.Restriction) ([]Neighbour, error) { //nolint:lll
+	var legalDepartments []string
+	if len(query.LegalDepartments) > 0 {
+		legalDepartments = query.LegalDepartments
+	}
+
+	neighbours := make([]Neighbour, 0)
+	err := p.selectAll(ctx, &neighbours, "v2_get-nearest-usage-locations",
+		query.Longitude, query.Latitude, query.MaxDistance, query.Active, query.Virtual, legalDepartments,
+		restriction.Arg(), query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return neighbours, nil
+}
+
+func (p *Postgres) WithdrawalRange(ctx context.Context, area geom.T, restriction access.Restriction) (WithdrawalRange, error) { //nolint:lll
+	query, err := db.Queries.Raw("get-withdrawal-range")
+	if err != nil {
+		return WithdrawalRange{}, err
+	}
+
+	var withdrawal WithdrawalRange
+	err = db.Pool().QueryRow(ctx, query, area, restriction.Arg()).Scan(&withdrawal.Minimal, &withdrawal.Maximal)
+	return withdrawal, err
+}
+
+func (p *Postgres) WithdrawalsWithin(ctx context.Context, area Circle, restriction access.Restriction) ([]LocationWithdrawal, error) { //nolint:lll
+	withdrawals := make([]LocationWithdrawal, 0)
+	err := p.selectAll(ctx, &withdrawals, "v2_get-withdrawals-within",
+		area.Longitude, area.Latitude, area.Radius, restriction.Arg())
+	if err != nil {
+		return nil, err
+	}
+	return withdrawals, nil
+}
+
+func (p *Postgres) MunicipalWithdrawals(ctx context.Context, restriction access.Restriction) ([]v2.WithdrawalGroup, error) { //nolint:lll
+	groups := make([]v2.WithdrawalGroup, 0)
+	if err := p.selectAll(ctx, &groups, "v2_get-municipal-withdrawals", restriction.Arg()); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (p *Postgres) GridWithdrawals(ctx context.Context, grid Grid, restriction access.Restriction) ([]v2.GridCell, error) { //nolint:lll
+	queryName := "v2_get-square-grid-withdrawals"
+	if grid.Shape == GridHexagon {
+		queryName = "v2_get-hexagon-grid-withdrawals"
+	}
+
+	cells := make([]v2.GridCell, 0)
+	if err := p.selectAll(ctx, &cells, queryName, grid.CellSize, restriction.Arg()); err != nil {
+		return nil, err
+	}
+	return cells, nil
+}
+
+func (p *Postgres) LayerWithdrawals(ctx context.Context, layer string, restriction access.Restriction) ([]v2.WithdrawalGroup, error) { //nolint:lll
+	var referenceLayer v2.ReferenceLayer
+	if err := p.get(ctx, &referenceLayer, "get-reference-layer", layer); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrUnknownLayer
+		}
+		return nil, err
+	}
+
+	groups := make([]v2.WithdrawalGroup, 0)
+	if err := p.selectAll(ctx, &groups, "get-layer-withdrawals", layer, restriction.Arg()); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (p *Postgres) ReferenceLayers(ctx context.Context) ([]v2.ReferenceLayer, error) {
+	layers := make([]v2.ReferenceLayer, 0)
+	if err := p.selectAll(ctx, &layers, "get-reference-layers"); err != nil {
+		return nil, err
+	}
+	return layers, nil
+}
+
+func (p *Postgres) ReplaceReferenceLayer(ctx context.Context, name string, srid int, areas []ReferenceArea) (v2.ReferenceLayer, error) { //nolint:lll
+	queries := make(map[string]string)
+	for _, queryName := range []string{"delete-reference-layer", "create-reference-layer", "create-reference-area", "get-reference-layer"} { //nolint:lll
+		query, err := db.Queries.Raw(queryName)
+		if err != nil {
+			return v2.ReferenceLayer{}, err
+		}
+		queries[queryName] = query
+	}
+
+	var layer v2.ReferenceLayer
+	err := pgx.BeginFunc(ctx, db.Pool(), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, queries["delete-reference-layer"], name); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, queries["create-reference-layer"], name, srid); err != nil {
+			return err
+		}
+
+		batch := &pgx.Batch{}
+		for _, area := range areas {
+			batch.Queue(queries["create-reference-area"], name, area.Key, area.Name, area.Properties, area.Geometry)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return err
+		}
+
+		return pgxscan.Get(ctx, tx, &layer, queries["get-reference-layer"], name)
+	})
+
+	// postgis reports geometries it is unable to process (e.g., as their
+	// reference system is unknown) as internal errors
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.Code == "XX000" || strings.HasPrefix(pgErr.Code, "22")) {
+		return v2.ReferenceLayer{}, fmt.Errorf("%w: %s", ErrInvalidLayer, pgErr.Message)
+	}
+	return layer, err
+}
+
+func (p *Postgres) DeleteReferenceLayer(ctx context.Context, name string) error {
+	query, err := db.Queries.Raw("delete-reference-layer")
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Pool().Exec(ctx, query, name)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrUnknownLayer
+	}
+	return nil
+}
+
+func (p *Postgres) LocationIssues(ctx context.Context, checks LocationChecks, restriction access.Restriction) ([]v2.LocationIssue, error) { //nolint:lll
+	issues := make([]v2.LocationIssue, 0)
+	err := p.selectAll(ctx, &issues, "get-location-issues",
+		checks.Extent[0], checks.Extent[1], checks.Extent[2], checks.Extent[3],
+		checks.MunicipalityLayer, checks.DuplicateDistance, restriction.Arg())
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+func (p *Postgres) Freshness(ctx context.Context) (time.Time, error) {
+	return views.Freshness(ctx, views.UsageLocationWithdrawals, views.MunicipalWithdrawals)
+}
+
+// get reads a single row into the destination and reports a missing row as
+// [ErrNotFound].
+func (p *Postgres) get(ctx context.Context, dst any, queryName string, args ...any) error {
+	query, err := db.Queries.Raw(queryName)
+	if err != nil {
+		return err
+	}
+
+	err = pgxscan.Get(ctx, db.Pool(), dst, query, args...)
+	if pgxscan.NotFound(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// selectAll reads all rows into the destination. No rows are not reported as
+// an error.
+func (p *Postgres) selectAll(ctx context.Context, dst any, queryName string, args ...any) error {
+	query, err := db.Queries.Raw(queryName)
+	if err != nil {
+		return err
+	}
+
+	err = pgxscan.Select(ctx, db.Pool(), dst, query, args...)
+	if pgxscan.NotFound(err) {
+		return nil
+	}
+	return err
+}
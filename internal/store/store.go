@@ -0,0 +1,203 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/twpayne/go-geom"
+
+	"microservice/internal/access"
+	"microservice/types"
+	v2 "microservice/types/v2"
+)
+
+// ErrNotFound is returned if the requested water right does not exist or is
+// hidden by the access policy.
+var ErrNotFound = errors.New("water right not found")
+
+// ErrUnknownLayer is returned if the requested reference layer has not been
+// uploaded.
+var ErrUnknownLayer = errors.New("unknown reference layer")
+
+// ErrInvalidLayer is returned if the areas of an uploaded reference layer can
+// not be stored, e.g., as their reference system is unknown.
+var ErrInvalidLayer = errors.New("invalid reference layer")
+
+// WaterRightStore provides read access to the water rights, their usage
+// locations and the withdrawals derived from them.
+//
+// All methods only return the water rights and usage locations that are
+// visible with the supplied restriction.
+// The methods prefixed with Legacy return the representation used by the
+// first version of the api.
+type WaterRightStore interface {
+	// UsageLocations returns all usage locations.
+	UsageLocations(ctx context.Context, restriction access.Restriction) ([]v2.UsageLocation, error)
+
+	// WaterRight returns the water right with the supplied database id or
+	// water right number.
+	WaterRight(ctx context.Context, id string, restriction access.Restriction) (v2.WaterRight, error)
+
+	// WaterRights returns all versions of all water rights ordered by their
+	// database id.
+	WaterRights(ctx context.Context, restriction access.Restriction) ([]v2.WaterRight, error)
+
+	// WaterRightUsageLocations returns the usage locations of the water right
+	// with the supplied database id.
+	WaterRightUsageLocations(ctx context.Context, waterRight uint64, restriction access.Restriction) ([]v2.UsageLocation, error) //nolint:lll
+
+	// LegacyUsageLocations returns all usage locations.
+	LegacyUsageLocations(ctx context.Context, restriction access.Restriction) ([]types.UsageLocation, error)
+
+	// LegacyWaterRight returns the water right with the supplied database id
+	// or water right number.
+	LegacyWaterRight(ctx context.Context, id string, restriction access.Restriction) (types.WaterRight, error)
+
+	// LegacyWaterRightUsageLocations returns the usage locations of the water
+	// right with the supplied database id.
+	LegacyWaterRightUsageLocations(ctx context.Context, waterRight int64, restriction access.Restriction) ([]types.UsageLocation, error) //nolint:lll
+
+	// NearestUsageLocations returns the usage locations of the current water
+	// rights matching the query ordered by their distance to the point of the
+	// query.
+	NearestUsageLocations(ctx context.Context, query NeighbourQuery, restriction access.Restriction) ([]Neighbour, error) //nolint:lll
+
+	// WithdrawalRange returns the summed annual withdrawal of the active usage
+	// locations of the current water rights within the area.
+	// The area is expected in EPSG:4326.
+	WithdrawalRange(ctx context.Context, area geom.T, restriction access.Restriction) (WithdrawalRange, error)
+
+	// WithdrawalsWithin returns the active usage locations of the current
+	// water rights within the circle together with their annual withdrawal
+	// ordered by their distance to the center of the circle.
+	WithdrawalsWithin(ctx context.Context, area Circle, restriction access.Restriction) ([]LocationWithdrawal, error) //nolint:lll
+
+	// MunicipalWithdrawals returns the summed annual withdrawals of the active
+	// usage locations of the current water rights per municipality.
+	MunicipalWithdrawals(ctx context.Context, restriction access.Restriction) ([]v2.WithdrawalGroup, error)
+
+	// GridWithdrawals returns the summed annual withdrawals of the active
+	// usage locations of the current water rights per cell of a regular grid
+	// in EPSG:25832. Only cells containing usage locations are returned.
+	GridWithdrawals(ctx context.Context, grid Grid, restriction access.Restriction) ([]v2.GridCell, error)
+
+	// LayerWithdrawals returns the summed annual withdrawals of the active
+	// usage locations of the current water rights per area of the reference
+	// layer.
+	LayerWithdrawals(ctx context.Context, layer string, restriction access.Restriction) ([]v2.WithdrawalGroup, error) //nolint:lll
+
+	// ReferenceLayers returns the uploaded reference layers ordered by their
+	// name. The reference layers do not contain water rights and are
+	// therefore not restricted.
+	ReferenceLayers(ctx context.Context) ([]v2.ReferenceLayer, error)
+
+	// ReplaceReferenceLayer stores the areas as reference layer and replaces
+	// an existing layer with the same name. The areas are expected in the
+	// reference system identified by the srid.
+	ReplaceReferenceLayer(ctx context.Context, name string, srid int, areas []ReferenceArea) (v2.ReferenceLayer, error) //nolint:lll
+
+	// DeleteReferenceLayer removes the reference layer and its areas.
+	DeleteReferenceLayer(ctx context.Context, name string) error
+
+	// LocationIssues applies the plausibility checks to the usage locations of
+	// the current water rights and returns the issues ordered by the usage
+	// location and the check.
+	LocationIssues(ctx context.Context, checks LocationChecks, restriction access.Restriction) ([]v2.LocationIssue, error) //nolint:lll
+
+	// Freshness returns the time up to which changes are reflected in the
+	// precomputed withdrawals.
+	Freshness(ctx context.Context) (time.Time, error)
+}
+
+// WithdrawalRange contains the lower and upper bound of an annual withdrawal
+// in cubic meters.
+// Usage locations with multiple withdrawal rates contribute their smallest
+// rate to the minimal and their largest rate to the maximal withdrawal.
+type WithdrawalRange struct {
+	Minimal float64
+	Maximal float64
+}
+
+// NeighbourQuery describes a search for the usage locations closest to a
+// point. The filters are only applied if they are set.
+type NeighbourQuery struct {
+	// Longitude and Latitude of the point in EPSG:4326.
+	Longitude float64
+	Latitude  float64
+
+	// MaxDistance limits the distance of the usage locations in meters.
+	MaxDistance *float64
+
+	Active           *bool
+	Virtual          *bool
+	LegalDepartments []string
+
+	// Offset is the number of closer usage locations skipped and Limit is the
+	// number of usage locations returned.
+	Offset int
+	Limit  int
+}
+
+// Neighbour is a usage location found by a nearest-neighbour search.
+// The distance is measured in meters in EPSG:25832 which is used to store the
+// usage locations.
+type Neighbour struct {
+	v2.UsageLocation
+	Distance float64 `db:"distance"`
+}
+
+// Circle is an area around a point.
+type Circle struct {
+	// Longitude and Latitude of the center in EPSG:4326.
+	Longitude float64
+	Latitude  float64
+
+	// Radius in meters.
+	Radius float64
+}
+
+// LocationWithdrawal is a usage location together with its distance and
+// annual withdrawal range in cubic meters.
+type LocationWithdrawal struct {
+	Neighbour
+	MinimalWithdrawal float64 `db:"minimal_withdrawal"`
+	MaximalWithdrawal float64 `db:"maximal_withdrawal"`
+}
+
+// ReferenceArea is a polygon of a reference layer.
+type ReferenceArea struct {
+	Key        string
+	Name       *string
+	Properties map[string]any
+	Geometry   geom.T // a polygon or multipolygon
+}
+
+// The shapes of the grid cells.
+const (
+	GridSquare  = "square"
+	GridHexagon = "hex"
+)
+
+// Grid describes a regular grid. The cell size is the edge length of the
+// cells in meters.
+type Grid struct {
+	CellSize float64
+	Shape    string
+}
+
+// LocationChecks configures the plausibility checks of the usage locations.
+type LocationChecks struct {
+	// Extent is the bounding box the usage locations are expected in. The
+	// box is given in EPSG:4326 as west, south, east and north.
+	Extent [4]float64
+
+	// MunicipalityLayer names the reference layer containing the
+	// municipalities keyed by their municipality key. The check is skipped if
+	// the layer has not been uploaded.
+	MunicipalityLayer string
+
+	// DuplicateDistance is the distance in meters up to which two usage
+	// locations are reported as duplicates.
+	DuplicateDistance float64
+}
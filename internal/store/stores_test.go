@@ -0,0 +1,326 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+
+	"microservice/internal/access"
+	"microservice/internal/configuration"
+	"microservice/internal/db"
+	"microservice/internal/store"
+	"microservice/internal/store/storetest"
+	"microservice/internal/views"
+)
+
+// integrationEnv enables running the cases against a PostgreSQL database
+// with the PostGIS extension. The database is configured like the service
+// (e.g. using PGHOST, PGUSER and PGPASSWORD). Its water rights are replaced
+// by the fixtures, so a disposable database needs to be used.
+const integrationEnv = "STORE_INTEGRATION_TEST"
+
+// departmentB only permits the water rights of the legal department B.
+var departmentB = access.Restriction{{LegalDepartments: []string{"B"}}}
+
+// hasbergen is a polygon in EPSG:4326 around the usage locations of the
+// water right 4711.
+var hasbergen = geom.NewPolygonFlat(geom.XY,
+	[]float64{7.9, 52.23, 7.95, 52.23, 7.95, 52.27, 7.9, 52.27, 7.9, 52.23}, []int{10}).SetSRID(4326)
+
+// elevatedHasbergen is the polygon around the usage locations of the water
+// right 4711 with an elevation.
+var elevatedHasbergen = geom.NewPolygonFlat(geom.XYZ,
+	[]float64{7.9, 52.23, 50, 7.95, 52.23, 50, 7.95, 52.27, 50, 7.9, 52.27, 50, 7.9, 52.23, 50}, []int{15}).SetSRID(4326)
+
+// storeCase is executed against every store. The result is reduced to the
+// attributes both stores are expected to agree on.
+type storeCase struct {
+	name     string
+	run      func(ctx context.Context, s store.WaterRightStore) (any, error)
+	expected any
+	err      error
+}
+
+var storeCases = []storeCase{
+	{
+		name: "water right by number",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			waterRight, err := s.WaterRight(ctx, "4711", nil)
+			return waterRight.Identifiers.Database, err
+		},
+		expected: uint64(2),
+	},
+	{
+		name: "water right by database id",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			waterRight, err := s.WaterRight(ctx, "1", nil)
+			return waterRight.Identifiers.Database, err
+		},
+		expected: uint64(1),
+	},
+	{
+		name: "unknown water right",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			_, err := s.WaterRight(ctx, "9999", nil)
+			return nil, err
+		},
+		err: store.ErrNotFound,
+	},
+	{
+		name: "hidden water right",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			_, err := s.WaterRight(ctx, "4711", departmentB)
+			return nil, err
+		},
+		err: store.ErrNotFound,
+	},
+	{
+		name: "legacy water right by number",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			waterRight, err := s.LegacyWaterRight(ctx, "4711", nil)
+			return waterRight.ID.Int64, err
+		},
+		expected: int64(2),
+	},
+	{
+		name: "water rights",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			waterRights, err := s.WaterRights(ctx, nil)
+			ids := make([]uint64, 0, len(waterRights))
+			for _, waterRight := range waterRights {
+				ids = append(ids, waterRight.Identifiers.Database)
+			}
+			return ids, err
+		},
+		expected: []uint64{1, 2, 3},
+	},
+	{
+		name: "usage locations",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			locations, err := s.UsageLocations(ctx, nil)
+			ids := make([]int, 0, len(locations))
+			for _, location := range locations {
+				ids = append(ids, location.ID)
+			}
+			slices.Sort(ids)
+			return ids, err
+		},
+		expected: []int{10, 11, 12},
+	},
+	{
+		name: "restricted usage locations",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			locations, err := s.UsageLocations(ctx, departmentB)
+			ids := make([]int, 0, len(locations))
+			for _, location := range locations {
+				ids = append(ids, location.ID)
+			}
+			return ids, err
+		},
+		expected: []int{12},
+	},
+	{
+		name: "usage locations of a water right",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			locations, err := s.WaterRightUsageLocations(ctx, 2, nil)
+			ids := make([]int, 0, len(locations))
+			for _, location := range locations {
+				ids = append(ids, location.ID)
+			}
+			slices.Sort(ids)
+			return ids, err
+		},
+		expected: []int{10, 11},
+	},
+	{
+		name: "nearest usage locations",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			neighbours, err := s.NearestUsageLocations(ctx, store.NeighbourQuery{
+				Longitude: 7.9273, Latitude: 52.2578, Active: storetest.Ptr(true), Limit: 10,
+			}, nil)
+			ids := make([]int, 0, len(neighbours))
+			for _, neighbour := range neighbours {
+				ids = append(ids, neighbour.ID)
+			}
+			return ids, err
+		},
+		expected: []int{10, 12},
+	},
+	{
+		name: "withdrawals within a circle",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			withdrawals, err := s.WithdrawalsWithin(ctx, store.Circle{
+				Longitude: 7.9273, Latitude: 52.2578, Radius: 1000,
+			}, nil)
+			ids := make([]int, 0, len(withdrawals))
+			for _, withdrawal := range withdrawals {
+				ids = append(ids, withdrawal.ID)
+			}
+			return ids, err
+		},
+		expected: []int{10},
+	},
+	{
+		name: "withdrawal range",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			withdrawal, err := s.WithdrawalRange(ctx, hasbergen, nil)
+			return withdrawal, err
+		},
+		expected: store.WithdrawalRange{Minimal: 120000, Maximal: 120000},
+	},
+	{
+		name: "municipal withdrawals",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			groups, err := s.MunicipalWithdrawals(ctx, nil)
+			keys := make([]string, 0, len(groups))
+			for _, group := range groups {
+				keys = append(keys, group.Key)
+			}
+			return keys, err
+		},
+		expected: []string{"03404000", "03459040"},
+	},
+	{
+		name: "grid withdrawals",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			cells, err := s.GridWithdrawals(ctx, store.Grid{CellSize: 5000, Shape: store.GridSquare}, nil)
+			positions := make([][2]int64, 0, len(cells))
+			for _, cell := range cells {
+				positions = append(positions, [2]int64{cell.Column, cell.Row})
+			}
+			slices.SortFunc(positions, func(a, b [2]int64) int {
+				return int(a[0] - b[0])
+			})
+			return positions, err
+		},
+		expected: [][2]int64{{85, 1158}, {87, 1158}},
+	},
+	{
+		name: "reference layer lifecycle",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			_, err := s.ReplaceReferenceLayer(ctx, "zones", 4326, []store.ReferenceArea{
+				{Key: "flat", Geometry: hasbergen},
+				{Key: "elevated", Name: storetest.Ptr("Hasbergen"), Geometry: elevatedHasbergen},
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			layers, err := s.ReferenceLayers(ctx)
+			if err != nil {
+				return nil, err
+			}
+			groups, err := s.LayerWithdrawals(ctx, "zones", nil)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.DeleteReferenceLayer(ctx, "zones"); err != nil {
+				return nil, err
+			}
+
+			result := []string{}
+			for _, layer := range layers {
+				result = append(result, fmt.Sprintf("layer %s: %d areas in EPSG:%d", layer.Name, layer.Areas, layer.SourceSRID))
+			}
+			for _, group := range groups {
+				result = append(result, fmt.Sprintf("area %s: %d usage locations", group.Key, group.UsageLocations))
+			}
+			if err := s.DeleteReferenceLayer(ctx, "zones"); !errors.Is(err, store.ErrUnknownLayer) {
+				return nil, fmt.Errorf("expected the deleted layer to be unknown, got %w", err)
+			}
+			return result, nil
+		},
+		expected: []string{
+			"layer zones: 2 areas in EPSG:4326",
+			"area elevated: 1 usage locations",
+			"area flat: 1 usage locations",
+		},
+	},
+	{
+		name: "location issues",
+		run: func(ctx context.Context, s store.WaterRightStore) (any, error) {
+			issues, err := s.LocationIssues(ctx, store.LocationChecks{
+				Extent:            [4]float64{6.6, 51.2, 11.7, 54.0},
+				DuplicateDistance: 1,
+			}, nil)
+			return len(issues), err
+		},
+		expected: 0,
+	},
+}
+
+func TestStores(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		runStoreCases(t, storetest.NewStore(t))
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		if os.Getenv(integrationEnv) == "" {
+			t.Skipf("set %s to run the cases against a database", integrationEnv)
+		}
+		runStoreCases(t, newPostgres(t))
+	})
+}
+
+func runStoreCases(t *testing.T, s store.WaterRightStore) {
+	t.Helper()
+
+	for _, tc := range storeCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.run(t.Context(), s)
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Errorf("expected the error %v, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+// newPostgres connects to the database, applies the migrations and replaces
+// the water rights with the fixtures.
+func newPostgres(t *testing.T) store.WaterRightStore {
+	t.Helper()
+
+	if err := configuration.Default.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := configuration.Default.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Pool().Close)
+	if err := db.MigrateDatabase(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.LoadQueries(); err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures, err := os.ReadFile("testdata/fixtures.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Pool().Exec(t.Context(), string(fixtures)); err != nil {
+		t.Fatal(err)
+	}
+	if err := views.Refresh(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	return store.NewPostgres()
+}
@@ -0,0 +1,203 @@
+// Package storetest contains the fixtures shared by the tests of the
+// handlers. The handlers are executed against an in-memory store and the
+// authentication is replaced by setting the permissions of the caller
+// directly.
+package storetest
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/twpayne/go-geom"
+
+	jwtMiddleware "github.com/wisdom-oss/common-go/v3/middleware/gin/jwt"
+
+	"microservice/internal"
+	"microservice/internal/configuration"
+	"microservice/internal/redaction"
+	"microservice/internal/store"
+	v2 "microservice/types/v2"
+)
+
+// Main initializes the configuration and runs the tests of a package.
+func Main(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	if err := configuration.Default.Initialize(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// Ptr returns a pointer to the value.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// Point returns a point using the coordinate reference system of the stored
+// geometries.
+func Point(x, y float64) geom.T {
+	return geom.NewPointFlat(geom.XY, []float64{x, y}).SetSRID(25832) //nolint:mnd
+}
+
+// Square returns a square polygon with the lower left corner at the point.
+func Square(x, y, size float64) geom.T {
+	return geom.NewPolygonFlat(geom.XY, []float64{x, y, x + size, y, x + size, y + size, x, y + size, x, y},
+		[]int{10}).SetSRID(25832) //nolint:mnd
+}
+
+// Date returns the date as valid database value.
+func Date(year int, month time.Month, day int) pgtype.Date {
+	return pgtype.Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC), Valid: true}
+}
+
+// Freshness is reported by [Store] to keep the headers comparable.
+var Freshness = time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// Store reports a constant freshness.
+type Store struct {
+	*store.Memory
+}
+
+func (Store) Freshness(context.Context) (time.Time, error) {
+	return Freshness, nil
+}
+
+// NewStore returns a store containing a water right with a retired and a
+// current version and a second water right of another legal department.
+// The current version has a usage location containing personal data.
+func NewStore(t testing.TB) *store.Memory {
+	t.Helper()
+
+	m := store.NewMemory()
+
+	var retired v2.WaterRight
+	retired.Identifiers.Database = 1
+	retired.Identifiers.Cadenza = 4711
+	retired.Holder = Ptr("Erika Mustermann")
+	retired.LegalDepartments = []string{"E"}
+	m.AddWaterRight(retired, false)
+
+	var current v2.WaterRight
+	current.Identifiers.Database = 2
+	current.Identifiers.Cadenza = 4711
+	current.Holder = Ptr("Erika Mustermann")
+	current.LegalDepartments = []string{"E"}
+	m.AddWaterRight(current, true)
+
+	var other v2.WaterRight
+	other.Identifiers.Database = 3
+	other.Identifiers.Cadenza = 4712
+	other.LegalDepartments = []string{"B"}
+	m.AddWaterRight(other, true)
+
+	locations := []v2.UsageLocation{
+		{
+			ID: 10, WaterRightID: 2, Active: Ptr(true), Real: Ptr(true), LegalDepartment: Ptr("E"),
+			MunicipalArea: &v2.NumericKeyedValue{Key: Ptr(int64(3459040)), Value: Ptr("Hasbergen")},
+			Plot:          Ptr("Flur 3, Flurstück 12"),
+			LandRecord:    &v2.LandRecord{District: Ptr("Gaste"), Fallback: Ptr("Gemarkung Hasbergen")},
+			Geometry:      Point(426780, 5790250),
+		},
+		{
+			ID: 11, WaterRightID: 2, Active: Ptr(false), Real: Ptr(false), LegalDepartment: Ptr("E"),
+			MunicipalArea: &v2.NumericKeyedValue{Key: Ptr(int64(3459040)), Value: Ptr("Hasbergen")},
+			Geometry:      Point(427010, 5790480),
+		},
+		{
+			ID: 12, WaterRightID: 3, Active: Ptr(true), Real: Ptr(true), LegalDepartment: Ptr("B"),
+			MunicipalArea: &v2.NumericKeyedValue{Key: Ptr(int64(3404000)), Value: Ptr("Osnabrück, Stadt")},
+			Geometry:      Point(435400, 5792100),
+		},
+	}
+	locations[0].Rates.Withdrawal = []v2.Rate{
+		{Value: Ptr(120000.0), Unit: Ptr("m³"), Per: pgtype.Interval{Months: 12, Valid: true}},
+	}
+	locations[2].Rates.Withdrawal = []v2.Rate{
+		{Value: Ptr(250.0), Unit: Ptr("m³"), Per: pgtype.Interval{Days: 1, Valid: true}},
+	}
+
+	for _, location := range locations {
+		if err := m.AddUsageLocation(location); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+// PersonalValues are the personal data of the current version of the water
+// right 4711 and its usage locations in the store returned by [NewStore].
+var PersonalValues = []string{"Erika Mustermann", "Flur 3", "Gaste", "Gemarkung Hasbergen"}
+
+// Caller configures the permissions of the requests.
+type Caller struct {
+	Administrator bool
+	Permissions   []string
+	Groups        []any // values of the claim used by the access policy
+}
+
+// The callers used by the tests.
+var (
+	Administrator = Caller{Administrator: true}
+	DepartmentB   = Caller{Groups: []any{"department-b"}}
+	Anonymous     = Caller{}
+	PersonalData  = Caller{Permissions: []string{redaction.ScopePersonalData}}
+)
+
+// EnableAccessPolicy enables the access policy which permits the members of
+// the department-b group to read the water rights of the legal department B.
+func EnableAccessPolicy(t testing.TB) {
+	t.Helper()
+
+	c := configuration.Default.Viper()
+	c.Set(configuration.ConfigurationKey_AccessPolicyEnabled, true)
+	c.Set(configuration.ConfigurationKey_AccessPolicyClaim, "groups")
+	c.Set(configuration.ConfigurationKey_AccessPolicyRules, map[string]any{
+		"department-b": map[string]any{"legal-departments": []string{"B"}},
+	})
+	t.Cleanup(func() {
+		c.Set(configuration.ConfigurationKey_AccessPolicyEnabled, false)
+	})
+}
+
+// EnableRedaction enables the redaction of the personal data using the
+// supplied mode.
+func EnableRedaction(t testing.TB, mode string) {
+	t.Helper()
+
+	c := configuration.Default.Viper()
+	c.Set(configuration.ConfigurationKey_RedactionEnabled, true)
+	c.Set(configuration.ConfigurationKey_RedactionMode, mode)
+	c.Set(configuration.ConfigurationKey_RedactionPseudonymKey, "secret")
+	t.Cleanup(func() {
+		c.Set(configuration.ConfigurationKey_RedactionEnabled, nil)
+		c.Set(configuration.ConfigurationKey_RedactionMode, redaction.ModeNull)
+	})
+}
+
+// Serve executes the request as the caller against the handlers added by
+// register.
+func Serve(as Caller, register func(r gin.IRoutes), method, target, body string) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(jwtMiddleware.KeyAdministrator, as.Administrator)
+		c.Set(jwtMiddleware.KeyTokenPermissions, as.Permissions)
+		c.Set(internal.KeyTokenClaims, map[string]any{"groups": as.Groups})
+		c.Next()
+	})
+	register(r)
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, httptest.NewRequest(method, target, reader))
+	return res
+}
@@ -0,0 +1,26 @@
+-- The fixtures contain the water rights and usage locations of
+-- storetest.NewStore. The existing water rights are removed.
+TRUNCATE water_rights.usage_locations, water_rights.current_rights, water_rights.rights,
+    water_rights.change_events, water_rights.reference_layers RESTART IDENTITY CASCADE;
+
+INSERT INTO water_rights.rights (id, water_right_number, holder, legal_departments)
+VALUES (1, 4711, 'Erika Mustermann', '{E}'),
+       (2, 4711, 'Erika Mustermann', '{E}'),
+       (3, 4712, NULL, '{B}');
+
+INSERT INTO water_rights.current_rights (water_right_number, internal_id)
+VALUES (4711, 2),
+       (4712, 3);
+
+INSERT INTO water_rights.usage_locations (id, water_right, active, real, legal_department, municipal_area, plot,
+                                          land_record, withdrawal_rates, location)
+VALUES (10, 2, TRUE, TRUE, 'E', ROW (3459040, 'Hasbergen')::water_rights.numeric_keyed_value,
+        'Flur 3, Flurstück 12', ROW ('Gaste', NULL, 'Gemarkung Hasbergen')::water_rights.land_record,
+        ARRAY [ROW (120000, 'm³', '12 months')::water_rights.rate],
+        ST_SetSRID(ST_MakePoint(426780, 5790250), 25832)),
+       (11, 2, FALSE, FALSE, 'E', ROW (3459040, 'Hasbergen')::water_rights.numeric_keyed_value,
+        NULL, NULL, NULL,
+        ST_SetSRID(ST_MakePoint(427010, 5790480), 25832)),
+       (12, 3, TRUE, TRUE, 'B', ROW (3404000, 'Osnabrück, Stadt')::water_rights.numeric_keyed_value,
+        NULL, NULL, ARRAY [ROW (250, 'm³', '1 day')::water_rights.rate],
+        ST_SetSRID(ST_MakePoint(435400, 5792100), 25832));
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"microservice/internal"
+	"microservice/internal/configuration"
+)
+
+// The supported exporters for the spans.
+const (
+	ExporterOtlpGrpc = "otlp-grpc"
+	ExporterOtlpHttp = "otlp-http"
+)
+
+// instrumentationName identifies the spans created by the service itself.
+const instrumentationName = "microservice"
+
+// Tracer is used to create the spans in the service.
+// It uses the globally configured tracer provider and therefore creates no-op
+// spans until [Setup] has been called.
+var Tracer trace.Tracer = otel.Tracer(instrumentationName)
+
+// Setup configures the global tracer provider and the propagation of the
+// trace context using the configuration.
+// The returned function flushes the remaining spans and needs to be called
+// during the shutdown of the service.
+//
+// The exporters additionally respect the OTEL_EXPORTER_OTLP_* environment
+// variables for settings that are not part of the configuration (e.g.,
+// headers or certificates).
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	c := configuration.Default.Viper()
+	if !c.GetBool(configuration.ConfigurationKey_TracingEnabled) {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	endpoint := c.GetString(configuration.ConfigurationKey_TracingEndpoint)
+	insecure := c.GetBool(configuration.ConfigurationKey_TracingInsecure)
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch exporterType := c.GetString(configuration.ConfigurationKey_TracingExporter); exporterType {
+	case ExporterOtlpGrpc:
+		var opts []otlptracegrpc.Option
+		if endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(endpoint))
+		}
+		if insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	case ExporterOtlpHttp:
+		var opts []otlptracehttp.Option
+		if endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		if insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported span exporter: %s", exporterType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to create span exporter: %w", err)
+	}
+
+	provider := NewProvider(exporter, sdktrace.TraceIDRatioBased(c.GetFloat64(configuration.ConfigurationKey_TracingSampleRatio)))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewProvider creates a tracer provider that exports the spans using the
+// supplied exporter.
+// The sampler is only applied to traces started by the service which lets
+// the service follow the sampling decision of its callers.
+//
+// Tests may use an in-memory exporter (e.g., tracetest.InMemoryExporter) to
+// inspect the created spans.
+func NewProvider(exporter sdktrace.SpanExporter, sampler sdktrace.Sampler) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(internal.ServiceName),
+		)),
+	)
+}
@@ -0,0 +1,153 @@
+package views
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"microservice/internal/db"
+)
+
+// The materialized views holding the precomputed withdrawals.
+const (
+	UsageLocationWithdrawals = "usage_location_withdrawals"
+	MunicipalWithdrawals     = "municipal_withdrawals"
+)
+
+// refreshQueries contains the queries refreshing the materialized views in
+// the order the views depend on each other.
+var refreshQueries = []struct {
+	view  string
+	query string
+}{
+	{UsageLocationWithdrawals, "refresh-usage-location-withdrawals"},
+	{MunicipalWithdrawals, "refresh-municipal-withdrawals"},
+}
+
+// settleDelay is the time waited after a change notification before the
+// views are refreshed. Imports may be split into multiple transactions which
+// should be covered by a single refresh.
+const settleDelay = 2 * time.Second
+
+// Default is the refresher used by the service.
+var Default = NewRefresher()
+
+// Refresher refreshes the materialized views after the underlying data has
+// been changed.
+type Refresher struct {
+	trigger chan struct{}
+
+	lock  sync.Mutex
+	hooks []func() // called after the views have been refreshed
+}
+
+// NewRefresher creates a new refresher.
+func NewRefresher() *Refresher {
+	return &Refresher{
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+// Trigger requests a refresh of the views.
+// Multiple requests that are made before the refresh started are collapsed
+// into a single refresh.
+func (r *Refresher) Trigger() {
+	select {
+	case r.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// OnRefresh registers a function that is called after the views have been
+// refreshed.
+func (r *Refresher) OnRefresh(fn func()) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.hooks = append(r.hooks, fn)
+}
+
+// Run refreshes the views whenever a refresh has been triggered until the
+// context is canceled.
+func (r *Refresher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.trigger:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(settleDelay):
+		}
+
+		// the refresh covers all triggers received while settling
+		select {
+		case <-r.trigger:
+		default:
+		}
+
+		start := time.Now()
+		if err := Refresh(ctx); err != nil {
+			slog.Error("unable to refresh materialized views", "error", err)
+			continue
+		}
+		slog.Debug("refreshed materialized views", "duration", time.Since(start))
+
+		r.lock.Lock()
+		hooks := slices.Clone(r.hooks)
+		r.lock.Unlock()
+
+		for _, fn := range hooks {
+			fn()
+		}
+	}
+}
+
+// Refresh refreshes all materialized views and records the time of the
+// refresh.
+// The views are refreshed concurrently which allows reading the previous
+// contents of the views while they are refreshed.
+func Refresh(ctx context.Context) error {
+	recordQuery, err := db.Queries.Raw("record-view-refresh")
+	if err != nil {
+		return err
+	}
+
+	return pgx.BeginFunc(ctx, db.Pool(), func(tx pgx.Tx) error {
+		for _, refresh := range refreshQueries {
+			query, err := db.Queries.Raw(refresh.query)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, query); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, recordQuery, refresh.view); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Freshness returns the time of the oldest refresh of the supplied views.
+// The time is the start of the transaction that refreshed the views and
+// therefore all changes committed before are contained in the views.
+func Freshness(ctx context.Context, views ...string) (time.Time, error) {
+	query, err := db.Queries.Raw("get-view-freshness")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var freshness time.Time
+	if err := db.Pool().QueryRow(ctx, query, views).Scan(&freshness); err != nil {
+		return time.Time{}, err
+	}
+	return freshness, nil
+}
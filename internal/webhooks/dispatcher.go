@@ -0,0 +1,253 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"microservice/internal"
+	"microservice/internal/configuration"
+	v2 "microservice/types/v2"
+)
+
+// batchSize limits the number of change events that are delivered to a
+// single subscriber before its claim is released.
+const batchSize = 100
+
+// maximalBackoff limits the time waited between two attempts.
+const maximalBackoff = time.Hour
+
+// cleanupInterval is the time between two deletions of the expired change
+// events.
+const cleanupInterval = time.Hour
+
+// Dispatcher periodically reads the change events recorded by the database
+// triggers and delivers them to the subscribers.
+// Every subscription is claimed before its events are delivered, which
+// allows running multiple service instances without delivering an event more
+// than once.
+// The events are not filtered by the access policy since only administrators
+// may subscribe and the access policy does not restrict them.
+type Dispatcher struct {
+	// Store contains the subscriptions and the delivery log
+	Store Store
+
+	// Client is used to deliver the events
+	Client *http.Client
+
+	// PollInterval is the time between two dispatch runs
+	PollInterval time.Duration
+
+	// MaxAttempts limits how often the delivery of a single event is tried
+	MaxAttempts int
+
+	// Backoff is the time waited after the first failed attempt. It is doubled
+	// after each following failed attempt
+	Backoff time.Duration
+
+	// Retention is the time the change events are kept after they have been
+	// handled for every subscription
+	Retention time.Duration
+}
+
+// NewDispatcher creates a new dispatcher which has been configured using the
+// default configuration and stores the subscriptions in the database.
+func NewDispatcher() *Dispatcher {
+	c := configuration.Default.Viper()
+	return &Dispatcher{
+		Store:        postgresStore{},
+		Client:       &http.Client{Timeout: c.GetDuration(configuration.ConfigurationKey_WebhooksTimeout)},
+		PollInterval: c.GetDuration(configuration.ConfigurationKey_WebhooksPollInterval),
+		MaxAttempts:  c.GetInt(configuration.ConfigurationKey_WebhooksMaxAttempts),
+		Backoff:      c.GetDuration(configuration.ConfigurationKey_WebhooksBackoff),
+		Retention:    c.GetDuration(configuration.ConfigurationKey_WebhooksRetention),
+	}
+}
+
+// Run claims the subscriptions with pending change events until the context
+// is canceled. The expired change events are deleted periodically.
+// Each claimed subscription is dispatched on its own, so a subscriber that
+// is waiting for a retry does not delay the deliveries to the other
+// subscribers.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	var lastCleanup time.Time
+	for {
+		if time.Since(lastCleanup) >= cleanupInterval {
+			lastCleanup = time.Now()
+			d.cleanup(ctx)
+		}
+
+		subscriptions, err := d.Store.Claim(ctx, d.lease())
+		if err != nil && ctx.Err() == nil {
+			slog.Error("unable to claim webhook subscriptions", "error", err)
+		}
+
+		for _, subscription := range subscriptions {
+			running.Add(1)
+			go func() {
+				defer running.Done()
+				if err := d.Dispatch(ctx, subscription); err != nil && ctx.Err() == nil {
+					slog.Error("unable to dispatch webhook events", "subscription", subscription.ID, "error", err)
+				}
+			}()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch delivers the pending change events to a claimed subscriber in the
+// order they occurred and releases the claim afterward.
+// The claim is extended after every event to cover the delivery of the next
+// event.
+func (d *Dispatcher) Dispatch(ctx context.Context, subscription v2.WebhookSubscription) error {
+	defer func() {
+		if err := d.Store.Release(context.WithoutCancel(ctx), subscription.ID); err != nil {
+			slog.Error("unable to release webhook subscription", "subscription", subscription.ID, "error", err)
+		}
+	}()
+
+	events, err := d.Store.PendingEvents(ctx, subscription, batchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if err := d.deliver(ctx, subscription, event); err != nil {
+			return err
+		}
+
+		if err := d.Store.Advance(ctx, subscription.ID, event.ID, d.lease()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanup deletes the change events that exceeded the retention.
+func (d *Dispatcher) cleanup(ctx context.Context) {
+	deleted, err := d.Store.DeleteExpiredEvents(ctx, d.Retention)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("unable to delete expired change events", "error", err)
+		}
+		return
+	}
+	if deleted > 0 {
+		slog.Info("deleted expired change events", "events", deleted)
+	}
+}
+
+// lease returns how long a subscription is claimed for the delivery of a
+// single event. It covers every attempt and the backoff between them.
+func (d *Dispatcher) lease() time.Duration {
+	lease := d.PollInterval + time.Duration(d.MaxAttempts)*d.Client.Timeout
+	backoff := d.Backoff
+	for range d.MaxAttempts - 1 {
+		lease += backoff
+		backoff = min(2*backoff, maximalBackoff)
+	}
+	return lease
+}
+
+// deliver sends the event to the subscriber and retries the delivery with an
+// exponential backoff until it succeeds or the maximum number of attempts is
+// reached.
+// Each attempt is recorded in the delivery log. The last attempt of an event
+// that could not be delivered is marked as failed.
+// An error is only returned if the context has been canceled or the delivery
+// log could not be written.
+func (d *Dispatcher) deliver(ctx context.Context, subscription v2.WebhookSubscription, event v2.ChangeEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	backoff := d.Backoff
+	for attempt := 1; attempt <= d.MaxAttempts; attempt++ {
+		start := time.Now()
+		statusCode, deliveryErr := d.send(ctx, subscription, event, body)
+		duration := time.Since(start)
+
+		delivery := v2.WebhookDelivery{
+			Subscription: subscription.ID,
+			Event:        event.ID,
+			EventType:    event.Type,
+			Attempt:      attempt,
+			StatusCode:   statusCode,
+			Duration:     duration.Milliseconds(),
+			Failed:       deliveryErr != nil && attempt == d.MaxAttempts,
+		}
+		if deliveryErr != nil {
+			msg := deliveryErr.Error()
+			delivery.Error = &msg
+		}
+
+		if err := d.Store.RecordDelivery(ctx, delivery); err != nil {
+			return err
+		}
+
+		if deliveryErr == nil {
+			return nil
+		}
+
+		if delivery.Failed {
+			slog.Error("webhook delivery failed permanently",
+				"subscription", subscription.ID, "event", event.ID, "attempts", attempt, "error", deliveryErr)
+			break
+		}
+
+		slog.Warn("webhook delivery failed",
+			"subscription", subscription.ID, "event", event.ID, "attempt", attempt, "error", deliveryErr)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maximalBackoff)
+	}
+	return nil
+}
+
+// send executes a single delivery attempt and returns the status code
+// returned by the subscriber if there was a response.
+func (d *Dispatcher) send(ctx context.Context, subscription v2.WebhookSubscription, event v2.ChangeEvent, body []byte) (*int, error) { //nolint:lll
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", internal.ServiceName)
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderEventID, strconv.FormatInt(event.ID, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, time.Now(), body))
+
+	res, err := d.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return &res.StatusCode, fmt.Errorf("subscriber responded with status %d", res.StatusCode)
+	}
+	return &res.StatusCode, nil
+}
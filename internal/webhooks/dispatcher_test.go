@@ -0,0 +1,287 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	v2 "microservice/types/v2"
+)
+
+const testSecret = "test-secret"
+
+// testStore keeps the subscriptions and the delivery log in memory.
+type testStore struct {
+	lock          sync.Mutex
+	subscriptions []v2.WebhookSubscription
+	claimed       map[int64]bool
+	events        []v2.ChangeEvent
+	deliveries    []v2.WebhookDelivery
+	retentions    []time.Duration // of the requested deletions
+}
+
+func (s *testStore) Claim(_ context.Context, _ time.Duration) ([]v2.WebhookSubscription, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var claimed []v2.WebhookSubscription
+	for _, subscription := range s.subscriptions {
+		if s.claimed[subscription.ID] {
+			continue
+		}
+		s.claimed[subscription.ID] = true
+		claimed = append(claimed, subscription)
+	}
+	return claimed, nil
+}
+
+func (s *testStore) PendingEvents(_ context.Context, subscription v2.WebhookSubscription, limit int) ([]v2.ChangeEvent, error) { //nolint:lll
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var events []v2.ChangeEvent
+	for _, event := range s.events {
+		if event.ID > subscription.LastEvent && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *testStore) RecordDelivery(_ context.Context, delivery v2.WebhookDelivery) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func (s *testStore) Advance(_ context.Context, subscription, event int64, _ time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for idx := range s.subscriptions {
+		if s.subscriptions[idx].ID == subscription {
+			s.subscriptions[idx].LastEvent = event
+		}
+	}
+	return nil
+}
+
+func (s *testStore) Release(_ context.Context, subscription int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.claimed, subscription)
+	return nil
+}
+
+func (s *testStore) DeleteExpiredEvents(_ context.Context, retention time.Duration) (int64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.retentions = append(s.retentions, retention)
+	return 0, nil
+}
+
+func (s *testStore) subscription(id int64) v2.WebhookSubscription {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, subscription := range s.subscriptions {
+		if subscription.ID == id {
+			return subscription
+		}
+	}
+	return v2.WebhookSubscription{}
+}
+
+func newTestStore(urls ...string) *testStore {
+	store := &testStore{
+		claimed: make(map[int64]bool),
+		events: []v2.ChangeEvent{
+			{ID: 1, Type: "water-right.created"},
+			{ID: 2, Type: "water-right.updated"},
+		},
+	}
+	for idx, url := range urls {
+		store.subscriptions = append(store.subscriptions, v2.WebhookSubscription{
+			ID:     int64(idx + 1),
+			URL:    url,
+			Secret: testSecret,
+		})
+	}
+	return store
+}
+
+func newTestDispatcher(store Store, backoff time.Duration) *Dispatcher {
+	return &Dispatcher{
+		Store:        store,
+		Client:       &http.Client{Timeout: time.Second},
+		PollInterval: 10 * time.Millisecond,
+		MaxAttempts:  3,
+		Backoff:      backoff,
+		Retention:    time.Hour,
+	}
+}
+
+func TestDispatchSignsDeliveries(t *testing.T) {
+	var received []int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !Verify(testSecret, r.Header.Get(HeaderSignature), body, DefaultTolerance) {
+			t.Errorf("invalid signature %q", r.Header.Get(HeaderSignature))
+		}
+
+		var event v2.ChangeEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+		if r.Header.Get(HeaderEvent) != event.Type {
+			t.Errorf("event header %q does not match event type %q", r.Header.Get(HeaderEvent), event.Type)
+		}
+		if r.Header.Get(HeaderEventID) != strconv.FormatInt(event.ID, 10) {
+			t.Errorf("event id header %q does not match event %d", r.Header.Get(HeaderEventID), event.ID)
+		}
+		received = append(received, event.ID)
+	}))
+	defer server.Close()
+
+	store := newTestStore(server.URL)
+	dispatcher := newTestDispatcher(store, time.Millisecond)
+	if err := dispatcher.Dispatch(t.Context(), store.subscription(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received) != 2 || received[0] != 1 || received[1] != 2 {
+		t.Errorf("expected events [1 2] to be delivered in order, got %v", received)
+	}
+	if lastEvent := store.subscription(1).LastEvent; lastEvent != 2 {
+		t.Errorf("expected the cursor to point to event 2, got %d", lastEvent)
+	}
+}
+
+func TestDispatchRetriesFailedDeliveries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		// the first event succeeds on the second attempt
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	store := newTestStore(server.URL)
+	store.events = store.events[:1]
+	dispatcher := newTestDispatcher(store, time.Millisecond)
+	if err := dispatcher.Dispatch(t.Context(), store.subscription(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(store.deliveries) != 2 {
+		t.Fatalf("expected 2 recorded attempts, got %d", len(store.deliveries))
+	}
+	first, second := store.deliveries[0], store.deliveries[1]
+	if first.Attempt != 1 || first.Error == nil || first.StatusCode == nil ||
+		*first.StatusCode != http.StatusServiceUnavailable || first.Failed {
+		t.Errorf("unexpected first attempt %+v", first)
+	}
+	if second.Attempt != 2 || second.Error != nil || second.Failed {
+		t.Errorf("unexpected second attempt %+v", second)
+	}
+	if lastEvent := store.subscription(1).LastEvent; lastEvent != 1 {
+		t.Errorf("expected the cursor to point to event 1, got %d", lastEvent)
+	}
+}
+
+func TestDispatchMarksUndeliverableEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	store := newTestStore(server.URL)
+	dispatcher := newTestDispatcher(store, time.Millisecond)
+	if err := dispatcher.Dispatch(t.Context(), store.subscription(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(store.deliveries) != 2*dispatcher.MaxAttempts {
+		t.Fatalf("expected %d recorded attempts, got %d", 2*dispatcher.MaxAttempts, len(store.deliveries))
+	}
+	for _, delivery := range store.deliveries {
+		if delivery.Failed != (delivery.Attempt == dispatcher.MaxAttempts) {
+			t.Errorf("only the last attempt should be marked as failed: %+v", delivery)
+		}
+	}
+	if lastEvent := store.subscription(1).LastEvent; lastEvent != 2 {
+		t.Errorf("expected the cursor to move past the failed events, got %d", lastEvent)
+	}
+	if store.claimed[1] {
+		t.Error("expected the subscription to be released")
+	}
+}
+
+func TestRunDoesNotBlockOnFailingSubscribers(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	delivered := make(chan struct{}, 2)
+	healthy := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		delivered <- struct{}{}
+	}))
+	defer healthy.Close()
+
+	store := newTestStore(failing.URL, healthy.URL)
+	// the failing subscriber waits for an hour before retrying
+	dispatcher := newTestDispatcher(store, time.Hour)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	stopped := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(stopped)
+	}()
+
+	for range 2 {
+		select {
+		case <-delivered:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the healthy subscriber did not receive the events")
+		}
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the dispatcher did not stop after the context has been canceled")
+	}
+}
+
+func TestRunDeletesExpiredEvents(t *testing.T) {
+	store := newTestStore()
+	dispatcher := newTestDispatcher(store, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	stopped := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(stopped)
+	}()
+
+	// the dispatcher runs multiple times within the cleanup interval
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-stopped
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	if len(store.retentions) != 1 || store.retentions[0] != time.Hour {
+		t.Errorf("expected the expired events to be deleted once using the retention, got %v", store.retentions)
+	}
+}
@@ -0,0 +1,74 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The headers set on every delivery.
+const (
+	HeaderEvent     = "X-Water-Rights-Event"
+	HeaderEventID   = "X-Water-Rights-Event-ID"
+	HeaderSignature = "X-Water-Rights-Signature"
+)
+
+const signatureFormat = `t=%d,v1=%s`
+
+// DefaultTolerance is the time a signature is accepted by [Verify] before or
+// after it has been created if the subscriber does not use another
+// tolerance.
+const DefaultTolerance = 5 * time.Minute
+
+// Sign generates the value of the signature header for the supplied body.
+// The signature is a HMAC-SHA256 over the unix timestamp and the body joined
+// by a dot. As the timestamp is signed, subscribers can reject replayed
+// deliveries by checking the age of the timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	return fmt.Sprintf(signatureFormat, timestamp.Unix(), hex.EncodeToString(mac(secret, timestamp.Unix(), body)))
+}
+
+// Verify checks if the signature header value matches the supplied body and
+// secret and if the signature has been created within the tolerance around
+// the current time. Older signatures are rejected as the delivery may have
+// been replayed.
+// It is used by subscribers written in Go and when testing the deliveries.
+func Verify(secret string, header string, body []byte, tolerance time.Duration) bool {
+	var timestamp int64
+	var signature []byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			return false
+		}
+		var err error
+		switch key {
+		case "t":
+			timestamp, err = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			signature, err = hex.DecodeString(value)
+		}
+		if err != nil {
+			return false
+		}
+	}
+
+	if signature == nil || !hmac.Equal(signature, mac(secret, timestamp, body)) {
+		return false
+	}
+
+	age := time.Since(time.Unix(timestamp, 0))
+	return age <= tolerance && age >= -tolerance
+}
+
+func mac(secret string, timestamp int64, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,37 @@
+package webhooks
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":1,"type":"water-right.created"}`)
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		header   string
+		body     []byte
+		expected bool
+	}{
+		{name: "valid", header: Sign(testSecret, now, body), body: body, expected: true},
+		{name: "within tolerance", header: Sign(testSecret, now.Add(-4*time.Minute), body), body: body, expected: true},
+		{name: "replayed", header: Sign(testSecret, now.Add(-10*time.Minute), body), body: body},
+		{name: "future timestamp", header: Sign(testSecret, now.Add(10*time.Minute), body), body: body},
+		{name: "other secret", header: Sign("other-secret", now, body), body: body},
+		{name: "changed body", header: Sign(testSecret, now, body), body: []byte(`{"id":2}`)},
+		{name: "changed timestamp", header: strings.Replace(Sign(testSecret, now, body), "t=", "t=1", 1), body: body},
+		{name: "missing signature", header: "t=" + now.Format("20060102"), body: body},
+		{name: "malformed", header: "signature", body: body},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if valid := Verify(testSecret, tt.header, tt.body, DefaultTolerance); valid != tt.expected {
+				t.Errorf("expected %q to be valid: %t, got %t", tt.header, tt.expected, valid)
+			}
+		})
+	}
+}
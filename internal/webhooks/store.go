@@ -0,0 +1,113 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+
+	"microservice/internal/db"
+	v2 "microservice/types/v2"
+)
+
+// Store persists the subscriptions, their position in the change events and
+// the delivery log.
+type Store interface {
+	// Claim returns the subscriptions that are not handled by another
+	// dispatcher and claims them for the supplied duration.
+	Claim(ctx context.Context, lease time.Duration) ([]v2.WebhookSubscription, error)
+
+	// PendingEvents returns up to limit change events the subscriber has not
+	// received yet in the order they are delivered in.
+	PendingEvents(ctx context.Context, subscription v2.WebhookSubscription, limit int) ([]v2.ChangeEvent, error)
+
+	// RecordDelivery adds a delivery attempt to the delivery log.
+	RecordDelivery(ctx context.Context, delivery v2.WebhookDelivery) error
+
+	// Advance marks the event as handled for the subscription and extends
+	// the claim on the subscription by the supplied duration.
+	Advance(ctx context.Context, subscription, event int64, lease time.Duration) error
+
+	// Release allows other dispatchers to claim the subscription again.
+	Release(ctx context.Context, subscription int64) error
+
+	// DeleteExpiredEvents deletes the change events that are older than the
+	// retention and have been handled for every subscription. It returns the
+	// number of deleted events.
+	DeleteExpiredEvents(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// postgresStore stores the subscriptions in the database used by the
+// service.
+type postgresStore struct{}
+
+func (postgresStore) Claim(ctx context.Context, lease time.Duration) ([]v2.WebhookSubscription, error) {
+	query, err := db.Queries.Raw("claim-webhook-subscriptions")
+	if err != nil {
+		return nil, err
+	}
+
+	var subscriptions []v2.WebhookSubscription
+	if err := pgxscan.Select(ctx, db.Pool(), &subscriptions, query, lease); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (postgresStore) PendingEvents(ctx context.Context, subscription v2.WebhookSubscription, limit int) ([]v2.ChangeEvent, error) { //nolint:lll
+	query, err := db.Queries.Raw("get-pending-change-events")
+	if err != nil {
+		return nil, err
+	}
+
+	var events []v2.ChangeEvent
+	err = pgxscan.Select(ctx, db.Pool(), &events, query, subscription.LastEvent, subscription.Events, limit)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (postgresStore) RecordDelivery(ctx context.Context, delivery v2.WebhookDelivery) error {
+	query, err := db.Queries.Raw("record-webhook-delivery")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Pool().Exec(ctx, query, delivery.Subscription, delivery.Event, delivery.Attempt,
+		delivery.StatusCode, delivery.Error, delivery.Duration, delivery.Failed)
+	return err
+}
+
+func (postgresStore) Advance(ctx context.Context, subscription, event int64, lease time.Duration) error {
+	query, err := db.Queries.Raw("advance-webhook-subscription")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Pool().Exec(ctx, query, subscription, event, lease)
+	return err
+}
+
+func (postgresStore) DeleteExpiredEvents(ctx context.Context, retention time.Duration) (int64, error) {
+	query, err := db.Queries.Raw("delete-expired-change-events")
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := db.Pool().Exec(ctx, query, retention)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (postgresStore) Release(ctx context.Context, subscription int64) error {
+	query, err := db.Queries.Raw("release-webhook-subscription")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Pool().Exec(ctx, query, subscription)
+	return err
+}
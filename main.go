@@ -11,8 +11,15 @@ import (
 	"syscall"
 	"time"
 
+	"microservice/internal/cache"
 	"microservice/internal/configuration"
 	"microservice/internal/db"
+	"microservice/internal/events"
+	"microservice/internal/logging"
+	"microservice/internal/store"
+	"microservice/internal/tracing"
+	"microservice/internal/views"
+	"microservice/internal/webhooks"
 	"microservice/router"
 )
 
@@ -23,6 +30,15 @@ var serverShutdownTimeout = 20 * time.Second
 // microservice.
 func main() {
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case healthcheckCommand:
+			os.Exit(healthcheck(os.Args[2:]))
+		case configCommand:
+			os.Exit(configurationCommand(os.Args[2:]))
+		}
+	}
+
 	if err := configuration.Default.Initialize(); err != nil {
 		slog.Error("unable to initialize configuration", "error", err)
 		os.Exit(1)
@@ -33,29 +49,34 @@ func main() {
 		os.Exit(1)
 	}
 
-	// setting up the database connection
-	err := db.Connect()
-	if err != nil {
-		slog.Error("unable to connect to the database", "error", err)
+	var validationErrs configuration.ValidationErrors
+	if err := configuration.Default.Validate(); errors.As(err, &validationErrs) {
+		for _, err := range validationErrs {
+			slog.Error("invalid configuration", "error", err)
+		}
 		os.Exit(1)
 	}
 
-	// running database migrations stored in resources/migrations
-	err = db.MigrateDatabase()
-	if err != nil {
-		slog.Error("failed to execute database migrations", "error", err)
+	if err := logging.Setup(); err != nil {
+		slog.Error("unable to set up logging", "error", err)
 		os.Exit(1)
 	}
 
-	// setting up the database connection
-	err = db.LoadQueries()
+	// setting up the tracing before connecting to the database to allow the
+	// queries to be traced
+	shutdownTracing, err := tracing.Setup(context.Background())
 	if err != nil {
-		slog.Error("unable to load the database queries", "error", err)
+		slog.Error("unable to set up tracing", "error", err)
 		os.Exit(1)
 	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("unable to flush remaining spans", "error", err)
+		}
+	}()
 
 	// configure your router
-	r, err := router.Configure()
+	r, err := router.Configure(store.NewPostgres())
 	if err != nil {
 		slog.Error("unable to create router", "error", err)
 		os.Exit(1)
@@ -70,19 +91,71 @@ func main() {
 		ReadHeaderTimeout: headerReadTimeout,
 	}
 
-	// Start the server and log errors that happen while running it
+	// Start the server and log errors that happen while running it.
+	// The server is started before the database is initialized to allow the
+	// health checks to report the progress of the startup
 	go func() {
 		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
 			slog.Error("unable to start http server", "error", err)
 		}
 	}()
 
+	// setting up the database connection
+	err = db.Connect()
+	if err != nil {
+		slog.Error("unable to connect to the database", "error", err)
+		os.Exit(1)
+	}
+
+	// running database migrations stored in resources/migrations
+	err = db.MigrateDatabase()
+	if err != nil {
+		slog.Error("failed to execute database migrations", "error", err)
+		os.Exit(1)
+	}
+
+	// setting up the database connection
+	err = db.LoadQueries()
+	if err != nil {
+		slog.Error("unable to load the database queries", "error", err)
+		os.Exit(1)
+	}
+
+	// the background context is used by all workers that run alongside the
+	// http server and is canceled once the service shuts down
+	backgroundCtx, cancelBackground := context.WithCancel(context.Background())
+	defer cancelBackground()
+
+	// applying changes to the configuration while running
+	go configuration.Default.Watch(backgroundCtx)
+
+	// delivering the recorded change events to the webhook subscribers
+	go webhooks.NewDispatcher().Run(backgroundCtx)
+
+	// the cached responses are discarded whenever the data or the
+	// configuration changes. the etags of the responses are derived from
+	// the last published change event
+	events.Default.OnNotification(cache.Default.Invalidate)
+	events.Default.OnPublish(cache.Default.SetDataVersion)
+	configuration.Default.OnReload(cache.Default.Invalidate)
+
+	// refreshing the precomputed withdrawals after the data changed. the
+	// cached responses may contain the previous withdrawals and are
+	// discarded once more after the refresh
+	events.Default.OnNotification(views.Default.Trigger)
+	views.Default.OnRefresh(cache.Default.Invalidate)
+	go views.Default.Run(backgroundCtx)
+
+	// publishing the change events to the clients of the event stream
+	go events.Default.Run(backgroundCtx)
+
 	// Set up some the signal handling to allow the server to shut down gracefully
 	shutdownSignal := make(chan os.Signal, 1)
 	signal.Notify(shutdownSignal, syscall.SIGINT, syscall.SIGTERM)
 
 	// Block further code execution until the shutdown signal was received
 	<-shutdownSignal
+	cancelBackground()
 
 	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
 	defer cancel()
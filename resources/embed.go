@@ -4,3 +4,8 @@ import "embed"
 
 //go:embed *.sql
 var QueryFiles embed.FS
+
+// Specifications contains the OpenAPI specifications of the api versions.
+//
+//go:embed *.openapi.yaml
+var Specifications embed.FS
@@ -0,0 +1,29 @@
+-- name: get-latest-change-event-id
+SELECT coalesce((SELECT id
+                 FROM water_rights.change_events
+                 WHERE transaction < pg_snapshot_xmin(pg_current_snapshot())
+                 ORDER BY transaction DESC, id DESC
+                 LIMIT 1), 0);
+
+-- name: get-change-event
+SELECT *
+FROM water_rights.change_events
+WHERE id = $1;
+
+-- name: get-change-events-since
+-- the legal departments and the water authority are returned to apply the
+-- access policy of the clients. events of deleted usage locations use the
+-- legal departments of their water right
+SELECT e.*,
+       CASE
+           WHEN l.id IS NOT NULL THEN ARRAY [l.legal_department::text]
+           ELSE r.legal_departments::text[]
+           END AS legal_departments,
+       r.water_authority
+FROM water_rights.change_events e
+    LEFT JOIN water_rights.rights r ON r.id = e.water_right
+    LEFT JOIN water_rights.usage_locations l ON l.id = e.usage_location
+WHERE (e.transaction, e.id) > (coalesce((SELECT transaction FROM water_rights.change_events WHERE id = $1), '0'), $1)
+    AND e.transaction < pg_snapshot_xmin(pg_current_snapshot())
+ORDER BY e.transaction, e.id
+LIMIT $2;
@@ -0,0 +1,53 @@
+-- the reference layers do not contain water rights and are therefore not
+-- restricted by the access policy. the withdrawals summed up for the areas
+-- only contain the usage locations visible to the caller
+
+-- name: get-reference-layers
+SELECT l.name, l.source_srid, l.uploaded_at, count(a.id) AS areas
+FROM water_rights.reference_layers l
+    LEFT JOIN water_rights.reference_areas a ON a.layer = l.name
+GROUP BY l.name
+ORDER BY l.name;
+
+-- name: get-reference-layer
+SELECT l.name, l.source_srid, l.uploaded_at, count(a.id) AS areas
+FROM water_rights.reference_layers l
+    LEFT JOIN water_rights.reference_areas a ON a.layer = l.name
+WHERE l.name = $1
+GROUP BY l.name;
+
+-- name: delete-reference-layer
+DELETE
+FROM water_rights.reference_layers
+WHERE name = $1;
+
+-- name: create-reference-layer
+INSERT INTO water_rights.reference_layers (name, source_srid)
+VALUES ($1, $2);
+
+-- name: create-reference-area
+-- the polygons are uploaded in their source reference system and transformed
+-- into the reference system of the usage locations. the elevation of three
+-- dimensional polygons is dropped and invalid polygons (e.g., with
+-- self-intersecting rings) are repaired before storing them
+INSERT INTO water_rights.reference_areas (layer, key, name, properties, geometry)
+VALUES ($1, $2, $3, $4,
+        ST_Multi(ST_CollectionExtract(ST_MakeValid(ST_Transform(ST_Force2D($5::geometry), 25832)), 3)));
+
+-- name: get-layer-withdrawals
+-- usage locations on the boundary of multiple areas are counted for each of
+-- the areas
+SELECT a.key,
+       a.name,
+       count(w.usage_location)               AS usage_locations,
+       coalesce(sum(w.minimal_withdrawal), 0) AS minimal_withdrawal,
+       coalesce(sum(w.maximal_withdrawal), 0) AS maximal_withdrawal
+FROM water_rights.reference_areas a
+    LEFT JOIN water_rights.usage_location_withdrawals w
+        ON w.current
+            AND w.active
+            AND ST_Covers(a.geometry, w.location)
+            AND water_rights.visible($2, ARRAY [w.legal_department::text], w.water_authority)
+WHERE a.layer = $1
+GROUP BY a.id
+ORDER BY a.key;
@@ -0,0 +1,18 @@
+-- the data metrics are exposed without a caller and are therefore not
+-- restricted by the access policy. they only contain aggregated values that do
+-- not identify a water right
+
+-- name: metrics_count-current-rights
+SELECT count(*)
+FROM water_rights.current_rights
+WHERE deleted IS NULL;
+
+-- name: metrics_count-active-locations
+SELECT count(*)
+FROM water_rights.usage_locations
+WHERE active
+    AND water_right IN (SELECT internal_id FROM water_rights.current_rights WHERE deleted IS NULL);
+
+-- name: metrics_last-import
+SELECT max(occurred)
+FROM water_rights.change_events;
@@ -0,0 +1,149 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- change_events acts as an outbox for all changes the crawler writes into the
+-- water right tables. the events are emitted by triggers since the import is
+-- executed directly against the database and not through the service
+CREATE TABLE IF NOT EXISTS water_rights.change_events
+(
+    id             bigserial PRIMARY KEY,
+    type           text        NOT NULL,
+    water_right    bigint      DEFAULT NULL,
+    usage_location bigint      DEFAULT NULL,
+    occurred       timestamptz NOT NULL DEFAULT now(),
+    -- transaction is the transaction that recorded the event. the ids are
+    -- allocated before the transaction commits, so a transaction committing
+    -- late may add events below ids that have already been read. the events
+    -- are therefore read ordered by their transaction and only once every
+    -- older transaction has finished
+    transaction    xid8        NOT NULL DEFAULT pg_current_xact_id()
+);
+
+CREATE INDEX IF NOT EXISTS change_events_order_idx
+    ON water_rights.change_events (transaction, id);
+
+CREATE TABLE IF NOT EXISTS water_rights.webhook_subscriptions
+(
+    id            bigserial PRIMARY KEY,
+    url           text        NOT NULL,
+    secret        text        NOT NULL,
+    -- events contains the event types the subscriber wants to receive. an
+    -- empty array or NULL subscribes to all events
+    events        text[]      DEFAULT NULL,
+    -- last_event points to the last change event that has been handled for
+    -- the subscriber regardless of the delivery outcome
+    last_event    bigint      NOT NULL DEFAULT 0,
+    created       timestamptz NOT NULL DEFAULT now(),
+    -- claimed_until is set while a service instance delivers the events to
+    -- the subscriber. other instances skip the subscription until the claim
+    -- is released or has expired
+    claimed_until timestamptz DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS water_rights.webhook_deliveries
+(
+    id           bigserial PRIMARY KEY,
+    subscription bigint REFERENCES water_rights.webhook_subscriptions (id) ON DELETE CASCADE NOT NULL,
+    event        bigint REFERENCES water_rights.change_events (id) ON DELETE CASCADE         NOT NULL,
+    attempt      int         NOT NULL,
+    status_code  int         DEFAULT NULL,
+    error        text        DEFAULT NULL,
+    duration_ms  bigint      NOT NULL,
+    attempted    timestamptz NOT NULL DEFAULT now(),
+    -- failed marks the last attempt of an event that could not be delivered.
+    -- the event is not retried afterward
+    failed       bool        NOT NULL DEFAULT false
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_failed_idx
+    ON water_rights.webhook_deliveries (subscription, event)
+    WHERE failed;
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_subscription_idx
+    ON water_rights.webhook_deliveries (subscription, attempted DESC);
+
+CREATE OR REPLACE FUNCTION water_rights.record_right_change() RETURNS trigger AS
+$$
+BEGIN
+    IF tg_op = 'INSERT' THEN
+        INSERT INTO water_rights.change_events (type, water_right) VALUES ('water-right.created', new.id);
+    ELSIF new IS DISTINCT FROM old THEN
+        INSERT INTO water_rights.change_events (type, water_right) VALUES ('water-right.updated', new.id);
+    END IF;
+    RETURN new;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION water_rights.record_right_retirement() RETURNS trigger AS
+$$
+BEGIN
+    IF new.deleted IS NOT NULL AND (tg_op = 'INSERT' OR old.deleted IS NULL) THEN
+        INSERT INTO water_rights.change_events (type, water_right) VALUES ('water-right.retired', new.internal_id);
+    END IF;
+    RETURN new;
+END;
+$$ LANGUAGE plpgsql;
+
+-- the crawler stores every version of a water right with its own copies of
+-- the usage locations. a usage location has been moved if the usage location
+-- with the same number in the previous version of the water right has been
+-- stored at a different location
+CREATE OR REPLACE FUNCTION water_rights.record_location_move() RETURNS trigger AS
+$$
+DECLARE
+    previous geometry;
+BEGIN
+    IF tg_op = 'UPDATE' THEN
+        previous := old.location;
+    ELSIF new.no IS NOT NULL THEN
+        SELECT l.location
+        INTO previous
+        FROM water_rights.usage_locations l
+            JOIN water_rights.rights r ON r.id = l.water_right
+        WHERE r.water_right_number = (SELECT water_right_number FROM water_rights.rights WHERE id = new.water_right)
+            AND r.id < new.water_right
+            AND l.no = new.no
+        ORDER BY r.id DESC
+        LIMIT 1;
+    END IF;
+
+    IF previous IS NOT NULL AND NOT ST_Equals(new.location, previous) THEN
+        INSERT INTO water_rights.change_events (type, water_right, usage_location)
+        VALUES ('usage-location.moved', new.water_right, new.id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER record_right_change
+    AFTER INSERT OR UPDATE
+    ON water_rights.rights
+    FOR EACH ROW
+EXECUTE FUNCTION water_rights.record_right_change();
+
+CREATE OR REPLACE TRIGGER record_right_retirement
+    AFTER INSERT OR UPDATE OF deleted
+    ON water_rights.current_rights
+    FOR EACH ROW
+EXECUTE FUNCTION water_rights.record_right_retirement();
+
+CREATE OR REPLACE TRIGGER record_location_move
+    AFTER INSERT OR UPDATE OF location
+    ON water_rights.usage_locations
+    FOR EACH ROW
+    WHEN (new.location IS NOT NULL)
+EXECUTE FUNCTION water_rights.record_location_move();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS record_location_move ON water_rights.usage_locations;
+DROP TRIGGER IF EXISTS record_right_retirement ON water_rights.current_rights;
+DROP TRIGGER IF EXISTS record_right_change ON water_rights.rights;
+DROP FUNCTION IF EXISTS water_rights.record_location_move();
+DROP FUNCTION IF EXISTS water_rights.record_right_retirement();
+DROP FUNCTION IF EXISTS water_rights.record_right_change();
+DROP TABLE IF EXISTS water_rights.webhook_deliveries;
+DROP TABLE IF EXISTS water_rights.webhook_subscriptions;
+DROP TABLE IF EXISTS water_rights.change_events;
+-- +goose StatementEnd
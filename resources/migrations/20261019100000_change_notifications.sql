@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- the usage locations are recorded as well to allow clients to follow all
+-- changes applied by an import
+CREATE OR REPLACE FUNCTION water_rights.record_location_change() RETURNS trigger AS
+$$
+BEGIN
+    IF tg_op = 'INSERT' THEN
+        INSERT INTO water_rights.change_events (type, water_right, usage_location)
+        VALUES ('usage-location.created', new.water_right, new.id);
+    ELSIF tg_op = 'DELETE' THEN
+        INSERT INTO water_rights.change_events (type, water_right, usage_location)
+        VALUES ('usage-location.deleted', old.water_right, old.id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- notify_changes informs listening service instances that new change events
+-- have been recorded. the payload is constant per table which lets postgres
+-- collapse the notifications of a whole import transaction into a single one
+CREATE OR REPLACE FUNCTION water_rights.notify_changes() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_notify('water_rights_changes', tg_table_name);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER record_location_change
+    AFTER INSERT OR DELETE
+    ON water_rights.usage_locations
+    FOR EACH ROW
+EXECUTE FUNCTION water_rights.record_location_change();
+
+CREATE OR REPLACE TRIGGER notify_changes
+    AFTER INSERT OR UPDATE OR DELETE
+    ON water_rights.rights
+    FOR EACH STATEMENT
+EXECUTE FUNCTION water_rights.notify_changes();
+
+CREATE OR REPLACE TRIGGER notify_changes
+    AFTER INSERT OR UPDATE OR DELETE
+    ON water_rights.usage_locations
+    FOR EACH STATEMENT
+EXECUTE FUNCTION water_rights.notify_changes();
+
+CREATE OR REPLACE TRIGGER notify_changes
+    AFTER INSERT OR UPDATE
+    ON water_rights.current_rights
+    FOR EACH STATEMENT
+EXECUTE FUNCTION water_rights.notify_changes();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS notify_changes ON water_rights.current_rights;
+DROP TRIGGER IF EXISTS notify_changes ON water_rights.usage_locations;
+DROP TRIGGER IF EXISTS notify_changes ON water_rights.rights;
+DROP TRIGGER IF EXISTS record_location_change ON water_rights.usage_locations;
+DROP FUNCTION IF EXISTS water_rights.notify_changes();
+DROP FUNCTION IF EXISTS water_rights.record_location_change();
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- visible evaluates the access policy rules passed by the service for a
+-- single water right or usage location. the policy is a json array of rules
+-- and a row is visible if any rule permits it. a rule permits a row if the
+-- legal departments overlap with the rules legal departments and the water
+-- authority is contained in the rules water authorities. missing attributes
+-- in a rule do not restrict the row. a NULL policy permits every row
+CREATE OR REPLACE FUNCTION water_rights.visible(policy jsonb, legal_departments text[], water_authority text)
+    RETURNS bool AS
+$$
+SELECT policy IS NULL
+           OR EXISTS (SELECT
+                      FROM jsonb_array_elements(policy) AS rule
+                      WHERE (rule -> 'legalDepartments' IS NULL
+                          OR legal_departments &&
+                             array(SELECT jsonb_array_elements_text(rule -> 'legalDepartments')))
+                        AND (rule -> 'waterAuthorities' IS NULL
+                          OR water_authority IN (SELECT jsonb_array_elements_text(rule -> 'waterAuthorities'))));
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS water_rights.visible(jsonb, text[], text);
+-- +goose StatementEnd
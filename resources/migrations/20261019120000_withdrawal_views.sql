@@ -0,0 +1,111 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- cubic_meters_per_year normalizes a rate into cubic meters per year. the
+-- function mirrors types.Rate.CubicMeterPerYear: a year consists of twelve
+-- months with 30 days each and rates using unknown units, no value or an
+-- empty interval are counted as zero
+CREATE OR REPLACE FUNCTION water_rights.cubic_meters_per_year(rate water_rights.rate)
+    RETURNS double precision AS
+$$
+SELECT CASE
+           WHEN rate.value IS NULL OR rate.per IS NULL THEN 0
+           WHEN seconds <= 0 THEN 0
+           WHEN rate.unit IN ('l', 'L', 'liter', 'litre', 'Liter', 'Litre')
+               THEN rate.value::double precision / (seconds / 31104000) / 1000
+           WHEN rate.unit IN ('m³', 'm^3', 'm3')
+               THEN rate.value::double precision / (seconds / 31104000)
+           ELSE 0
+           END
+FROM (SELECT ((extract(YEAR FROM rate.per) * 12 + extract(MONTH FROM rate.per)) * 2592000
+    + extract(DAY FROM rate.per) * 86400
+    + extract(EPOCH FROM rate.per - date_trunc('day', rate.per)))::double precision AS seconds) AS normalized;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- the withdrawal of a usage location with multiple rates is not known
+-- exactly. therefore, the smallest and the largest normalized rate are used
+-- as lower and upper bound of the annual withdrawal
+CREATE OR REPLACE FUNCTION water_rights.minimal_annual_withdrawal(rates water_rights.rate[])
+    RETURNS double precision AS
+$$
+SELECT min(water_rights.cubic_meters_per_year(rates[i]))
+FROM generate_subscripts(rates, 1) AS i;
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION water_rights.maximal_annual_withdrawal(rates water_rights.rate[])
+    RETURNS double precision AS
+$$
+SELECT max(water_rights.cubic_meters_per_year(rates[i]))
+FROM generate_subscripts(rates, 1) AS i;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- usage_location_withdrawals contains the normalized annual withdrawal of
+-- every usage location together with the attributes required to filter the
+-- locations and to evaluate the access policy
+CREATE MATERIALIZED VIEW IF NOT EXISTS water_rights.usage_location_withdrawals AS
+SELECT l.id                                                       AS usage_location,
+       l.water_right,
+       l.legal_department,
+       r.water_authority,
+       l.active,
+       l.real,
+       (l.municipal_area).key                                     AS municipality_key,
+       (l.municipal_area).name                                    AS municipality_name,
+       l.groundwater_body,
+       c.internal_id IS NOT NULL                                  AS current,
+       l.location,
+       water_rights.minimal_annual_withdrawal(l.withdrawal_rates) AS minimal_withdrawal,
+       water_rights.maximal_annual_withdrawal(l.withdrawal_rates) AS maximal_withdrawal
+FROM water_rights.usage_locations l
+         JOIN water_rights.rights r ON r.id = l.water_right
+         LEFT JOIN water_rights.current_rights c ON c.internal_id = l.water_right AND c.deleted IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS usage_location_withdrawals_usage_location
+    ON water_rights.usage_location_withdrawals (usage_location);
+
+CREATE INDEX IF NOT EXISTS usage_location_withdrawals_location
+    ON water_rights.usage_location_withdrawals USING gist (location);
+
+-- municipal_withdrawals sums the withdrawals of the active usage locations
+-- of the current water rights per municipality. the sums are split by the
+-- attributes of the access policy to allow summing only the visible parts
+CREATE MATERIALIZED VIEW IF NOT EXISTS water_rights.municipal_withdrawals AS
+SELECT municipality_key,
+       min(municipality_name)               AS municipality_name,
+       legal_department,
+       coalesce(water_authority, '')        AS water_authority,
+       count(*)                             AS usage_locations,
+       coalesce(sum(minimal_withdrawal), 0) AS minimal_withdrawal,
+       coalesce(sum(maximal_withdrawal), 0) AS maximal_withdrawal
+FROM water_rights.usage_location_withdrawals
+WHERE current
+  AND active
+  AND municipality_key IS NOT NULL
+GROUP BY municipality_key, legal_department, coalesce(water_authority, '');
+
+CREATE UNIQUE INDEX IF NOT EXISTS municipal_withdrawals_group
+    ON water_rights.municipal_withdrawals (municipality_key, legal_department, water_authority);
+
+-- view_refreshes records when the materialized views have been refreshed
+-- the last time
+CREATE TABLE IF NOT EXISTS water_rights.view_refreshes
+(
+    view         text PRIMARY KEY,
+    refreshed_at timestamptz NOT NULL DEFAULT now()
+);
+
+INSERT INTO water_rights.view_refreshes (view)
+VALUES ('usage_location_withdrawals'),
+       ('municipal_withdrawals')
+ON CONFLICT (view) DO UPDATE SET refreshed_at = excluded.refreshed_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS water_rights.view_refreshes;
+DROP MATERIALIZED VIEW IF EXISTS water_rights.municipal_withdrawals;
+DROP MATERIALIZED VIEW IF EXISTS water_rights.usage_location_withdrawals;
+DROP FUNCTION IF EXISTS water_rights.maximal_annual_withdrawal(water_rights.rate[]);
+DROP FUNCTION IF EXISTS water_rights.minimal_annual_withdrawal(water_rights.rate[]);
+DROP FUNCTION IF EXISTS water_rights.cubic_meters_per_year(water_rights.rate);
+-- +goose StatementEnd
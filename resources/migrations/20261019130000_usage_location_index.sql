@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- the spatial index allows ordering the usage locations by their distance to
+-- a point without computing the distance to every usage location
+CREATE INDEX IF NOT EXISTS usage_locations_location
+    ON water_rights.usage_locations USING gist (location);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS water_rights.usage_locations_location;
+-- +goose StatementEnd
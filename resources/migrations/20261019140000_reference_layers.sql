@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- reference_layers contains the polygon layers uploaded to group the
+-- withdrawals by areas not managed by this service (e.g., water protection
+-- zones)
+CREATE TABLE IF NOT EXISTS water_rights.reference_layers
+(
+    name        text PRIMARY KEY,
+    source_srid integer     NOT NULL,
+    uploaded_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- reference_areas contains the polygons of the layers. the polygons are
+-- transformed into the reference system of the usage locations while
+-- uploading them
+CREATE TABLE IF NOT EXISTS water_rights.reference_areas
+(
+    id         bigserial PRIMARY KEY,
+    layer      text                              NOT NULL
+        REFERENCES water_rights.reference_layers (name) ON DELETE CASCADE,
+    key        text                              NOT NULL,
+    name       text                                       DEFAULT NULL,
+    properties jsonb                                      DEFAULT NULL,
+    geometry   geometry('multipolygon', 25832)   NOT NULL,
+    UNIQUE (layer, key)
+);
+
+CREATE INDEX IF NOT EXISTS reference_areas_geometry
+    ON water_rights.reference_areas USING gist (geometry);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS water_rights.reference_areas;
+DROP TABLE IF EXISTS water_rights.reference_layers;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- the index allows finding the change events that exceeded their retention
+-- without reading every event
+CREATE INDEX IF NOT EXISTS change_events_occurred_idx
+    ON water_rights.change_events (occurred);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS water_rights.change_events_occurred_idx;
+-- +goose StatementEnd
@@ -0,0 +1,70 @@
+-- name: get-location-issues
+-- the extent is expected in EPSG:4326 and the duplicate distance in meters in
+-- EPSG:25832. usage locations with a missing or invalid point are only
+-- reported by the geometry checks. only the usage locations of the current
+-- water rights are checked
+WITH locations AS (SELECT l.id, l.water_right, l.location, (l.municipal_area).key AS municipality_key
+                   FROM water_rights.usage_locations l
+                       JOIN water_rights.current_rights c ON c.internal_id = l.water_right AND c.deleted IS NULL
+                       JOIN water_rights.rights r ON r.id = l.water_right
+                   WHERE water_rights.visible($7, ARRAY [l.legal_department::text], r.water_authority)),
+     points AS (SELECT *, ST_Transform(location, 4326) AS position
+                FROM locations
+                WHERE location IS NOT NULL
+                    AND NOT ST_IsEmpty(location)
+                    AND ST_IsValid(location)
+                    AND NOT (ST_X(location) = 0 AND ST_Y(location) = 0)),
+     issues AS (SELECT id                                   AS usage_location,
+                       water_right,
+                       'missing-geometry'                   AS check_name,
+                       'the usage location has no geometry' AS detail
+                FROM locations
+                WHERE location IS NULL
+                UNION ALL
+                SELECT id,
+                       water_right,
+                       'invalid-geometry',
+                       CASE
+                           WHEN ST_IsEmpty(location) THEN 'the point is empty'
+                           WHEN NOT ST_IsValid(location) THEN 'the point has invalid coordinates'
+                           ELSE 'the point is located at (0, 0)'
+                           END
+                FROM locations
+                WHERE location IS NOT NULL
+                    AND (ST_IsEmpty(location)
+                        OR NOT ST_IsValid(location)
+                        OR (ST_X(location) = 0 AND ST_Y(location) = 0))
+                UNION ALL
+                SELECT id,
+                       water_right,
+                       'outside-extent',
+                       format('the point (%s, %s) is located outside of the expected extent',
+                              round(ST_X(position)::numeric, 6), round(ST_Y(position)::numeric, 6))
+                FROM points
+                WHERE ST_X(position) NOT BETWEEN $1 AND $3
+                    OR ST_Y(position) NOT BETWEEN $2 AND $4
+                UNION ALL
+                -- the keys of the reference layer may omit the leading zero
+                -- of the municipality keys
+                SELECT p.id,
+                       p.water_right,
+                       'municipality-mismatch',
+                       format('the municipality key 0%s does not match the municipalities containing the point (%s)',
+                              p.municipality_key, string_agg(a.key, ', ' ORDER BY a.key))
+                FROM points p
+                    JOIN water_rights.reference_areas a ON a.layer = $5 AND ST_Covers(a.geometry, p.location)
+                WHERE p.municipality_key IS NOT NULL
+                GROUP BY p.id, p.water_right, p.municipality_key
+                HAVING NOT bool_or(ltrim(a.key, '0') = p.municipality_key::text)
+                UNION ALL
+                SELECT p.id,
+                       p.water_right,
+                       'duplicate-location',
+                       format('the point is within %s m of the usage locations %s',
+                              $6::double precision, string_agg(o.id::text, ', ' ORDER BY o.id))
+                FROM points p
+                    JOIN points o ON o.id <> p.id AND ST_DWithin(p.location, o.location, $6)
+                GROUP BY p.id, p.water_right)
+SELECT *
+FROM issues
+ORDER BY usage_location, check_name;
@@ -1,17 +1,25 @@
+-- All queries reading water rights or usage locations expect the access policy
+-- restriction of the caller as last argument which is evaluated using
+-- water_rights.visible.
+
 -- name: water-rights
 SELECT
     *
 FROM
-    water_rights.rights;
+    water_rights.rights
+WHERE
+    water_rights.visible ($1, legal_departments::text[], water_authority);
 
 -- name: get-current-wr
 SELECT
-    internal_id
+    c.internal_id
 FROM
-    water_rights.current_rights
+    water_rights.current_rights c
+    JOIN water_rights.rights r ON r.id = c.internal_id
 WHERE
-    water_right_number = $1
-    OR internal_id = $1;
+    (c.water_right_number = $1
+        OR c.internal_id = $1)
+    AND water_rights.visible ($2, r.legal_departments::text[], r.water_authority);
 
 -- name: get-water-right
 SELECT
@@ -19,34 +27,37 @@ SELECT
 FROM
     water_rights.rights
 WHERE
-    id = $1;
+    id = $1
+    AND water_rights.visible ($2, legal_departments::text[], water_authority);
 
 -- name: get-locations
 SELECT
-    *
+    l.*
 FROM
-    water_rights.usage_locations;
+    water_rights.usage_locations l
+    JOIN water_rights.rights r ON r.id = l.water_right
+WHERE
+    water_rights.visible ($1, ARRAY[l.legal_department::text], r.water_authority);
 
 -- name: get-water-right-usage-locations
 SELECT
-    *
+    l.*
 FROM
-    water_rights.usage_locations
+    water_rights.usage_locations l
+    JOIN water_rights.rights r ON r.id = l.water_right
 WHERE
-    water_right = $1;
+    l.water_right = $1
+    AND water_rights.visible ($2, ARRAY[l.legal_department::text], r.water_authority);
 
--- name: get-withdrawal-rates
+-- name: get-withdrawal-range
 SELECT
-    withdrawal_rates
+    coalesce(sum(w.minimal_withdrawal), 0) AS minimal,
+    coalesce(sum(w.maximal_withdrawal), 0) AS maximal
 FROM
-    water_rights.usage_locations
+    water_rights.usage_location_withdrawals w
 WHERE
-    active = true
-    AND id IN (
-        SELECT
-            internal_id
-        FROM
-            water_rights.current_rights
-    )
-    AND ST_Within (ST_Transform (location, 4326), $1)
-    AND withdrawal_rates is not NULL;
\ No newline at end of file
+    w.active = true
+    AND w.current
+    AND w.minimal_withdrawal IS NOT NULL
+    AND ST_Within (ST_Transform (w.location, 4326), $1)
+    AND water_rights.visible ($2, ARRAY[w.legal_department::text], w.water_authority);
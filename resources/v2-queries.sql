@@ -1,10 +1,138 @@
 -- name: v2_get-water-right
+-- the database id selects the version while the water right number selects
+-- its current version. the database id takes precedence if both match
+SELECT *
+FROM (SELECT r.*
+      FROM water_rights.rights r
+      WHERE r.id = $1
+          OR r.id = (SELECT c.internal_id
+                     FROM water_rights.current_rights c
+                     WHERE c.water_right_number = $1
+                         AND c.deleted IS NULL)
+      ORDER BY r.id = $1 DESC
+      LIMIT 1) r
+WHERE water_rights.visible($2, r.legal_departments::text[], r.water_authority);
+
+-- name: v2_get-water-rights
 SELECT *
 FROM water_rights.rights
-WHERE id = $1
-    OR water_right_number = $1;
+WHERE water_rights.visible($1, legal_departments::text[], water_authority)
+ORDER BY id;
 
 -- name: v2_get-water-right-usage-locations
-SELECT *
-FROM water_rights.usage_locations
-WHERE water_right = $1;
\ No newline at end of file
+SELECT l.*
+FROM water_rights.usage_locations l
+    JOIN water_rights.rights r ON r.id = l.water_right
+WHERE l.water_right = $1
+    AND water_rights.visible($2, ARRAY [l.legal_department::text], r.water_authority);
+
+-- name: v2_get-municipal-withdrawals
+SELECT '0' || municipality_key::text AS key,
+       min(municipality_name)        AS name,
+       sum(usage_locations)::bigint  AS usage_locations,
+       sum(minimal_withdrawal)       AS minimal_withdrawal,
+       sum(maximal_withdrawal)       AS maximal_withdrawal
+FROM water_rights.municipal_withdrawals
+WHERE water_rights.visible($1, ARRAY [legal_department::text], nullif(water_authority, ''))
+GROUP BY municipality_key
+ORDER BY municipality_key;
+
+-- name: v2_get-nearest-usage-locations
+-- the usage locations are ordered using the knn operator which allows using
+-- the spatial index. the distances are measured in meters in EPSG:25832.
+-- only the usage locations of the current water rights are searched
+SELECT l.*,
+       l.location <-> ST_Transform(ST_SetSRID(ST_MakePoint($1, $2), 4326), 25832) AS distance
+FROM water_rights.usage_locations l
+    JOIN water_rights.current_rights c ON c.internal_id = l.water_right AND c.deleted IS NULL
+    JOIN water_rights.rights r ON r.id = l.water_right
+WHERE l.location IS NOT NULL
+    AND ($3::double precision IS NULL
+        OR ST_DWithin(l.location, ST_Transform(ST_SetSRID(ST_MakePoint($1, $2), 4326), 25832), $3))
+    AND ($4::boolean IS NULL OR l.active = $4)
+    AND ($5::boolean IS NULL OR l.real = NOT $5)
+    AND ($6::text[] IS NULL OR l.legal_department::text = ANY ($6))
+    AND water_rights.visible($7, ARRAY [l.legal_department::text], r.water_authority)
+ORDER BY l.location <-> ST_Transform(ST_SetSRID(ST_MakePoint($1, $2), 4326), 25832), l.id
+LIMIT $8 OFFSET $9;
+
+-- name: v2_get-withdrawals-within
+-- the withdrawals are read from the precomputed withdrawals of the usage
+-- locations. the radius is measured in meters in EPSG:25832
+SELECT l.*,
+       ST_Distance(w.location, ST_Transform(ST_SetSRID(ST_MakePoint($1, $2), 4326), 25832)) AS distance,
+       coalesce(w.minimal_withdrawal, 0)                                                   AS minimal_withdrawal,
+       coalesce(w.maximal_withdrawal, 0)                                                   AS maximal_withdrawal
+FROM water_rights.usage_location_withdrawals w
+    JOIN water_rights.usage_locations l ON l.id = w.usage_location
+WHERE w.current
+    AND w.active
+    AND ST_DWithin(w.location, ST_Transform(ST_SetSRID(ST_MakePoint($1, $2), 4326), 25832), $3)
+    AND water_rights.visible($4, ARRAY [w.legal_department::text], w.water_authority)
+ORDER BY distance, l.id;
+
+-- name: v2_get-square-grid-withdrawals
+-- the cell of every usage location is computed instead of generating the grid
+-- over the extent of the usage locations, which would mostly consist of empty
+-- cells for small cell sizes. the cells match the cells of ST_SquareGrid
+WITH locations AS (SELECT usage_location, location, minimal_withdrawal, maximal_withdrawal
+                   FROM water_rights.usage_location_withdrawals
+                   WHERE current
+                       AND active
+                       AND location IS NOT NULL
+                       AND water_rights.visible($2, ARRAY [legal_department::text], water_authority)),
+     assignments AS (SELECT l.*,
+                            floor(ST_X(l.location) / $1::double precision)::integer AS i,
+                            floor(ST_Y(l.location) / $1::double precision)::integer AS j
+                     FROM locations l),
+     sums AS (SELECT i,
+                     j,
+                     count(*)                               AS usage_locations,
+                     coalesce(sum(minimal_withdrawal), 0)   AS minimal_withdrawal,
+                     coalesce(sum(maximal_withdrawal), 0)   AS maximal_withdrawal
+              FROM assignments
+              GROUP BY i, j)
+SELECT i, j, ST_Square($1, i, j, ST_SetSRID(ST_MakePoint(0, 0), 25832)) AS geometry,
+       usage_locations, minimal_withdrawal, maximal_withdrawal
+FROM sums
+ORDER BY i, j;
+
+-- name: v2_get-hexagon-grid-withdrawals
+-- the cell of every usage location is computed instead of generating the grid
+-- over the extent of the usage locations, which would mostly consist of empty
+-- cells for small cell sizes. the cells match the cells of ST_HexagonGrid.
+-- the hexagons contain the points closest to their centers, therefore the
+-- closest center of the neighbouring columns and rows is searched. every
+-- second column is shifted upwards by half the height of a hexagon
+WITH locations AS (SELECT usage_location, location, minimal_withdrawal, maximal_withdrawal
+                   FROM water_rights.usage_location_withdrawals
+                   WHERE current
+                       AND active
+                       AND location IS NOT NULL
+                       AND water_rights.visible($2, ARRAY [legal_department::text], water_authority)),
+     grid AS (SELECT $1::double precision AS size, sqrt(3) * $1::double precision AS height),
+     assignments AS (SELECT l.*, closest.i, closest.j
+                     FROM locations l
+                         CROSS JOIN grid g
+                         CROSS JOIN LATERAL (SELECT cell_column.i, cell_row.j
+                                             FROM generate_series(round(ST_X(l.location) / (1.5 * g.size))::integer - 1,
+                                                                  round(ST_X(l.location) / (1.5 * g.size))::integer + 1) AS cell_column(i)
+                                                 CROSS JOIN LATERAL (SELECT 1.5 * g.size * cell_column.i AS x,
+                                                                            CASE WHEN cell_column.i % 2 <> 0 THEN g.height / 2 ELSE 0 END AS offset_y) center
+                                                 CROSS JOIN LATERAL generate_series(round((ST_Y(l.location) - center.offset_y) / g.height)::integer - 1,
+                                                                                    round((ST_Y(l.location) - center.offset_y) / g.height)::integer + 1) AS cell_row(j)
+                                             ORDER BY (ST_X(l.location) - center.x) ^ 2
+                                                          + (ST_Y(l.location) - center.offset_y - cell_row.j * g.height) ^ 2,
+                                                      cell_column.i, cell_row.j
+                                             LIMIT 1) closest),
+     sums AS (SELECT i,
+                     j,
+                     count(*)                               AS usage_locations,
+                     coalesce(sum(minimal_withdrawal), 0)   AS minimal_withdrawal,
+                     coalesce(sum(maximal_withdrawal), 0)   AS maximal_withdrawal
+              FROM assignments
+              GROUP BY i, j)
+SELECT i, j, ST_Hexagon($1, i, j, ST_SetSRID(ST_MakePoint(0, 0), 25832)) AS geometry,
+       usage_locations, minimal_withdrawal, maximal_withdrawal
+FROM sums
+ORDER BY i, j;
@@ -0,0 +1,17 @@
+-- The materialized views are refreshed in the order they depend on each other.
+
+-- name: refresh-usage-location-withdrawals
+REFRESH MATERIALIZED VIEW CONCURRENTLY water_rights.usage_location_withdrawals;
+
+-- name: refresh-municipal-withdrawals
+REFRESH MATERIALIZED VIEW CONCURRENTLY water_rights.municipal_withdrawals;
+
+-- name: record-view-refresh
+INSERT INTO water_rights.view_refreshes (view, refreshed_at)
+VALUES ($1, now())
+ON CONFLICT (view) DO UPDATE SET refreshed_at = excluded.refreshed_at;
+
+-- name: get-view-freshness
+SELECT min(refreshed_at)
+FROM water_rights.view_refreshes
+WHERE view = ANY ($1);
@@ -0,0 +1,81 @@
+-- name: get-webhook-subscriptions
+SELECT *
+FROM water_rights.webhook_subscriptions
+ORDER BY id;
+
+-- name: get-webhook-subscription
+SELECT *
+FROM water_rights.webhook_subscriptions
+WHERE id = $1;
+
+-- name: create-webhook-subscription
+INSERT INTO water_rights.webhook_subscriptions (url, secret, events, last_event)
+VALUES ($1, $2, $3, coalesce((SELECT id
+                              FROM water_rights.change_events
+                              WHERE transaction < pg_snapshot_xmin(pg_current_snapshot())
+                              ORDER BY transaction DESC, id DESC
+                              LIMIT 1), 0))
+RETURNING *;
+
+-- name: delete-webhook-subscription
+DELETE
+FROM water_rights.webhook_subscriptions
+WHERE id = $1;
+
+-- name: get-pending-change-events
+-- the access policy is not applied as only administrators may subscribe
+SELECT e.id, e.type, e.water_right, e.usage_location, e.occurred
+FROM water_rights.change_events e
+WHERE (e.transaction, e.id) > (coalesce((SELECT transaction FROM water_rights.change_events WHERE id = $1), '0'), $1)
+    AND e.transaction < pg_snapshot_xmin(pg_current_snapshot())
+    AND (coalesce(cardinality($2::text[]), 0) = 0 OR e.type = ANY ($2::text[]))
+ORDER BY e.transaction, e.id
+LIMIT $3;
+
+-- name: claim-webhook-subscriptions
+UPDATE water_rights.webhook_subscriptions
+SET claimed_until = now() + $1::interval
+WHERE id IN (SELECT id
+             FROM water_rights.webhook_subscriptions
+             WHERE claimed_until IS NULL
+                OR claimed_until < now()
+             ORDER BY id
+             FOR UPDATE SKIP LOCKED)
+RETURNING *;
+
+-- name: advance-webhook-subscription
+UPDATE water_rights.webhook_subscriptions
+SET last_event    = $2,
+    claimed_until = now() + $3::interval
+WHERE id = $1;
+
+-- name: release-webhook-subscription
+UPDATE water_rights.webhook_subscriptions
+SET claimed_until = NULL
+WHERE id = $1;
+
+-- name: record-webhook-delivery
+INSERT INTO water_rights.webhook_deliveries (subscription, event, attempt, status_code, error, duration_ms, failed)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: get-webhook-deliveries
+SELECT d.*, e.type AS event_type
+FROM water_rights.webhook_deliveries d
+    JOIN water_rights.change_events e ON e.id = d.event
+WHERE d.subscription = $1
+ORDER BY d.attempted DESC
+LIMIT $2;
+
+-- name: delete-expired-change-events
+-- the events are kept until every subscription handled them. the last event
+-- handled for a subscription is kept as its position in the change events
+-- is determined using the transaction of the event
+DELETE
+FROM water_rights.change_events e
+WHERE e.occurred < now() - $1::interval
+    AND e.transaction < pg_snapshot_xmin(pg_current_snapshot())
+    AND NOT EXISTS (SELECT
+                    FROM water_rights.webhook_subscriptions s
+                        LEFT JOIN water_rights.change_events handled ON handled.id = s.last_event
+                    WHERE s.last_event = e.id
+                       OR (e.transaction, e.id) > (coalesce(handled.transaction, '0'), s.last_event));
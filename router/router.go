@@ -3,35 +3,85 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 
+	"microservice/internal/cache"
+	"microservice/internal/openapi"
 	internal "microservice/internal/router"
+	"microservice/internal/store"
 	v1Routes "microservice/routes/v1"
 	v2Routes "microservice/routes/v2"
 )
 
-// Configure generates a new router and adds routes to the router
+// Configure generates a new router and adds routes to the router.
+// The handlers read the water rights and usage locations from the supplied
+// store.
 //
 // The router can also be imported during tests, as long as the tests are in a
 // separate package.
 // If the tests are in the same package (e.g. routes defined in `v3` and tests
 // also defined in `v3`) an import cycle exists.
-func Configure() (*gin.Engine, error) {
-	r, err := internal.GenerateRouter()
+func Configure(s store.WaterRightStore) (*gin.Engine, error) {
+	v1Spec, err := openapi.LoadEmbedded("v1.openapi.yaml")
+	if err != nil {
+		return nil, err
+	}
+	v2Spec, err := openapi.LoadEmbedded("v2.openapi.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	// the specifications are public to allow generating clients without
+	// a token
+	r, err := internal.GenerateRouter(map[string]gin.HandlerFunc{
+		"/v1/openapi.yaml": v1Spec.Serve,
+		"/v2/openapi.yaml": v2Spec.Serve,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	v1 := r.Group("/v1")
+	v1Handlers := v1Routes.Routes{Store: s}
+	v1 := r.Group("/v1", internal.RequireRead, v1Spec.Validate("/v1"))
 	{
-		v1.GET("/", v1Routes.UsageLocations)
-		v1.GET("/details/:id", v1Routes.WaterRightDetails)
-		v1.POST("/average-withdrawals", v1Routes.AverageWaterTakeout)
+		v1.GET("/", cache.Middleware, v1Handlers.UsageLocations)
+		v1.GET("/details/:id", v1Handlers.WaterRightDetails)
+		v1.POST("/average-withdrawals", v1Handlers.AverageWaterTakeout)
 
 	}
 
-	v2 := r.Group("/v2")
+	v2Handlers := v2Routes.Routes{Store: s}
+	// the request bodies are limited before the validation reads them. the
+	// reference layers are the largest bodies accepted
+	v2 := r.Group("/v2", internal.RequireRead, internal.LimitBody(v2Routes.MaximalLayerSize), v2Spec.Validate("/v2"))
 	{
-		v2.GET("/", v2Routes.UsageLocations)
-		v2.GET("/water-right-details/:id", v2Routes.WaterRightDetails)
+		v2.GET("/", cache.Middleware, v2Handlers.UsageLocations)
+		v2.GET("/water-right-details/:id", v2Handlers.WaterRightDetails)
+		v2.GET("/nearest", v2Handlers.NearestUsageLocations)
+		v2.GET("/withdrawals", v2Handlers.Withdrawals)
+		v2.POST("/analysis/impact", v2Handlers.ImpactAnalysis)
+		v2.GET("/analysis/grid", v2Handlers.GridAnalysis)
+		v2.GET("/events", v2Routes.Events)
+
+		quality := v2.Group("/quality")
+		{
+			quality.GET("/locations", v2Handlers.LocationQuality)
+			quality.GET("/water-rights", v2Handlers.WaterRightQuality)
+			quality.GET("/water-rights/issues", v2Handlers.WaterRightIssues)
+		}
+
+		layers := v2.Group("/layers")
+		{
+			layers.GET("/", v2Handlers.ReferenceLayers)
+			layers.PUT("/:name", internal.RequireAdministrator, v2Handlers.UploadReferenceLayer)
+			layers.DELETE("/:name", internal.RequireAdministrator, v2Handlers.DeleteReferenceLayer)
+		}
+
+		webhooks := v2.Group("/webhooks", internal.RequireAdministrator)
+		{
+			webhooks.GET("/", v2Routes.WebhookSubscriptions)
+			webhooks.POST("/", v2Routes.CreateWebhookSubscription)
+			webhooks.DELETE("/:id", v2Routes.DeleteWebhookSubscription)
+			webhooks.GET("/:id/deliveries", v2Routes.WebhookDeliveries)
+		}
 	}
 
 	return r, nil
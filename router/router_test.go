@@ -0,0 +1,75 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"microservice/internal/configuration"
+	"microservice/internal/store"
+	"microservice/internal/store/storetest"
+	"microservice/router"
+)
+
+func TestMain(m *testing.M) {
+	storetest.Main(m)
+}
+
+// requireAuthorization configures an authority publishing an empty key set,
+// which causes every request on an authenticated route to be rejected.
+func requireAuthorization(t *testing.T) {
+	t.Helper()
+
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   server.URL,
+			"jwks_uri": server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"keys":[]}`))
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	c := configuration.Default.Viper()
+	c.Set(configuration.ConfigurationKey_AuthorizationRequired, true)
+	c.Set(configuration.ConfigurationKey_OidcAuthority, server.URL)
+	c.Set(configuration.ConfigurationKey_OidcAudience, "water-rights-api")
+	t.Cleanup(func() {
+		c.Set(configuration.ConfigurationKey_AuthorizationRequired, false)
+	})
+}
+
+func TestSpecificationsWithoutToken(t *testing.T) {
+	requireAuthorization(t)
+
+	r, err := router.Configure(store.NewMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{"/v1/openapi.yaml", "/v2/openapi.yaml"} {
+		t.Run(target, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			r.ServeHTTP(res, httptest.NewRequest(http.MethodGet, target, nil))
+
+			if res.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, res.Code, res.Body.String())
+			}
+			if res.Body.Len() == 0 {
+				t.Error("expected the specification in the response")
+			}
+		})
+	}
+
+	// the other routes still require the service to be started and a token
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/v2/", nil))
+	if res.Code == http.StatusOK {
+		t.Errorf("expected the usage locations to be rejected without a token")
+	}
+}
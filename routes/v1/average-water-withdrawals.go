@@ -2,10 +2,8 @@ package v1
 
 import (
 	"net/http"
-	"slices"
 	"sync"
 
-	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/gin-gonic/gin"
 	"github.com/twpayne/go-geom"
 	"github.com/twpayne/go-geom/encoding/geojson"
@@ -13,9 +11,7 @@ import (
 
 	wisdom "github.com/wisdom-oss/common-go/v3/types"
 
-	"microservice/globals"
-	"microservice/internal/db"
-	"microservice/types"
+	"microservice/internal/access"
 )
 
 var (
@@ -27,7 +23,7 @@ var (
 	}
 )
 
-func AverageWaterTakeout(c *gin.Context) {
+func (r Routes) AverageWaterTakeout(c *gin.Context) {
 	var geometries []geojson.Geometry
 	if err := c.ShouldBindBodyWithJSON(&geometries); err != nil {
 		c.Abort()
@@ -35,14 +31,9 @@ func AverageWaterTakeout(c *gin.Context) {
 		return
 	}
 
-	query, err := globals.SqlQueries.Raw("get-withdrawal-rates")
-	if err != nil {
-		c.Abort()
-		_ = c.Error(err)
-		return
-	}
+	restriction := access.For(c)
 
-	var withdrawalRates [][]types.Rate
+	var minimalTakeout, maximalTakeout float64
 	var lock sync.Mutex
 	var paralel errgroup.Group
 	for _, geometry := range geometries {
@@ -57,17 +48,14 @@ func AverageWaterTakeout(c *gin.Context) {
 				return err
 			}
 
-			var r [][]types.Rate
-			err = pgxscan.Select(c, db.Pool(), &r, query, decodedGeometry)
+			withdrawal, err := r.Store.WithdrawalRange(c, decodedGeometry, restriction)
 			if err != nil {
-				if pgxscan.NotFound(err) {
-					return nil
-				}
 				return err
 			}
 
 			lock.Lock()
-			withdrawalRates = append(withdrawalRates, r...)
+			minimalTakeout += withdrawal.Minimal
+			maximalTakeout += withdrawal.Maximal
 			lock.Unlock()
 
 			return nil
@@ -75,27 +63,18 @@ func AverageWaterTakeout(c *gin.Context) {
 		})
 	}
 
-	err = paralel.Wait()
+	err := paralel.Wait()
 	if err != nil {
 		c.Abort()
 		_ = c.Error(err)
 		return
 	}
 
-	var minimalTakeout, maximalTakeout float64
-	for _, rates := range withdrawalRates {
-		if len(rates) == 1 {
-			minimalTakeout += rates[0].CubicMeterPerYear()
-			maximalTakeout += rates[0].CubicMeterPerYear()
-		}
-
-		var possibleRates []float64
-		for _, rate := range rates {
-			possibleRates = append(possibleRates, rate.CubicMeterPerYear())
-		}
-
-		minimalTakeout += slices.Min(possibleRates)
-		maximalTakeout += slices.Max(possibleRates)
+	freshness, err := r.Store.Freshness(c)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
 	}
 
 	var takeout struct {
@@ -105,6 +84,9 @@ func AverageWaterTakeout(c *gin.Context) {
 	takeout.Maximal = maximalTakeout
 	takeout.Minimal = minimalTakeout
 
+	// the response body of the v1 api is frozen, therefore the time of the
+	// last refresh of the precomputed withdrawals is only sent as header
+	c.Header("Last-Modified", freshness.UTC().Format(http.TimeFormat))
 	c.JSON(http.StatusOK, takeout)
 
 }
@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"microservice/internal/store/storetest"
+)
+
+// hasbergen is a polygon in EPSG:4326 around the well of the water right
+// 4711 which excludes the other usage locations.
+const hasbergen = `[{"type":"Polygon","coordinates":[[[7.9,52.23],[7.95,52.23],[7.95,52.27],[7.9,52.27],[7.9,52.23]]]}]`
+
+func TestAverageWaterTakeout(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	res := serve(m, storetest.Administrator, http.MethodPost, "/average-withdrawals", hasbergen)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+	}
+
+	var withdrawal struct {
+		Minimal float64 `json:"minimalWithdrawal"`
+		Maximal float64 `json:"maximalWithdrawal"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &withdrawal); err != nil {
+		t.Fatal(err)
+	}
+	if withdrawal.Minimal != 120000 || withdrawal.Maximal != 120000 {
+		t.Errorf("expected a withdrawal of 120000 m³, got %+v", withdrawal)
+	}
+
+	if lastModified := res.Header().Get("Last-Modified"); lastModified != storetest.Freshness.Format(http.TimeFormat) {
+		t.Errorf("expected the freshness %s as Last-Modified header, got %q", storetest.Freshness, lastModified)
+	}
+}
+
+func TestAverageWaterTakeoutRestricted(t *testing.T) {
+	m := storetest.NewStore(t)
+	storetest.EnableAccessPolicy(t)
+
+	res := serve(m, storetest.DepartmentB, http.MethodPost, "/average-withdrawals", hasbergen)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+	}
+	if res.Body.String() != `{"minimalWithdrawal":0,"maximalWithdrawal":0}` {
+		t.Errorf("expected the hidden well to be excluded, got %s", res.Body.String())
+	}
+}
+
+func TestAverageWaterTakeoutInvalidBody(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	res := serve(m, storetest.Administrator, http.MethodPost, "/average-withdrawals", `{"type":"Point"}`)
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 Bad Request, got %d", res.Code)
+	}
+}
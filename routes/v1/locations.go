@@ -1,34 +1,45 @@
 package v1
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 
-	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
-	"microservice/internal/db"
+	common "github.com/wisdom-oss/common-go/v3/types"
+
+	"microservice/internal/access"
+	"microservice/internal/redaction"
+	"microservice/internal/tracing"
 	"microservice/types"
 )
 
-func UsageLocations(c *gin.Context) {
+var (
+	errInvalidUsageLocationQuery = common.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Invalid Usage Location Query",
+		Detail: "The 'is_active' and 'is_real' parameters need to be booleans",
+	}
+)
+
+func (r Routes) UsageLocations(c *gin.Context) {
 	var queryParams struct {
 		MunicipalityKeys []string `form:"in"`
 		Active           *bool    `form:"is_active"`
 		Real             *bool    `form:"is_real"`
 	}
-	_ = c.ShouldBindQuery(&queryParams)
-
-	query, err := db.Queries.Raw("get-locations")
-	if err != nil {
+	if err := c.ShouldBindQuery(&queryParams); err != nil {
 		c.Abort()
-		_ = c.Error(err)
+		errInvalidUsageLocationQuery.Emit(c)
 		return
 	}
 
-	var usageLocations []types.UsageLocation
-	err = pgxscan.Select(c, db.Pool(), &usageLocations, query)
+	usageLocations, err := r.Store.LegacyUsageLocations(c, access.For(c))
 	if err != nil {
 		c.Abort()
 		_ = c.Error(err)
@@ -72,6 +83,22 @@ locations:
 		filteredLocations = append(filteredLocations, location)
 	}
 output:
-	c.JSON(http.StatusOK, filteredLocations)
+	redactor := redaction.For(c)
+	for idx := range filteredLocations {
+		filteredLocations[idx].RedactPersonalData(redactor)
+	}
+
+	// the locations are reprojected while marshalling them
+	_, span := tracing.Tracer.Start(c.Request.Context(), "usage-locations.marshal",
+		trace.WithAttributes(attribute.Int("locations", len(filteredLocations))))
+	encoded, err := json.Marshal(filteredLocations)
+	span.End()
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", encoded)
 
 }
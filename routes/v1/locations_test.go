@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"microservice/internal/store/storetest"
+)
+
+func TestUsageLocations(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	tests := []struct {
+		name     string
+		as       storetest.Caller
+		query    string
+		policy   bool
+		expected []int64
+	}{
+		{name: "all", as: storetest.Administrator, expected: []int64{10, 11, 12}},
+		{name: "active", as: storetest.Administrator, query: "?is_active=true", expected: []int64{10, 12}},
+		{name: "virtual", as: storetest.Administrator, query: "?is_real=false", expected: []int64{11}},
+		{name: "municipality", as: storetest.Administrator, query: "?in=03459", expected: []int64{10, 11}},
+		{name: "restricted", as: storetest.DepartmentB, policy: true, expected: []int64{12}},
+		{name: "without rule", as: storetest.Anonymous, policy: true, expected: []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.policy {
+				storetest.EnableAccessPolicy(t)
+			}
+
+			res := serve(m, tt.as, http.MethodGet, "/"+tt.query, "")
+			if res.Code != http.StatusOK {
+				t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+			}
+
+			var locations []struct {
+				ID int64 `json:"id"`
+			}
+			if err := json.Unmarshal(res.Body.Bytes(), &locations); err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]int64, 0, len(locations))
+			for _, location := range locations {
+				ids = append(ids, location.ID)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.expected) {
+				t.Errorf("expected the usage locations %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestUsageLocationsInvalidQuery(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	for _, query := range []string{"is_active=yes", "is_real=1.5"} {
+		res := serve(m, storetest.Administrator, http.MethodGet, "/?"+query, "")
+		if res.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request for %q, got %d", query, res.Code)
+		}
+	}
+}
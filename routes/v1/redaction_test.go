@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"microservice/internal/redaction"
+	"microservice/internal/store/storetest"
+)
+
+func TestRedaction(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	tests := []struct {
+		name       string
+		as         storetest.Caller
+		mode       string
+		personal   bool
+		pseudonyms bool
+	}{
+		{name: "disabled", as: storetest.Anonymous, personal: true},
+		{name: "null", as: storetest.Anonymous, mode: redaction.ModeNull},
+		{name: "pseudonymize", as: storetest.Anonymous, mode: redaction.ModePseudonymize, pseudonyms: true},
+		{name: "personal data scope", as: storetest.PersonalData, mode: redaction.ModeNull, personal: true},
+		{name: "administrator", as: storetest.Administrator, mode: redaction.ModeNull, personal: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mode != "" {
+				storetest.EnableRedaction(t, tt.mode)
+			}
+
+			res := serve(m, tt.as, http.MethodGet, "/details/4711", "")
+			if res.Code != http.StatusOK {
+				t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+			}
+
+			// the multipart response contains the water right and its usage
+			// locations as separate parts
+			body := res.Body.String()
+			for _, value := range storetest.PersonalValues {
+				if strings.Contains(body, value) != tt.personal {
+					t.Errorf("expected %q to be returned: %t, got %s", value, tt.personal, body)
+				}
+			}
+			if tt.pseudonyms && !strings.Contains(body, "pseudonym:") {
+				t.Errorf("expected the personal data to be pseudonymized, got %s", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package v1
+
+import "microservice/internal/store"
+
+// Routes contains the handlers of the first api version.
+type Routes struct {
+	// Store is used to read the water rights and usage locations
+	Store store.WaterRightStore
+}
@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"microservice/internal/store"
+	"microservice/internal/store/storetest"
+)
+
+func TestMain(m *testing.M) {
+	storetest.Main(m)
+}
+
+// serve executes the request against the handlers of the routes using the
+// supplied store.
+func serve(m *store.Memory, as storetest.Caller, method, target, body string) *httptest.ResponseRecorder {
+	routes := Routes{Store: storetest.Store{Memory: m}}
+	return storetest.Serve(as, func(r gin.IRoutes) {
+		r.GET("/", routes.UsageLocations)
+		r.GET("/details/:id", routes.WaterRightDetails)
+		r.POST("/average-withdrawals", routes.AverageWaterTakeout)
+	}, method, target, body)
+}
@@ -2,18 +2,22 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
 	"strings"
 
-	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	common "github.com/wisdom-oss/common-go/v3/types"
 
-	"microservice/internal/db"
-	"microservice/types"
+	"microservice/internal/access"
+	"microservice/internal/redaction"
+	"microservice/internal/store"
+	"microservice/internal/tracing"
 )
 
 var (
@@ -32,7 +36,7 @@ var (
 	}
 )
 
-func WaterRightDetails(c *gin.Context) {
+func (r Routes) WaterRightDetails(c *gin.Context) {
 	waterRightID := strings.TrimSpace(c.Param("id"))
 	if waterRightID == "" {
 		c.Abort()
@@ -40,19 +44,15 @@ func WaterRightDetails(c *gin.Context) {
 		return
 	}
 
-	query, err := db.Queries.Raw("v2_get-water-right")
-	if err != nil {
-		c.Abort()
-		_ = c.Error(err)
-		return
-	}
+	// water rights hidden by the access policy are reported as unknown to not
+	// reveal their existence
+	restriction := access.For(c)
 
-	var waterRight types.WaterRight
-	err = pgxscan.Get(c, db.Pool(), &waterRight, query, waterRightID)
+	waterRight, err := r.Store.LegacyWaterRight(c, waterRightID, restriction)
 	if err != nil {
 		c.Abort()
 
-		if pgxscan.NotFound(err) {
+		if errors.Is(err, store.ErrNotFound) {
 			errUnknownWaterRight.Emit(c)
 			return
 		}
@@ -61,27 +61,22 @@ func WaterRightDetails(c *gin.Context) {
 		return
 	}
 
-	query, err = db.Queries.Raw("v2_get-water-right-usage-locations")
+	locations, err := r.Store.LegacyWaterRightUsageLocations(c, waterRight.ID.Int64, restriction)
 	if err != nil {
 		c.Abort()
 		_ = c.Error(err)
 		return
 	}
 
-	var locations []types.UsageLocation
-	err = pgxscan.Select(c, db.Pool(), &locations, query, waterRight.ID)
-	if err != nil {
-		c.Abort()
-
-		if pgxscan.NotFound(err) {
-			goto output
-		}
-
-		_ = c.Error(err)
-		return
+	redactor := redaction.For(c)
+	waterRight.RedactPersonalData(redactor)
+	for idx := range locations {
+		locations[idx].RedactPersonalData(redactor)
 	}
 
-output:
+	_, span := tracing.Tracer.Start(c.Request.Context(), "water-right.marshal",
+		trace.WithAttributes(attribute.Int("locations", len(locations))))
+	defer span.End()
 
 	multipartWriter := multipart.NewWriter(c.Writer)
 	c.Header("Content-Type", multipartWriter.FormDataContentType())
@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"microservice/internal/store/storetest"
+)
+
+func TestWaterRightDetails(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	res := serve(m, storetest.Administrator, http.MethodGet, "/details/4711", "")
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+	}
+
+	_, params, err := mime.ParseMediaType(res.Header().Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := multipart.NewReader(res.Body, params["boundary"])
+
+	parts := make(map[string][]byte)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[part.FormName()] = content
+	}
+
+	var waterRight struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(parts["water-right"], &waterRight); err != nil {
+		t.Fatal(err)
+	}
+	if waterRight.ID != 2 {
+		t.Errorf("expected the current version 2 of the water right, got %d", waterRight.ID)
+	}
+
+	var locations []struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(parts["usage-locations"], &locations); err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 2 {
+		t.Errorf("expected the two usage locations of the water right, got %d", len(locations))
+	}
+}
+
+func TestWaterRightDetailsNotFound(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	t.Run("unknown", func(t *testing.T) {
+		res := serve(m, storetest.Administrator, http.MethodGet, "/details/9999", "")
+		if res.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", res.Code)
+		}
+	})
+
+	t.Run("hidden", func(t *testing.T) {
+		storetest.EnableAccessPolicy(t)
+
+		res := serve(m, storetest.DepartmentB, http.MethodGet, "/details/4711", "")
+		if res.Code != http.StatusNotFound {
+			t.Errorf("expected the hidden water right to be reported as unknown, got %d", res.Code)
+		}
+	})
+}
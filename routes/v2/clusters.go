@@ -0,0 +1,80 @@
+package v2
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/twpayne/go-geom"
+	"github.com/wisdom-oss/common-go/v3/types"
+	"github.com/wroge/wgs84/v2"
+
+	v2 "microservice/types/v2"
+)
+
+const (
+	// clusterSRID is the reference system the clusters are computed in.
+	// Web Mercator is used by the map tiles and a cell therefore has the
+	// same size on the screen regardless of the latitude.
+	clusterSRID = 3857
+
+	// clusterSize is the edge length of the cells the usage locations are
+	// collected in, measured in pixels of a 256 pixel map tile.
+	clusterSize = 64
+
+	// webMercatorCircumference is the length of the equator in the units of
+	// Web Mercator.
+	webMercatorCircumference = 2 * math.Pi * 6378137
+
+	// maximalZoom is the largest zoom level supported by common map tiles.
+	maximalZoom = 24
+)
+
+var (
+	errInvalidClusterQuery = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Invalid Cluster Query",
+		Detail: "Clustering the usage locations requires a zoom level between 0 and 24 using the 'zoom' parameter",
+	}
+)
+
+// clusterCellSize returns the edge length of the cells in Web Mercator units
+// that are covered by clusterSize pixels at the zoom level.
+func clusterCellSize(zoom int) float64 {
+	return clusterSize * webMercatorCircumference / (256 * math.Exp2(float64(zoom))) //nolint:mnd
+}
+
+// clusterUsageLocations collects the usage locations into the cells of a
+// square grid with a zoom dependent cell size. The clusters are ordered by
+// the first usage location they contain. Usage locations without a usable
+// point can not be displayed on a map and are left out.
+func clusterUsageLocations(locations []v2.UsageLocation, zoom int) []v2.UsageLocationCluster {
+	cellSize := clusterCellSize(zoom)
+	transformers := make(map[int]wgs84.Func)
+
+	var clusters []v2.UsageLocationCluster
+	cells := make(map[[2]int64]int)
+	for _, location := range locations {
+		point, ok := location.Geometry.(*geom.Point)
+		if !ok || !location.HasGeometry() {
+			continue
+		}
+
+		transformer, found := transformers[point.SRID()]
+		if !found {
+			transformer = wgs84.Transform(wgs84.EPSG(point.SRID()), wgs84.EPSG(clusterSRID))
+			transformers[point.SRID()] = transformer
+		}
+		x, y, _ := transformer(point.X(), point.Y(), 0)
+
+		cell := [2]int64{int64(math.Floor(x / cellSize)), int64(math.Floor(y / cellSize))}
+		idx, found := cells[cell]
+		if !found {
+			idx = len(clusters)
+			cells[cell] = idx
+			clusters = append(clusters, v2.UsageLocationCluster{Column: cell[0], Row: cell[1]})
+		}
+		clusters[idx].UsageLocations = append(clusters[idx].UsageLocations, location)
+	}
+	return clusters
+}
@@ -0,0 +1,127 @@
+package v2
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/wisdom-oss/common-go/v3/types"
+
+	"microservice/internal/access"
+	"microservice/internal/events"
+	v2 "microservice/types/v2"
+)
+
+// heartbeatInterval is the time between two comments sent to keep idle
+// streams from being closed by proxies.
+const heartbeatInterval = 30 * time.Second
+
+// replayBatchSize limits how many events are read at once while replaying
+// the events a reconnecting client missed.
+const replayBatchSize = 500
+
+var errInvalidLastEventID = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: http.StatusBadRequest,
+	Title:  "Invalid Last Event ID",
+	Detail: "The Last-Event-ID header needs to contain the id of a previously received event",
+}
+
+// Events streams the changes applied to the water rights and usage locations
+// as server-sent events.
+// Clients reconnecting with the Last-Event-ID header receive all events that
+// have been recorded since the supplied event before receiving new events.
+// Events of water rights and usage locations hidden by the access policy are
+// left out.
+func Events(c *gin.Context) {
+	var lastEventID int64
+	if header := strings.TrimSpace(c.GetHeader("Last-Event-ID")); header != "" {
+		var err error
+		lastEventID, err = strconv.ParseInt(header, 10, 64)
+		if err != nil || lastEventID < 0 {
+			c.Abort()
+			errInvalidLastEventID.Emit(c)
+			return
+		}
+	}
+
+	restriction := access.For(c)
+
+	// subscribing before replaying the missed events ensures that no event
+	// recorded during the replay is lost
+	changes, unsubscribe := events.Default.Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	var lastEvent v2.ChangeEvent
+	if lastEventID > 0 {
+		var err error
+		lastEvent, err = events.Event(c, lastEventID)
+		if err != nil {
+			slog.Error("unable to look up the last received change event", "error", err)
+			return
+		}
+	}
+
+	for lastEvent.ID > 0 {
+		missedEvents, err := events.Since(c, lastEvent.ID, replayBatchSize)
+		if err != nil {
+			slog.Error("unable to replay change events", "error", err)
+			return
+		}
+
+		for _, event := range missedEvents {
+			if restriction.Permits(event.LegalDepartments, event.WaterAuthority) {
+				writeEvent(c, event)
+			}
+			lastEvent = event
+		}
+
+		if len(missedEvents) < replayBatchSize {
+			break
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, open := <-changes:
+			if !open {
+				// the service is shutting down or the client did not keep
+				// up. in both cases the client may resume using the last id
+				return
+			}
+			if !event.After(lastEvent) {
+				continue
+			}
+			if restriction.Permits(event.LegalDepartments, event.WaterAuthority) {
+				writeEvent(c, event)
+			}
+			lastEvent = event
+		case <-heartbeat.C:
+			_, _ = c.Writer.WriteString(": heartbeat\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeEvent(c *gin.Context, event v2.ChangeEvent) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatInt(event.ID, 10),
+		Event: event.Type,
+		Data:  event,
+	})
+}
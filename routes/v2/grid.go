@@ -0,0 +1,105 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/wisdom-oss/common-go/v3/types"
+
+	"microservice/internal/access"
+	"microservice/internal/store"
+)
+
+const (
+	// defaultCellSize is the edge length of the grid cells in meters if no
+	// other size has been requested.
+	defaultCellSize = 1000
+
+	// minimalCellSize and maximalCellSize limit the edge length of the cells
+	// to a meaningful resolution of the analysis.
+	minimalCellSize = 100
+	maximalCellSize = 100000
+)
+
+var (
+	errInvalidGrid = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Invalid Grid",
+		Detail: "The cell size needs to be between 100 and 100000 meters and the shape either 'square' or 'hex'",
+	}
+)
+
+// GridAnalysis returns the summed annual withdrawals per cell of a regular
+// grid as GeoJSON polygons. Only cells containing usage locations are
+// returned.
+// The Last-Modified header contains the time of the last refresh of the
+// precomputed withdrawals.
+func (r Routes) GridAnalysis(c *gin.Context) {
+	var queryParams struct {
+		CellSize *float64 `form:"cellSize"`
+		Shape    string   `form:"shape"`
+	}
+	if err := c.ShouldBindQuery(&queryParams); err != nil {
+		c.Abort()
+		errInvalidGrid.Emit(c)
+		return
+	}
+
+	grid := store.Grid{CellSize: defaultCellSize, Shape: store.GridSquare}
+	if queryParams.CellSize != nil {
+		grid.CellSize = *queryParams.CellSize
+	}
+	if queryParams.Shape != "" {
+		grid.Shape = queryParams.Shape
+	}
+
+	if grid.CellSize < minimalCellSize || grid.CellSize > maximalCellSize ||
+		(grid.Shape != store.GridSquare && grid.Shape != store.GridHexagon) {
+		c.Abort()
+		errInvalidGrid.Emit(c)
+		return
+	}
+
+	cells, err := r.Store.GridWithdrawals(c, grid, access.For(c))
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	freshness, err := r.Store.Freshness(c)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	featureCollection := geojson.FeatureCollection{
+		Features: make([]*geojson.Feature, 0, len(cells)),
+		BBox:     geom.NewBounds(geom.XY),
+	}
+	for _, cell := range cells {
+		feature := cell.ToFeature()
+		featureCollection.Features = append(featureCollection.Features, feature)
+		featureCollection.BBox.Extend(feature.Geometry)
+	}
+
+	// the bounds of an empty collection are infinite and can not be encoded
+	if len(featureCollection.Features) == 0 {
+		featureCollection.BBox = nil
+	}
+
+	encoded, err := featureCollection.MarshalJSON()
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Last-Modified", freshness.UTC().Format(http.TimeFormat))
+	c.JSON(http.StatusOK, json.RawMessage(encoded))
+}
@@ -0,0 +1,39 @@
+package v2
+
+import (
+	"net/http"
+	"testing"
+
+	"microservice/internal/store/storetest"
+)
+
+func TestGridAnalysis(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	for _, shape := range []string{"square", "hex"} {
+		t.Run(shape, func(t *testing.T) {
+			res := serve(m, storetest.Administrator, http.MethodGet, "/withdrawals/grid?cellSize=1000&shape="+shape, "")
+			if res.Code != http.StatusOK {
+				t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+			}
+			// the active usage locations are located in different cells
+			if cells := featureIDs(t, res.Body.Bytes()); len(cells) != 2 {
+				t.Errorf("expected two cells, got %v", cells)
+			}
+			if lastModified := res.Header().Get("Last-Modified"); lastModified != storetest.Freshness.Format(http.TimeFormat) {
+				t.Errorf("expected the freshness %s as Last-Modified header, got %q", storetest.Freshness, lastModified)
+			}
+		})
+	}
+}
+
+func TestGridAnalysisInvalidGrid(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	for _, query := range []string{"cellSize=10", "cellSize=1000000", "cellSize=large", "shape=triangle"} {
+		res := serve(m, storetest.Administrator, http.MethodGet, "/withdrawals/grid?"+query, "")
+		if res.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request for %q, got %d", query, res.Code)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/wisdom-oss/common-go/v3/types"
+
+	"microservice/internal/access"
+	"microservice/internal/redaction"
+	"microservice/internal/store"
+	v2 "microservice/types/v2"
+)
+
+// maximalImpactRadius limits the radius of an impact analysis in meters to
+// keep the number of analyzed usage locations manageable.
+const maximalImpactRadius = 50000
+
+var (
+	errInvalidImpactRequest = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Invalid Impact Analysis Request",
+		Detail: "The request needs a GeoJSON point in EPSG:4326, a positive radius of up to 50 km and the proposed rate",
+	}
+
+	errInvalidProposedRate = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Invalid Proposed Rate",
+		Detail: "The proposed rate needs a positive amount, a volume unit (e.g., m³ or l) and a non-empty interval",
+	}
+
+	errUnknownGroundwaterBody = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.21",
+		Status: http.StatusUnprocessableEntity,
+		Title:  "Unknown Groundwater Body",
+		Detail: "No usage location with a groundwater body is located within the radius. Please supply the groundwater body",
+	}
+)
+
+// ImpactAnalysis sums the annual withdrawals of the usage locations around a
+// planned withdrawal that use the same groundwater body and computes the
+// share the planned withdrawal would add.
+func (r Routes) ImpactAnalysis(c *gin.Context) {
+	var request v2.ImpactRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Abort()
+		errInvalidImpactRequest.Emit(c)
+		return
+	}
+
+	decoded, err := request.Point.Decode()
+	point, isPoint := decoded.(*geom.Point)
+	if err != nil || !isPoint || point.Empty() || !validCoordinates(point.X(), point.Y()) ||
+		request.Radius > maximalImpactRadius {
+		c.Abort()
+		errInvalidImpactRequest.Emit(c)
+		return
+	}
+
+	proposed := request.Rate.CubicMeterPerYear()
+	if proposed <= 0 {
+		c.Abort()
+		errInvalidProposedRate.Emit(c)
+		return
+	}
+
+	locations, err := r.Store.WithdrawalsWithin(c, store.Circle{
+		Longitude: point.X(),
+		Latitude:  point.Y(),
+		Radius:    request.Radius,
+	}, access.For(c))
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	// the locations are ordered by their distance and the first groundwater
+	// body is therefore the one of the closest usage location
+	groundwaterBody := request.GroundwaterBody
+	if groundwaterBody == nil {
+		for _, location := range locations {
+			if location.GroundwaterBody != nil {
+				groundwaterBody = location.GroundwaterBody
+				break
+			}
+		}
+	}
+	if groundwaterBody == nil {
+		c.Abort()
+		errUnknownGroundwaterBody.Emit(c)
+		return
+	}
+
+	freshness, err := r.Store.Freshness(c)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	analysis := v2.ImpactAnalysis{
+		Freshness:          freshness,
+		GroundwaterBody:    *groundwaterBody,
+		ProposedWithdrawal: proposed,
+	}
+
+	featureCollection := geojson.FeatureCollection{
+		Features: make([]*geojson.Feature, 0),
+		BBox:     geom.NewBounds(geom.XY),
+	}
+
+	redactor := redaction.For(c)
+	for _, location := range locations {
+		if location.GroundwaterBody == nil || *location.GroundwaterBody != *groundwaterBody {
+			continue
+		}
+
+		analysis.MinimalWithdrawal += location.MinimalWithdrawal
+		analysis.MaximalWithdrawal += location.MaximalWithdrawal
+
+		location.RedactPersonalData(redactor)
+		feature, err := location.ToFeature()
+		if err != nil {
+			c.Abort()
+			_ = c.Error(err)
+			return
+		}
+		feature.Properties["distance"] = location.Distance
+		feature.Properties["minimalWithdrawal"] = location.MinimalWithdrawal
+		feature.Properties["maximalWithdrawal"] = location.MaximalWithdrawal
+		featureCollection.Features = append(featureCollection.Features, feature)
+		featureCollection.BBox.Extend(location.Geometry)
+	}
+
+	analysis.MinimalShare = proposed / (proposed + analysis.MaximalWithdrawal)
+	analysis.MaximalShare = proposed / (proposed + analysis.MinimalWithdrawal)
+
+	// the bounds of an empty collection are infinite and can not be encoded
+	if len(featureCollection.Features) == 0 {
+		featureCollection.BBox = nil
+	}
+
+	analysis.UsageLocations, err = featureCollection.MarshalJSON()
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// validCoordinates reports if the longitude and latitude are within the range
+// of EPSG:4326.
+func validCoordinates(longitude, latitude float64) bool {
+	return longitude >= -180 && longitude <= 180 && latitude >= -90 && latitude <= 90
+}
@@ -0,0 +1,48 @@
+package v2
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"microservice/internal/store/storetest"
+)
+
+// impactRequest renders an impact analysis request for the point and radius.
+func impactRequest(point string, radius float64) string {
+	return fmt.Sprintf(`{"point":%s,"radius":%v,"groundwaterBody":"DEGB_DENI_4_2010",`+
+		`"rate":{"amount":50000,"unit":"m³","per":"P1Y"}}`, point, radius)
+}
+
+func TestImpactAnalysisBounds(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"valid", impactRequest(`{"type":"Point","coordinates":[7.9362,52.2536]}`, 1000), http.StatusOK},
+		{"maximal radius", impactRequest(`{"type":"Point","coordinates":[7.9362,52.2536]}`, 50000), http.StatusOK},
+		{"empty radius", impactRequest(`{"type":"Point","coordinates":[7.9362,52.2536]}`, 0), http.StatusBadRequest},
+		{"radius too large", impactRequest(`{"type":"Point","coordinates":[7.9362,52.2536]}`, 50001),
+			http.StatusBadRequest},
+		{"longitude out of range", impactRequest(`{"type":"Point","coordinates":[187.9362,52.2536]}`, 1000),
+			http.StatusBadRequest},
+		{"latitude out of range", impactRequest(`{"type":"Point","coordinates":[7.9362,-92.2536]}`, 1000),
+			http.StatusBadRequest},
+		{"projected coordinates", impactRequest(`{"type":"Point","coordinates":[426780,5790250]}`, 1000),
+			http.StatusBadRequest},
+		{"no point", impactRequest(`{"type":"LineString","coordinates":[[7.9,52.2],[8.0,52.3]]}`, 1000),
+			http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := serve(m, storetest.Administrator, http.MethodPost, "/analysis/impact", tt.body)
+			if res.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, res.Code, res.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package v2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/wisdom-oss/common-go/v3/types"
+
+	"microservice/internal/store"
+)
+
+// defaultLayerSRID is the reference system of uploaded layers that do not
+// name a reference system. It is the reference system required by RFC 7946.
+const defaultLayerSRID = 4326
+
+// MaximalLayerSize limits the size of an uploaded reference layer in bytes.
+const MaximalLayerSize = 64 << 20
+
+// layerName restricts the names of the reference layers to allow using them
+// in the groupBy parameter without escaping.
+var layerName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// crsName extracts the EPSG code from the names used in the crs member of
+// GeoJSON documents (e.g. "urn:ogc:def:crs:EPSG::25832" or "EPSG:25832").
+var crsName = regexp.MustCompile(`(?i)EPSG:(?:[0-9.]*:)?([0-9]+)$`)
+
+var (
+	errInvalidLayerName = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Invalid Reference Layer Name",
+		Detail: "The name of a reference layer may only contain lowercase letters, digits, dashes and underscores",
+	}
+
+	errInvalidLayer = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Invalid Reference Layer",
+		Detail: "The reference layer needs to be a GeoJSON FeatureCollection of polygons with unique keys",
+	}
+
+	errLayerTooLarge = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.14",
+		Status: http.StatusRequestEntityTooLarge,
+		Title:  "Reference Layer Too Large",
+		Detail: "The reference layer may not be larger than 64 MiB. Please simplify the polygons or split the layer",
+	}
+
+	errUnknownLayer = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.5",
+		Status: http.StatusNotFound,
+		Title:  "Unknown Reference Layer",
+		Detail: "The specified reference layer has not been uploaded",
+	}
+)
+
+// ReferenceLayers returns the uploaded reference layers.
+func (r Routes) ReferenceLayers(c *gin.Context) {
+	layers, err := r.Store.ReferenceLayers(c)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, layers)
+}
+
+// UploadReferenceLayer stores the polygons of a GeoJSON FeatureCollection as
+// reference layer and replaces an existing layer with the same name.
+//
+// The polygons are transformed from the reference system named in the crs
+// member of the collection or the srid parameter into the reference system of
+// the usage locations. The keys and names of the areas are read from the
+// properties named by the keyProperty and nameProperty parameters and default
+// to the feature ids and the "name" property.
+func (r Routes) UploadReferenceLayer(c *gin.Context) {
+	name := c.Param("name")
+	if !layerName.MatchString(name) {
+		c.Abort()
+		errInvalidLayerName.Emit(c)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaximalLayerSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.Abort()
+			errLayerTooLarge.Emit(c)
+			return
+		}
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	srid, areas, invalid := parseReferenceLayer(body, c.Query("srid"), c.Query("keyProperty"),
+		c.DefaultQuery("nameProperty", "name"))
+	if len(invalid) > 0 {
+		c.Abort()
+		serviceError := errInvalidLayer
+		serviceError.Errors = invalid
+		serviceError.Emit(c)
+		return
+	}
+
+	layer, err := r.Store.ReplaceReferenceLayer(c, name, srid, areas)
+	if errors.Is(err, store.ErrInvalidLayer) {
+		c.Abort()
+		serviceError := errInvalidLayer
+		serviceError.Errors = []error{err}
+		serviceError.Emit(c)
+		return
+	}
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, layer)
+}
+
+// DeleteReferenceLayer removes the reference layer.
+func (r Routes) DeleteReferenceLayer(c *gin.Context) {
+	err := r.Store.DeleteReferenceLayer(c, c.Param("name"))
+	if errors.Is(err, store.ErrUnknownLayer) {
+		c.Abort()
+		errUnknownLayer.Emit(c)
+		return
+	}
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseReferenceLayer reads the polygons of the feature collection and
+// returns an error for every invalid feature.
+func parseReferenceLayer(body []byte, srid, keyProperty, nameProperty string) (int, []store.ReferenceArea, []error) {
+	var collection geojson.FeatureCollection
+	if err := json.Unmarshal(body, &collection); err != nil {
+		return 0, nil, []error{err}
+	}
+	if len(collection.Features) == 0 {
+		return 0, nil, []error{errors.New("the feature collection does not contain any features")}
+	}
+
+	layerSRID, err := referenceSystem(body, srid)
+	if err != nil {
+		return 0, nil, []error{err}
+	}
+
+	var invalid []error
+	areas := make([]store.ReferenceArea, 0, len(collection.Features))
+	keys := make(map[string]bool, len(collection.Features))
+	for idx, feature := range collection.Features {
+		area := store.ReferenceArea{
+			Key:        feature.ID,
+			Properties: feature.Properties,
+		}
+		if keyProperty != "" {
+			area.Key = ""
+			if value, found := feature.Properties[keyProperty]; found && value != nil {
+				area.Key = fmt.Sprint(value)
+			}
+		}
+		if area.Key == "" {
+			area.Key = strconv.Itoa(idx + 1)
+		}
+		if value, ok := feature.Properties[nameProperty].(string); ok {
+			area.Name = &value
+		}
+
+		switch feature.Geometry.(type) {
+		case *geom.Polygon, *geom.MultiPolygon:
+			if !closedRings(feature.Geometry) {
+				invalid = append(invalid, fmt.Errorf("feature %d: the polygon contains rings that are not closed", idx))
+				continue
+			}
+			area.Geometry, err = geom.SetSRID(feature.Geometry, layerSRID)
+			if err != nil {
+				invalid = append(invalid, fmt.Errorf("feature %d: %w", idx, err))
+				continue
+			}
+		default:
+			invalid = append(invalid, fmt.Errorf("feature %d: the geometry is not a polygon", idx))
+			continue
+		}
+
+		if keys[area.Key] {
+			invalid = append(invalid, fmt.Errorf("feature %d: the key '%s' is used multiple times", idx, area.Key))
+			continue
+		}
+		keys[area.Key] = true
+
+		areas = append(areas, area)
+	}
+	return layerSRID, areas, invalid
+}
+
+// closedRings reports if all rings of the polygon or multipolygon contain at
+// least four coordinates and end at their first coordinate.
+func closedRings(g geom.T) bool {
+	var polygons []*geom.Polygon
+	switch polygon := g.(type) {
+	case *geom.Polygon:
+		polygons = append(polygons, polygon)
+	case *geom.MultiPolygon:
+		for idx := range polygon.NumPolygons() {
+			polygons = append(polygons, polygon.Polygon(idx))
+		}
+	}
+
+	for _, polygon := range polygons {
+		if polygon.NumLinearRings() == 0 {
+			return false
+		}
+		for idx := range polygon.NumLinearRings() {
+			ring := polygon.LinearRing(idx)
+			if ring.NumCoords() < 4 || !ring.Coord(0).Equal(ring.Layout(), ring.Coord(ring.NumCoords()-1)) { //nolint:mnd
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// referenceSystem returns the EPSG code of the reference system the layer
+// uses. The srid parameter takes precedence over the crs member of the
+// document.
+func referenceSystem(body []byte, srid string) (int, error) {
+	if srid != "" {
+		code, err := strconv.Atoi(srid)
+		if err != nil || code <= 0 {
+			return 0, fmt.Errorf("invalid srid '%s'", srid)
+		}
+		return code, nil
+	}
+
+	var document struct {
+		CRS *geojson.CRS `json:"crs"`
+	}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return 0, err
+	}
+	if document.CRS == nil {
+		return defaultLayerSRID, nil
+	}
+
+	name, _ := document.CRS.Properties["name"].(string)
+	match := crsName.FindStringSubmatch(strings.TrimSpace(name))
+	if match == nil {
+		if strings.EqualFold(strings.TrimSpace(name), "urn:ogc:def:crs:OGC:1.3:CRS84") {
+			return defaultLayerSRID, nil
+		}
+		return 0, fmt.Errorf("unsupported reference system '%s'", name)
+	}
+	return strconv.Atoi(match[1])
+}
@@ -0,0 +1,124 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"microservice/internal/store/storetest"
+	v2 "microservice/types/v2"
+)
+
+// zoneFeature is a square around the usage locations 10 and 11 using
+// EPSG:25832.
+const zoneFeature = `{"type":"Feature","id":"zone-1","properties":{"name":"Gaste"},"geometry":{"type":"Polygon",` +
+	`"coordinates":[[[426000,5789000],[428000,5789000],[428000,5791000],[426000,5791000],[426000,5789000]]]}}`
+
+// layerCollection returns a feature collection of the features using the
+// reference system.
+func layerCollection(crs string, features ...string) string {
+	return `{"type":"FeatureCollection","crs":{"type":"name","properties":{"name":"` + crs + `"}},` +
+		`"features":[` + strings.Join(features, ",") + `]}`
+}
+
+var protectionZone = layerCollection("urn:ogc:def:crs:EPSG::25832", zoneFeature)
+
+func TestUploadReferenceLayer(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	res := serve(m, storetest.Administrator, http.MethodPut, "/layers/protection-zones", protectionZone)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+	}
+	var layer v2.ReferenceLayer
+	if err := json.Unmarshal(res.Body.Bytes(), &layer); err != nil {
+		t.Fatal(err)
+	}
+	if layer.Name != "protection-zones" || layer.SourceSRID != 25832 || layer.Areas != 1 {
+		t.Errorf("unexpected layer %+v", layer)
+	}
+
+	res = serve(m, storetest.Administrator, http.MethodGet, "/layers", "")
+	var layers []v2.ReferenceLayer
+	if err := json.Unmarshal(res.Body.Bytes(), &layers); err != nil {
+		t.Fatal(err)
+	}
+	if len(layers) != 1 || layers[0].Name != "protection-zones" {
+		t.Errorf("expected the uploaded layer to be listed, got %+v", layers)
+	}
+
+	res = serve(m, storetest.Administrator, http.MethodGet, "/withdrawals?groupBy=layer:protection-zones", "")
+	var summary v2.WithdrawalSummary
+	if err := json.Unmarshal(res.Body.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Groups) != 1 || summary.Groups[0].Key != "zone-1" || summary.Groups[0].UsageLocations != 1 {
+		t.Errorf("expected the active usage location within the zone, got %+v", summary.Groups)
+	}
+}
+
+func TestUploadReferenceLayerElevation(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	body := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Polygon",` +
+		`"coordinates":[[[7.9,52.2,50],[8.0,52.2,50],[8.0,52.3,50],[7.9,52.3,50],[7.9,52.2,50]]]}}]}`
+	res := serve(m, storetest.Administrator, http.MethodPut, "/layers/elevated", body)
+	if res.Code != http.StatusOK {
+		t.Errorf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+	}
+}
+
+func TestUploadInvalidReferenceLayer(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	tests := []struct {
+		name   string
+		layer  string
+		body   string
+		status int
+	}{
+		{"invalid name", "Protection_Zones", protectionZone, http.StatusBadRequest},
+		{"no collection", "zones", `{"type":"Feature"}`, http.StatusBadRequest},
+		{"no polygon", "zones", `{"type":"FeatureCollection","features":[{"type":"Feature",` +
+			`"geometry":{"type":"Point","coordinates":[7.9,52.2]}}]}`, http.StatusBadRequest},
+		{"open ring", "zones", `{"type":"FeatureCollection","features":[{"type":"Feature",` +
+			`"geometry":{"type":"Polygon","coordinates":[[[7.9,52.2],[8.0,52.2],[8.0,52.3]]]}}]}`,
+			http.StatusBadRequest},
+		{"unknown reference system", "zones", layerCollection("urn:ogc:def:crs:OGC::WGS72", zoneFeature),
+			http.StatusBadRequest},
+		{"duplicate keys", "zones", layerCollection("EPSG:25832", zoneFeature, zoneFeature), http.StatusBadRequest},
+		{"too large", "zones", protectionZone + strings.Repeat(" ", MaximalLayerSize), http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := serve(m, storetest.Administrator, http.MethodPut, "/layers/"+tt.layer, tt.body)
+			if res.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %.300s", tt.status, res.Code, res.Body.String())
+			}
+		})
+	}
+
+	res := serve(m, storetest.Administrator, http.MethodGet, "/layers", "")
+	if body := strings.TrimSpace(res.Body.String()); body != "[]" {
+		t.Errorf("expected no layers to be stored, got %s", body)
+	}
+}
+
+func TestDeleteReferenceLayer(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	res := serve(m, storetest.Administrator, http.MethodPut, "/layers/protection-zones", protectionZone)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+	}
+	res = serve(m, storetest.Administrator, http.MethodDelete, "/layers/protection-zones", "")
+	if res.Code != http.StatusNoContent {
+		t.Errorf("expected 204 No Content, got %d %s", res.Code, res.Body.String())
+	}
+	res = serve(m, storetest.Administrator, http.MethodDelete, "/layers/protection-zones", "")
+	if res.Code != http.StatusNotFound {
+		t.Errorf("expected 404 Not Found, got %d %s", res.Code, res.Body.String())
+	}
+}
@@ -6,32 +6,52 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/gin-gonic/gin"
 	"github.com/twpayne/go-geom"
 	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/wisdom-oss/common-go/v3/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
-	"microservice/internal/db"
+	"microservice/internal/access"
+	"microservice/internal/redaction"
+	"microservice/internal/tracing"
 	v2 "microservice/types/v2"
 )
 
-func UsageLocations(c *gin.Context) {
+var (
+	errInvalidUsageLocationQuery = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Invalid Usage Location Query",
+		Detail: "The 'active', 'virtual' and 'cluster' parameters need to be booleans and the 'zoom' parameter an integer",
+	}
+)
+
+// UsageLocations returns the usage locations as GeoJSON points.
+// If clustering has been requested, usage locations that would overlap on a
+// map at the requested zoom level are combined into a single cluster feature.
+func (r Routes) UsageLocations(c *gin.Context) {
 	var queryParams struct {
 		MunicipalityPrefixes []string `form:"in"`
 		Active               *bool    `form:"active"`
 		Virtual              *bool    `form:"virtual"`
+		Cluster              bool     `form:"cluster"`
+		Zoom                 *int     `form:"zoom"`
+	}
+	if err := c.ShouldBindQuery(&queryParams); err != nil {
+		c.Abort()
+		errInvalidUsageLocationQuery.Emit(c)
+		return
 	}
-	_ = c.ShouldBindQuery(&queryParams)
 
-	query, err := db.Queries.Raw("get-locations")
-	if err != nil {
+	if queryParams.Cluster && (queryParams.Zoom == nil || *queryParams.Zoom < 0 || *queryParams.Zoom > maximalZoom) {
 		c.Abort()
-		_ = c.Error(err)
+		errInvalidClusterQuery.Emit(c)
 		return
 	}
 
-	var locations []v2.UsageLocation
-	err = pgxscan.Select(c, db.Pool(), &locations, query)
+	locations, err := r.Store.UsageLocations(c, access.For(c))
 	if err != nil {
 		c.Abort()
 		_ = c.Error(err)
@@ -60,7 +80,7 @@ locations:
 		}
 
 		if queryParams.Active != nil {
-			if location.Active != queryParams.Active {
+			if location.Active == nil || *location.Active != *queryParams.Active {
 				continue
 			}
 		}
@@ -80,13 +100,46 @@ output:
 		BBox:     geom.NewBounds(geom.XY),
 	}
 
-	for _, loc := range filteredLocations {
-		feature, _ := loc.ToFeature()
-		featureCollection.Features = append(featureCollection.Features, feature)
-		featureCollection.BBox.Extend(loc.Geometry)
+	_, span := tracing.Tracer.Start(c.Request.Context(), "usage-locations.to-features",
+		trace.WithAttributes(attribute.Int("features", len(filteredLocations))))
+	redactor := redaction.For(c)
+	if queryParams.Cluster {
+		for _, cluster := range clusterUsageLocations(filteredLocations, *queryParams.Zoom) {
+			if len(cluster.UsageLocations) > 1 {
+				featureCollection.Features = append(featureCollection.Features, cluster.ToFeature())
+				for _, loc := range cluster.UsageLocations {
+					featureCollection.BBox.Extend(loc.Geometry)
+				}
+				continue
+			}
+
+			loc := cluster.UsageLocations[0]
+			loc.RedactPersonalData(redactor)
+			feature, _ := loc.ToFeature()
+			featureCollection.Features = append(featureCollection.Features, feature)
+			featureCollection.BBox.Extend(loc.Geometry)
+		}
+	} else {
+		for _, loc := range filteredLocations {
+			loc.RedactPersonalData(redactor)
+			feature, _ := loc.ToFeature()
+			featureCollection.Features = append(featureCollection.Features, feature)
+			if feature.Geometry != nil {
+				featureCollection.BBox.Extend(feature.Geometry)
+			}
+		}
+	}
+	span.End()
+
+	// the bounds of a collection without geometries are infinite and can not
+	// be encoded
+	if featureCollection.BBox.IsEmpty() {
+		featureCollection.BBox = nil
 	}
 
+	_, span = tracing.Tracer.Start(c.Request.Context(), "usage-locations.marshal")
 	encoded, _ := featureCollection.MarshalJSON()
+	span.End()
 
 	c.JSON(http.StatusAccepted, json.RawMessage(encoded))
 }
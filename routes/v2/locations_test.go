@@ -0,0 +1,77 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"microservice/internal/store/storetest"
+)
+
+// featureIDs returns the sorted ids of the features of a collection.
+func featureIDs(t *testing.T, body []byte) []string {
+	t.Helper()
+
+	var collection struct {
+		Features []struct {
+			ID string `json:"id"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(body, &collection); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make([]string, 0, len(collection.Features))
+	for _, feature := range collection.Features {
+		ids = append(ids, feature.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestUsageLocations(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	tests := []struct {
+		name     string
+		as       storetest.Caller
+		query    string
+		policy   bool
+		expected []string
+	}{
+		{name: "all", as: storetest.Administrator, expected: []string{"10", "11", "12"}},
+		{name: "active", as: storetest.Administrator, query: "?active=true", expected: []string{"10", "12"}},
+		{name: "virtual", as: storetest.Administrator, query: "?virtual=true", expected: []string{"11"}},
+		{name: "municipality", as: storetest.Administrator, query: "?in=03404", expected: []string{"12"}},
+		{name: "restricted", as: storetest.DepartmentB, policy: true, expected: []string{"12"}},
+		{name: "without rule", as: storetest.Anonymous, policy: true, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.policy {
+				storetest.EnableAccessPolicy(t)
+			}
+
+			res := serve(m, tt.as, http.MethodGet, "/usage-locations"+tt.query, "")
+			if res.Code != http.StatusAccepted {
+				t.Fatalf("expected 202 Accepted, got %d %s", res.Code, res.Body.String())
+			}
+			if ids := featureIDs(t, res.Body.Bytes()); !slices.Equal(ids, tt.expected) {
+				t.Errorf("expected the usage locations %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestUsageLocationsInvalidQuery(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	for _, query := range []string{"active=yes", "virtual=1.5", "cluster=true", "cluster=true&zoom=25", "cluster=true&zoom=near"} {
+		res := serve(m, storetest.Administrator, http.MethodGet, "/usage-locations?"+query, "")
+		if res.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request for %q, got %d", query, res.Code)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/wisdom-oss/common-go/v3/types"
+
+	"microservice/internal/access"
+	"microservice/internal/redaction"
+	"microservice/internal/store"
+)
+
+const (
+	// defaultNeighbours is the number of usage locations returned if the
+	// request does not specify the number.
+	defaultNeighbours = 10
+
+	// maximalNeighbours limits the number of usage locations returned by a
+	// single request.
+	maximalNeighbours = 1000
+)
+
+var (
+	errInvalidNeighbourQuery = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Invalid Nearest Neighbour Query",
+		Detail: "The query requires the coordinates of a point using the 'lon' and 'lat' parameters",
+	}
+
+	errTooManyNeighbours = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Too Many Neighbours Requested",
+		Detail: "The 'k' parameter may not request more than 1000 usage locations per page",
+	}
+)
+
+// NearestUsageLocations returns the usage locations closest to the requested
+// point ordered by their distance.
+// If more usage locations are available, the response contains a link to the
+// next page in the Link header.
+func (r Routes) NearestUsageLocations(c *gin.Context) {
+	var queryParams struct {
+		Longitude        *float64 `form:"lon"`
+		Latitude         *float64 `form:"lat"`
+		K                int      `form:"k"`
+		MaxDistance      *float64 `form:"maxDistance"`
+		Offset           int      `form:"offset"`
+		Active           *bool    `form:"active"`
+		Virtual          *bool    `form:"virtual"`
+		LegalDepartments []string `form:"legalDepartment"`
+	}
+	if err := c.ShouldBindQuery(&queryParams); err != nil || queryParams.Longitude == nil || queryParams.Latitude == nil {
+		c.Abort()
+		errInvalidNeighbourQuery.Emit(c)
+		return
+	}
+
+	k := queryParams.K
+	if k <= 0 {
+		k = defaultNeighbours
+	}
+	if k > maximalNeighbours {
+		c.Abort()
+		errTooManyNeighbours.Emit(c)
+		return
+	}
+	offset := max(queryParams.Offset, 0)
+
+	// an additional usage location is requested to detect if another page
+	// exists
+	neighbours, err := r.Store.NearestUsageLocations(c, store.NeighbourQuery{
+		Longitude:        *queryParams.Longitude,
+		Latitude:         *queryParams.Latitude,
+		MaxDistance:      queryParams.MaxDistance,
+		Active:           queryParams.Active,
+		Virtual:          queryParams.Virtual,
+		LegalDepartments: queryParams.LegalDepartments,
+		Offset:           offset,
+		Limit:            k + 1,
+	}, access.For(c))
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	if len(neighbours) > k {
+		neighbours = neighbours[:k]
+
+		next := c.Request.URL.Query()
+		next.Set("offset", strconv.Itoa(offset+k))
+		c.Header("Link", `<`+c.Request.URL.Path+`?`+next.Encode()+`>; rel="next"`)
+	}
+
+	featureCollection := geojson.FeatureCollection{
+		Features: make([]*geojson.Feature, 0, len(neighbours)),
+		BBox:     geom.NewBounds(geom.XY),
+	}
+
+	redactor := redaction.For(c)
+	for _, neighbour := range neighbours {
+		neighbour.RedactPersonalData(redactor)
+		feature, err := neighbour.ToFeature()
+		if err != nil {
+			c.Abort()
+			_ = c.Error(err)
+			return
+		}
+		feature.Properties["distance"] = neighbour.Distance
+		featureCollection.Features = append(featureCollection.Features, feature)
+		featureCollection.BBox.Extend(neighbour.Geometry)
+	}
+
+	// the bounds of an empty collection are infinite and can not be encoded
+	if len(featureCollection.Features) == 0 {
+		featureCollection.BBox = nil
+	}
+
+	encoded, err := featureCollection.MarshalJSON()
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, json.RawMessage(encoded))
+}
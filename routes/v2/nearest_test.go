@@ -0,0 +1,104 @@
+package v2
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"microservice/internal/store/storetest"
+	v2 "microservice/types/v2"
+)
+
+// The coordinates of the well of the water right 4711 in EPSG:4326.
+const nearWell = "lon=7.9362&lat=52.2536"
+
+func TestNearestUsageLocations(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	// the usage locations of the retired version are not searched
+	err := m.AddUsageLocation(v2.UsageLocation{
+		ID: 13, WaterRightID: 1, Active: storetest.Ptr(true), Real: storetest.Ptr(true), LegalDepartment: storetest.Ptr("E"),
+		Geometry: storetest.Point(426780, 5790250),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+		next     string
+	}{
+		{name: "closest", query: nearWell + "&k=1", expected: []string{"10"}, next: "1"},
+		{name: "next page", query: nearWell + "&k=1&offset=1", expected: []string{"11"}, next: "2"},
+		{name: "last page", query: nearWell + "&k=2&offset=1", expected: []string{"11", "12"}},
+		{name: "inactive", query: nearWell + "&active=false", expected: []string{"11"}},
+		{name: "legal department", query: nearWell + "&legalDepartment=B", expected: []string{"12"}},
+		{name: "maximal distance", query: nearWell + "&maxDistance=1000", expected: []string{"10", "11"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := serve(m, storetest.Administrator, http.MethodGet, "/usage-locations/nearest?"+tt.query, "")
+			if res.Code != http.StatusOK {
+				t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+			}
+			if ids := featureIDs(t, res.Body.Bytes()); !slices.Equal(ids, tt.expected) {
+				t.Errorf("expected the usage locations %v, got %v", tt.expected, ids)
+			}
+
+			if offset := nextOffset(t, res.Header().Get("Link")); offset != tt.next {
+				t.Errorf("expected the next page at offset %q, got %q", tt.next, offset)
+			}
+		})
+	}
+}
+
+func TestNearestUsageLocationsRetiredWaterRight(t *testing.T) {
+	m := storetest.NewStore(t)
+	m.RetireWaterRight(4712)
+
+	res := serve(m, storetest.Administrator, http.MethodGet, "/usage-locations/nearest?"+nearWell, "")
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+	}
+	if ids := featureIDs(t, res.Body.Bytes()); !slices.Equal(ids, []string{"10", "11"}) {
+		t.Errorf("expected the usage locations of the retired water right to be excluded, got %v", ids)
+	}
+}
+
+func TestNearestUsageLocationsInvalidQuery(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	for _, query := range []string{"", "lon=7.9362", "lon=east&lat=52.2536", nearWell + "&k=1001"} {
+		res := serve(m, storetest.Administrator, http.MethodGet, "/usage-locations/nearest?"+query, "")
+		if res.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request for %q, got %d", query, res.Code)
+		}
+	}
+}
+
+// nextOffset returns the offset of the next page referenced by the Link
+// header.
+func nextOffset(t *testing.T, link string) string {
+	t.Helper()
+
+	if link == "" {
+		return ""
+	}
+	target, found := strings.CutPrefix(strings.TrimSuffix(link, `>; rel="next"`), "<")
+	if !found {
+		t.Fatalf("unexpected Link header %q", link)
+	}
+	next, err := url.Parse(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Path != "/usage-locations/nearest" {
+		t.Errorf("expected the next page to use the requested path, got %q", next.Path)
+	}
+	return next.Query().Get("offset")
+}
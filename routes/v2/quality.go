@@ -0,0 +1,169 @@
+package v2
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wisdom-oss/common-go/v3/types"
+
+	"microservice/internal/access"
+	"microservice/internal/configuration"
+	"microservice/internal/quality"
+	"microservice/internal/store"
+	v2 "microservice/types/v2"
+)
+
+// The formats of the issue list.
+const (
+	issueFormatCSV  = "csv"
+	issueFormatJSON = "json"
+)
+
+var (
+	errInvalidIssueFormat = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Invalid Issue List Format",
+		Detail: "The issue list is available as 'csv' or 'json'",
+	}
+)
+
+// LocationQuality applies the plausibility checks to the usage locations of
+// the current water rights and returns the issues found together with the
+// number of issues per check.
+// The checks are configured using the quality section of the configuration.
+func (r Routes) LocationQuality(c *gin.Context) {
+	config := configuration.Default.Viper()
+	checks := store.LocationChecks{
+		Extent: [4]float64{
+			config.GetFloat64(configuration.ConfigurationKey_QualityExtentMinLongitude),
+			config.GetFloat64(configuration.ConfigurationKey_QualityExtentMinLatitude),
+			config.GetFloat64(configuration.ConfigurationKey_QualityExtentMaxLongitude),
+			config.GetFloat64(configuration.ConfigurationKey_QualityExtentMaxLatitude),
+		},
+		MunicipalityLayer: config.GetString(configuration.ConfigurationKey_QualityMunicipalityLayer),
+		DuplicateDistance: config.GetFloat64(configuration.ConfigurationKey_QualityDuplicateDistance),
+	}
+
+	issues, err := r.Store.LocationIssues(c, checks, access.For(c))
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	report := v2.LocationQualityReport{
+		Extent:            checks.Extent,
+		MunicipalityLayer: checks.MunicipalityLayer,
+		DuplicateDistance: checks.DuplicateDistance,
+		Summary: map[string]int{
+			v2.CheckMissingGeometry:      0,
+			v2.CheckInvalidGeometry:      0,
+			v2.CheckOutsideExtent:        0,
+			v2.CheckMunicipalityMismatch: 0,
+			v2.CheckDuplicateLocation:    0,
+		},
+		Issues: issues,
+	}
+	for _, issue := range issues {
+		report.Summary[issue.Check]++
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// WaterRightQuality rates the attributes of every water right and its usage
+// locations using the configured rules.
+func (r Routes) WaterRightQuality(c *gin.Context) {
+	rules := quality.Rules()
+	scores, _, err := r.evaluateWaterRights(c, rules)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	report := v2.WaterRightQualityReport{
+		Rules:  make([]v2.QualityRule, len(rules)),
+		Scores: scores,
+	}
+	for idx, rule := range rules {
+		report.Rules[idx] = rule.QualityRule
+	}
+	if len(scores) > 0 {
+		var sum float64
+		for _, score := range scores {
+			sum += score.Score
+		}
+		average := sum / float64(len(scores))
+		report.AverageScore = &average
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// WaterRightIssues returns the issues found by the configured rules. The
+// issues are downloaded as csv file unless json has been requested.
+func (r Routes) WaterRightIssues(c *gin.Context) {
+	format := c.DefaultQuery("format", issueFormatCSV)
+	if format != issueFormatCSV && format != issueFormatJSON {
+		c.Abort()
+		errInvalidIssueFormat.Emit(c)
+		return
+	}
+
+	_, issues, err := r.evaluateWaterRights(c, quality.Rules())
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	if format == issueFormatJSON {
+		c.JSON(http.StatusOK, issues)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="water-right-issues.csv"`)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	_ = writer.Write([]string{"water_right", "water_right_number", "usage_location", "rule", "detail"})
+	for _, issue := range issues {
+		var location string
+		if issue.UsageLocation != nil {
+			location = strconv.Itoa(*issue.UsageLocation)
+		}
+		_ = writer.Write([]string{
+			strconv.FormatUint(issue.WaterRight, 10),
+			strconv.FormatUint(issue.WaterRightNumber, 10),
+			location,
+			issue.Rule,
+			issue.Detail,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// evaluateWaterRights applies the rules to the water rights and usage
+// locations visible to the caller.
+func (r Routes) evaluateWaterRights(c *gin.Context, rules []quality.Rule) ([]v2.WaterRightScore, []v2.WaterRightIssue, error) { //nolint:lll
+	restriction := access.For(c)
+	waterRights, err := r.Store.WaterRights(c, restriction)
+	if err != nil {
+		return nil, nil, err
+	}
+	locations, err := r.Store.UsageLocations(c, restriction)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scores, issues := quality.Evaluate(rules, waterRights, locations)
+	return scores, issues, nil
+}
@@ -0,0 +1,49 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"microservice/internal/store/storetest"
+	v2 "microservice/types/v2"
+)
+
+func TestLocationQualityChecksCurrentWaterRights(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	locations := []v2.UsageLocation{
+		// the retired version of the water right 4711 shares the point of
+		// the well and has a usage location without a geometry
+		{
+			ID: 13, WaterRightID: 1, Active: storetest.Ptr(true), LegalDepartment: storetest.Ptr("E"),
+			Geometry: storetest.Point(426780, 5790250),
+		},
+		{ID: 14, WaterRightID: 1, Active: storetest.Ptr(true), LegalDepartment: storetest.Ptr("E")},
+		{ID: 15, WaterRightID: 2, Active: storetest.Ptr(true), LegalDepartment: storetest.Ptr("E")},
+	}
+	for _, location := range locations {
+		if err := m.AddUsageLocation(location); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res := serve(m, storetest.Administrator, http.MethodGet, "/quality/locations", "")
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+	}
+
+	var report v2.LocationQualityReport
+	if err := json.Unmarshal(res.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Issues) != 1 {
+		t.Fatalf("expected only the issue of the current version, got %+v", report.Issues)
+	}
+	if issue := report.Issues[0]; issue.UsageLocation != 15 || issue.Check != v2.CheckMissingGeometry {
+		t.Errorf("expected the missing geometry of the usage location 15, got %+v", issue)
+	}
+	if report.Summary[v2.CheckDuplicateLocation] != 0 {
+		t.Errorf("expected the point of the retired version not to duplicate the well, got %v", report.Summary)
+	}
+}
@@ -0,0 +1,85 @@
+package v2
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"microservice/internal/quality"
+	"microservice/internal/redaction"
+	"microservice/internal/store/storetest"
+)
+
+func TestRedaction(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	outputs := []struct {
+		name     string
+		target   string
+		status   int
+		contains string
+		personal string // personal data contained in the unredacted output
+	}{
+		{
+			name: "json", target: "/water-rights/4711", status: http.StatusOK,
+			contains: `"holder"`, personal: "Erika Mustermann",
+		},
+		{
+			name: "geojson", target: "/usage-locations", status: http.StatusAccepted,
+			contains: `"plot"`, personal: "Flur 3",
+		},
+		// the issues only describe the land record without including its
+		// values
+		{
+			name: "csv", target: "/quality/water-rights/issues?format=csv", status: http.StatusOK,
+			contains: quality.RuleLandRecordExclusivity,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		as         storetest.Caller
+		mode       string
+		personal   bool
+		pseudonyms bool
+	}{
+		{name: "disabled", as: storetest.Anonymous, personal: true},
+		{name: "null", as: storetest.Anonymous, mode: redaction.ModeNull},
+		{name: "pseudonymize", as: storetest.Anonymous, mode: redaction.ModePseudonymize, pseudonyms: true},
+		{name: "personal data scope", as: storetest.PersonalData, mode: redaction.ModeNull, personal: true},
+		{name: "administrator", as: storetest.Administrator, mode: redaction.ModeNull, personal: true},
+	}
+
+	for _, output := range outputs {
+		for _, tt := range tests {
+			t.Run(output.name+"/"+tt.name, func(t *testing.T) {
+				if tt.mode != "" {
+					storetest.EnableRedaction(t, tt.mode)
+				}
+
+				res := serve(m, tt.as, http.MethodGet, output.target, "")
+				if res.Code != output.status {
+					t.Fatalf("expected %d, got %d %s", output.status, res.Code, res.Body.String())
+				}
+
+				body := res.Body.String()
+				if !strings.Contains(body, output.contains) {
+					t.Fatalf("expected the output to contain %s, got %s", output.contains, body)
+				}
+				if tt.personal && !strings.Contains(body, output.personal) {
+					t.Errorf("expected the personal data to be returned, got %s", body)
+				}
+				if !tt.personal {
+					for _, value := range storetest.PersonalValues {
+						if strings.Contains(body, value) {
+							t.Errorf("expected %q to be redacted, got %s", value, body)
+						}
+					}
+				}
+				if tt.pseudonyms && output.personal != "" && !strings.Contains(body, "pseudonym:") {
+					t.Errorf("expected the personal data to be pseudonymized, got %s", body)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package v2
+
+import "microservice/internal/store"
+
+// Routes contains the handlers of the second api version that read the water
+// rights and usage locations.
+type Routes struct {
+	// Store is used to read the water rights and usage locations
+	Store store.WaterRightStore
+}
@@ -0,0 +1,34 @@
+package v2
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"microservice/internal/store"
+	"microservice/internal/store/storetest"
+)
+
+func TestMain(m *testing.M) {
+	storetest.Main(m)
+}
+
+// serve executes the request against the handlers of the routes using the
+// supplied store.
+func serve(m *store.Memory, as storetest.Caller, method, target, body string) *httptest.ResponseRecorder {
+	routes := Routes{Store: storetest.Store{Memory: m}}
+	return storetest.Serve(as, func(r gin.IRoutes) {
+		r.GET("/usage-locations", routes.UsageLocations)
+		r.GET("/usage-locations/nearest", routes.NearestUsageLocations)
+		r.GET("/water-rights/:id", routes.WaterRightDetails)
+		r.GET("/withdrawals", routes.Withdrawals)
+		r.GET("/withdrawals/grid", routes.GridAnalysis)
+		r.POST("/analysis/impact", routes.ImpactAnalysis)
+		r.GET("/quality/locations", routes.LocationQuality)
+		r.GET("/quality/water-rights/issues", routes.WaterRightIssues)
+		r.GET("/layers", routes.ReferenceLayers)
+		r.PUT("/layers/:name", routes.UploadReferenceLayer)
+		r.DELETE("/layers/:name", routes.DeleteReferenceLayer)
+	}, method, target, body)
+}
@@ -1,15 +1,20 @@
 package v2
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
-	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/gin-gonic/gin"
 	"github.com/wisdom-oss/common-go/v3/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
-	"microservice/internal/db"
-	v2 "microservice/types/v2"
+	"microservice/internal/access"
+	"microservice/internal/redaction"
+	"microservice/internal/store"
+	"microservice/internal/tracing"
 )
 
 var (
@@ -28,7 +33,7 @@ var (
 	}
 )
 
-func WaterRightDetails(c *gin.Context) {
+func (r Routes) WaterRightDetails(c *gin.Context) {
 	waterRightID := strings.TrimSpace(c.Param("id"))
 	if waterRightID == "" {
 		c.Abort()
@@ -36,19 +41,15 @@ func WaterRightDetails(c *gin.Context) {
 		return
 	}
 
-	query, err := db.Queries.Raw("v2_get-water-right")
-	if err != nil {
-		c.Abort()
-		_ = c.Error(err)
-		return
-	}
+	// water rights hidden by the access policy are reported as unknown to not
+	// reveal their existence
+	restriction := access.For(c)
 
-	var waterRight v2.WaterRight
-	err = pgxscan.Get(c, db.Pool(), &waterRight, query, waterRightID)
+	waterRight, err := r.Store.WaterRight(c, waterRightID, restriction)
 	if err != nil {
 		c.Abort()
 
-		if pgxscan.NotFound(err) {
+		if errors.Is(err, store.ErrNotFound) {
 			errUnknownWaterRight.Emit(c)
 			return
 		}
@@ -57,28 +58,27 @@ func WaterRightDetails(c *gin.Context) {
 		return
 	}
 
-	query, err = db.Queries.Raw("v2_get-water-right-usage-locations")
+	locations, err := r.Store.WaterRightUsageLocations(c, waterRight.Identifiers.Database, restriction)
 	if err != nil {
 		c.Abort()
 		_ = c.Error(err)
 		return
 	}
 
-	var locations []v2.UsageLocation
-	err = pgxscan.Select(c, db.Pool(), &locations, query, waterRight.Identifiers.Database)
+	waterRight.AssociatedUsageLocations = locations
+	waterRight.RedactPersonalData(redaction.For(c))
+
+	// the usage locations are reprojected and converted into features while
+	// marshalling the water right
+	_, span := tracing.Tracer.Start(c.Request.Context(), "water-right.marshal",
+		trace.WithAttributes(attribute.Int("features", len(waterRight.AssociatedUsageLocations))))
+	encoded, err := json.Marshal(waterRight)
+	span.End()
 	if err != nil {
 		c.Abort()
-
-		if pgxscan.NotFound(err) {
-			waterRight.AssociatedUsageLocations = nil
-			goto output
-		}
-
 		_ = c.Error(err)
 		return
 	}
 
-output:
-	waterRight.AssociatedUsageLocations = locations
-	c.JSON(http.StatusOK, waterRight)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", encoded)
 }
@@ -0,0 +1,56 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"microservice/internal/store/storetest"
+)
+
+func TestWaterRightDetails(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	res := serve(m, storetest.Administrator, http.MethodGet, "/water-rights/4711", "")
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+	}
+
+	var waterRight struct {
+		Identifiers struct {
+			Database uint64 `json:"database"`
+		} `json:"identifiers"`
+		UsageLocations struct {
+			Features []json.RawMessage `json:"features"`
+		} `json:"usageLocations"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &waterRight); err != nil {
+		t.Fatal(err)
+	}
+	if waterRight.Identifiers.Database != 2 {
+		t.Errorf("expected the current version 2 of the water right, got %d", waterRight.Identifiers.Database)
+	}
+	if len(waterRight.UsageLocations.Features) != 2 {
+		t.Errorf("expected the two usage locations of the water right, got %d", len(waterRight.UsageLocations.Features))
+	}
+}
+
+func TestWaterRightDetailsNotFound(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	t.Run("unknown", func(t *testing.T) {
+		res := serve(m, storetest.Administrator, http.MethodGet, "/water-rights/9999", "")
+		if res.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", res.Code)
+		}
+	})
+
+	t.Run("hidden", func(t *testing.T) {
+		storetest.EnableAccessPolicy(t)
+
+		res := serve(m, storetest.DepartmentB, http.MethodGet, "/water-rights/4711", "")
+		if res.Code != http.StatusNotFound {
+			t.Errorf("expected the hidden water right to be reported as unknown, got %d", res.Code)
+		}
+	})
+}
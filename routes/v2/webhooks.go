@@ -0,0 +1,215 @@
+package v2
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/gin-gonic/gin"
+	"github.com/thanhpk/randstr"
+	"github.com/wisdom-oss/common-go/v3/types"
+
+	"microservice/internal/db"
+	v2 "microservice/types/v2"
+)
+
+// secretLength determines how long the generated webhook secrets are.
+const secretLength = 48
+
+// defaultDeliveryLimit limits the number of deliveries returned by the
+// delivery log if no other limit has been requested.
+const defaultDeliveryLimit = 100
+
+var (
+	errInvalidSubscription = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Invalid Webhook Subscription",
+		Detail: "The subscription needs an absolute http(s) url and may only filter for known event types",
+	}
+
+	errInvalidSubscriptionID = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Invalid Webhook Subscription ID",
+		Detail: "The webhook subscription id needs to be a number",
+	}
+
+	errInvalidDeliveryLimit = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Invalid Delivery Limit",
+		Detail: "The number of deliveries requested using the 'limit' parameter needs to be an integer",
+	}
+
+	errUnknownSubscription = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.5",
+		Status: http.StatusNotFound,
+		Title:  "Unknown Webhook Subscription",
+		Detail: "The specified webhook subscription does not exist",
+	}
+)
+
+func WebhookSubscriptions(c *gin.Context) {
+	query, err := db.Queries.Raw("get-webhook-subscriptions")
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	subscriptions := make([]v2.WebhookSubscription, 0)
+	err = pgxscan.Select(c, db.Pool(), &subscriptions, query)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+func CreateWebhookSubscription(c *gin.Context) {
+	var body struct {
+		URL    string   `json:"url"    binding:"required"`
+		Secret string   `json:"secret"`
+		Events []string `json:"events"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Abort()
+		errInvalidSubscription.Emit(c)
+		return
+	}
+
+	target, err := url.Parse(body.URL)
+	if err != nil || !target.IsAbs() || (target.Scheme != "http" && target.Scheme != "https") {
+		c.Abort()
+		errInvalidSubscription.Emit(c)
+		return
+	}
+
+	for _, event := range body.Events {
+		if !slices.Contains(v2.EventTypes, event) {
+			c.Abort()
+			errInvalidSubscription.Emit(c)
+			return
+		}
+	}
+
+	if body.Secret == "" {
+		body.Secret = randstr.Base62(secretLength)
+	}
+
+	query, err := db.Queries.Raw("create-webhook-subscription")
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	var subscription v2.WebhookSubscription
+	err = pgxscan.Get(c, db.Pool(), &subscription, query, target.String(), body.Secret, body.Events)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	// the secret is only returned once to allow the subscriber to store it
+	c.JSON(http.StatusCreated, struct {
+		v2.WebhookSubscription
+		Secret string `json:"secret"`
+	}{subscription, subscription.Secret})
+}
+
+func DeleteWebhookSubscription(c *gin.Context) {
+	subscriptionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Abort()
+		errInvalidSubscriptionID.Emit(c)
+		return
+	}
+
+	query, err := db.Queries.Raw("delete-webhook-subscription")
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	tag, err := db.Pool().Exec(c, query, subscriptionID)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	if tag.RowsAffected() == 0 {
+		c.Abort()
+		errUnknownSubscription.Emit(c)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func WebhookDeliveries(c *gin.Context) {
+	subscriptionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Abort()
+		errInvalidSubscriptionID.Emit(c)
+		return
+	}
+
+	var queryParams struct {
+		Limit int `form:"limit"`
+	}
+	if err := c.ShouldBindQuery(&queryParams); err != nil {
+		c.Abort()
+		errInvalidDeliveryLimit.Emit(c)
+		return
+	}
+	if queryParams.Limit <= 0 {
+		queryParams.Limit = defaultDeliveryLimit
+	}
+
+	query, err := db.Queries.Raw("get-webhook-subscription")
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	var subscription v2.WebhookSubscription
+	err = pgxscan.Get(c, db.Pool(), &subscription, query, subscriptionID)
+	if err != nil {
+		c.Abort()
+
+		if pgxscan.NotFound(err) {
+			errUnknownSubscription.Emit(c)
+			return
+		}
+
+		_ = c.Error(err)
+		return
+	}
+
+	query, err = db.Queries.Raw("get-webhook-deliveries")
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	deliveries := make([]v2.WebhookDelivery, 0)
+	err = pgxscan.Select(c, db.Pool(), &deliveries, query, subscription.ID, queryParams.Limit)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
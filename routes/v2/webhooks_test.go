@@ -0,0 +1,23 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWebhookDeliveriesInvalidQuery(t *testing.T) {
+	r := gin.New()
+	r.GET("/webhooks/:id/deliveries", WebhookDeliveries)
+
+	// the requests are rejected before the database is queried
+	for _, target := range []string{"/webhooks/first/deliveries", "/webhooks/1/deliveries?limit=all"} {
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, httptest.NewRequest(http.MethodGet, target, nil))
+		if res.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request for %s, got %d", target, res.Code)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package v2
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wisdom-oss/common-go/v3/types"
+
+	"microservice/internal/access"
+	"microservice/internal/store"
+	v2 "microservice/types/v2"
+)
+
+// The areas the withdrawals may be grouped by.
+// The withdrawals are grouped by the areas of a reference layer by prefixing
+// the name of the layer with GroupByLayerPrefix.
+const (
+	GroupByMunicipality = "municipality"
+	GroupByLayerPrefix  = "layer:"
+)
+
+var (
+	errUnsupportedGrouping = types.ServiceError{
+		Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+		Status: http.StatusBadRequest,
+		Title:  "Unsupported Grouping",
+		Detail: "The withdrawals can be grouped by 'municipality' or by a reference layer using 'layer:<name>'",
+	}
+)
+
+// Withdrawals returns the summed annual withdrawals per area.
+// The sums are precomputed by the store and may not reflect the latest
+// changes yet.
+func (r Routes) Withdrawals(c *gin.Context) {
+	groupBy := c.DefaultQuery("groupBy", GroupByMunicipality)
+	restriction := access.For(c)
+
+	var groups []v2.WithdrawalGroup
+	var err error
+	switch {
+	case groupBy == GroupByMunicipality:
+		groups, err = r.Store.MunicipalWithdrawals(c, restriction)
+	case strings.HasPrefix(groupBy, GroupByLayerPrefix):
+		groups, err = r.Store.LayerWithdrawals(c, strings.TrimPrefix(groupBy, GroupByLayerPrefix), restriction)
+	default:
+		c.Abort()
+		errUnsupportedGrouping.Emit(c)
+		return
+	}
+	if err != nil {
+		c.Abort()
+
+		if errors.Is(err, store.ErrUnknownLayer) {
+			errUnknownLayer.Emit(c)
+			return
+		}
+
+		_ = c.Error(err)
+		return
+	}
+
+	freshness, err := r.Store.Freshness(c)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v2.WithdrawalSummary{
+		Freshness: freshness,
+		GroupBy:   groupBy,
+		Groups:    groups,
+	})
+}
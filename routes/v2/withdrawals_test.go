@@ -0,0 +1,75 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"microservice/internal/store/storetest"
+	v2 "microservice/types/v2"
+)
+
+func TestWithdrawals(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	res := serve(m, storetest.Administrator, http.MethodGet, "/withdrawals", "")
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+	}
+
+	var summary v2.WithdrawalSummary
+	if err := json.Unmarshal(res.Body.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if !summary.Freshness.Equal(storetest.Freshness) || summary.GroupBy != GroupByMunicipality {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	expected := map[string]float64{"03459040": 120000, "03404000": 250 * 360}
+	if len(summary.Groups) != len(expected) {
+		t.Fatalf("expected %d municipalities, got %+v", len(expected), summary.Groups)
+	}
+	for _, group := range summary.Groups {
+		if group.UsageLocations != 1 || group.MaximalWithdrawal != expected[group.Key] {
+			t.Errorf("expected a single usage location withdrawing %v m³ in %s, got %+v",
+				expected[group.Key], group.Key, group)
+		}
+	}
+}
+
+func TestWithdrawalsRestricted(t *testing.T) {
+	m := storetest.NewStore(t)
+	storetest.EnableAccessPolicy(t)
+
+	res := serve(m, storetest.DepartmentB, http.MethodGet, "/withdrawals", "")
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d %s", res.Code, res.Body.String())
+	}
+
+	var summary v2.WithdrawalSummary
+	if err := json.Unmarshal(res.Body.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Groups) != 1 || summary.Groups[0].Key != "03404000" {
+		t.Errorf("expected only the withdrawals of the legal department B, got %+v", summary.Groups)
+	}
+}
+
+func TestWithdrawalsInvalidGrouping(t *testing.T) {
+	m := storetest.NewStore(t)
+
+	tests := []struct {
+		groupBy string
+		status  int
+	}{
+		{groupBy: "county", status: http.StatusBadRequest},
+		{groupBy: GroupByLayerPrefix + "unknown", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		res := serve(m, storetest.Administrator, http.MethodGet, "/withdrawals?groupBy="+tt.groupBy, "")
+		if res.Code != tt.status {
+			t.Errorf("expected %d for the grouping %s, got %d", tt.status, tt.groupBy, res.Code)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+// Package contract renders fixture data through the handlers of all api
+// versions and compares the responses with golden files. The responses are
+// additionally validated against the OpenAPI specifications to detect
+// accidental changes of the formats used by the clients.
+//
+// The test runs offline and does not require a database:
+//
+//	go test ./tools/contract              # compare with the golden files
+//	go test ./tools/contract -update      # rewrite the golden files
+package contract
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	jwtMiddleware "github.com/wisdom-oss/common-go/v3/middleware/gin/jwt"
+
+	"microservice/internal/configuration"
+	"microservice/internal/openapi"
+	"microservice/internal/redaction"
+	"microservice/internal/store"
+	v1Routes "microservice/routes/v1"
+	v2Routes "microservice/routes/v2"
+)
+
+// pseudonymKey makes the pseudonyms in the golden files stable.
+const pseudonymKey = "contract-test-pseudonym-key"
+
+// boundaryPlaceholder replaces the random boundary of multipart responses.
+const boundaryPlaceholder = "BOUNDARY"
+
+// testCase is a single request rendered into a golden file.
+type testCase struct {
+	name   string
+	spec   string // name of the specification
+	method string
+	target string // request uri
+	path   string // path as written in the specification
+	body   string
+}
+
+var cases = []testCase{
+	{name: "v1-usage-locations", spec: "v1", method: http.MethodGet, target: "/v1/", path: "/"},
+	{name: "v1-usage-locations-filtered", spec: "v1", method: http.MethodGet, target: "/v1/?in=03459&is_active=true", path: "/"},
+	{name: "v1-water-right-details", spec: "v1", method: http.MethodGet, target: "/v1/details/2", path: "/details/{water-right-id}"},
+	{name: "v1-average-withdrawals", spec: "v1", method: http.MethodPost, target: "/v1/average-withdrawals", path: "/average-withdrawals",
+		body: `[{"type":"Polygon","coordinates":[[[7.8,52.1],[8.2,52.1],[8.2,52.4],[7.8,52.4],[7.8,52.1]]]}]`},
+	{name: "v2-usage-locations", spec: "v2", method: http.MethodGet, target: "/v2/", path: "/"},
+	{name: "v2-usage-locations-filtered", spec: "v2", method: http.MethodGet, target: "/v2/?active=true&virtual=false", path: "/"},
+	{name: "v2-usage-locations-clustered", spec: "v2", method: http.MethodGet, target: "/v2/?cluster=true&zoom=8", path: "/"},
+	{name: "v2-water-right-details", spec: "v2", method: http.MethodGet, target: "/v2/water-right-details/4711", path: "/water-right-details/{id}"},
+	{name: "v2-nearest", spec: "v2", method: http.MethodGet, target: "/v2/nearest?lon=7.93&lat=52.26&k=2&active=true", path: "/nearest"},
+	{name: "v2-withdrawals", spec: "v2", method: http.MethodGet, target: "/v2/withdrawals", path: "/withdrawals"},
+	{name: "v2-withdrawals-layer", spec: "v2", method: http.MethodGet, target: "/v2/withdrawals?groupBy=layer:protection-zones", path: "/withdrawals"},
+	{name: "v2-grid-analysis", spec: "v2", method: http.MethodGet, target: "/v2/analysis/grid?cellSize=5000", path: "/analysis/grid"},
+	{name: "v2-grid-analysis-hex", spec: "v2", method: http.MethodGet, target: "/v2/analysis/grid?cellSize=5000&shape=hex", path: "/analysis/grid"},
+	{name: "v2-location-quality", spec: "v2", method: http.MethodGet, target: "/v2/quality/locations", path: "/quality/locations"},
+	{name: "v2-water-right-quality", spec: "v2", method: http.MethodGet, target: "/v2/quality/water-rights", path: "/quality/water-rights"},
+	{name: "v2-water-right-issues", spec: "v2", method: http.MethodGet, target: "/v2/quality/water-rights/issues", path: "/quality/water-rights/issues"},
+	{name: "v2-water-right-issues-json", spec: "v2", method: http.MethodGet, target: "/v2/quality/water-rights/issues?format=json", path: "/quality/water-rights/issues"},
+	{name: "v2-impact-analysis", spec: "v2", method: http.MethodPost, target: "/v2/analysis/impact", path: "/analysis/impact",
+		body: `{"point":{"type":"Point","coordinates":[7.93,52.26]},"radius":1000,"rate":{"amount":50000,"unit":"m³","per":"P1Y"}}`},
+}
+
+// variants are the callers the cases are rendered for.
+var variants = []struct {
+	suffix        string
+	administrator bool
+}{
+	{suffix: "", administrator: true},
+	{suffix: "-redacted", administrator: false},
+}
+
+var update = flag.Bool("update", false, "rewrite the golden files instead of comparing them")
+
+func TestContract(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	// the configuration is only read from the environment to keep the
+	// responses independent of a local configuration file
+	t.Setenv(configuration.EnvConfigurationType, configuration.ConfigurationType_Local)
+	if err := configuration.Default.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	config := configuration.Default.Viper()
+	config.Set(configuration.ConfigurationKey_AccessPolicyEnabled, false)
+	config.Set(configuration.ConfigurationKey_RedactionEnabled, true)
+	config.Set(configuration.ConfigurationKey_RedactionMode, redaction.ModePseudonymize)
+	config.Set(configuration.ConfigurationKey_RedactionPseudonymKey, pseudonymKey)
+
+	specs := make(map[string]*openapi.Document)
+	for _, name := range []string{"v1", "v2"} {
+		var err error
+		if specs[name], err = openapi.LoadEmbedded(name + ".openapi.yaml"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, variant := range variants {
+		s, err := fixtures()
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := engine(s, specs, variant.administrator)
+
+		for _, tc := range cases {
+			name := tc.name + variant.suffix
+			t.Run(name, func(t *testing.T) {
+				rendered, err := render(r, specs[tc.spec], tc)
+				if err != nil {
+					t.Error(err)
+				}
+
+				goldenFile := filepath.Join("testdata", name+".golden")
+				if *update {
+					if err := os.WriteFile(goldenFile, rendered, 0o644); err != nil { //nolint:gosec,mnd
+						t.Fatal(err)
+					}
+					return
+				}
+
+				expected, err := os.ReadFile(goldenFile)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := firstDifference(expected, rendered); diff != "" {
+					t.Errorf("response differs from golden file: %s", diff)
+				}
+			})
+		}
+	}
+}
+
+// engine registers the handlers like the service router. The authentication
+// is replaced by setting the permissions of the caller directly.
+func engine(s store.WaterRightStore, specs map[string]*openapi.Document, administrator bool) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(jwtMiddleware.KeyAdministrator, administrator)
+		c.Next()
+	})
+
+	v1Handlers := v1Routes.Routes{Store: s}
+	v1 := r.Group("/v1", specs["v1"].Validate("/v1"))
+	v1.GET("/", v1Handlers.UsageLocations)
+	v1.GET("/details/:id", v1Handlers.WaterRightDetails)
+	v1.POST("/average-withdrawals", v1Handlers.AverageWaterTakeout)
+
+	v2Handlers := v2Routes.Routes{Store: s}
+	v2 := r.Group("/v2", specs["v2"].Validate("/v2"))
+	v2.GET("/", v2Handlers.UsageLocations)
+	v2.GET("/water-right-details/:id", v2Handlers.WaterRightDetails)
+	v2.GET("/nearest", v2Handlers.NearestUsageLocations)
+	v2.GET("/withdrawals", v2Handlers.Withdrawals)
+	v2.POST("/analysis/impact", v2Handlers.ImpactAnalysis)
+	v2.GET("/analysis/grid", v2Handlers.GridAnalysis)
+	v2.GET("/quality/locations", v2Handlers.LocationQuality)
+	v2.GET("/quality/water-rights", v2Handlers.WaterRightQuality)
+	v2.GET("/quality/water-rights/issues", v2Handlers.WaterRightIssues)
+
+	return r
+}
+
+// render executes the request and returns the normalized response. The
+// response is validated against the specification.
+func render(r *gin.Engine, spec *openapi.Document, tc testCase) ([]byte, error) {
+	var body io.Reader
+	if tc.body != "" {
+		body = strings.NewReader(tc.body)
+	}
+	request := httptest.NewRequest(tc.method, tc.target, body)
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, request)
+
+	contentType := recorder.Header().Get("Content-Type")
+	responseBody := recorder.Body.Bytes()
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+
+	var validationErr error
+	var normalized []byte
+	switch {
+	case mediaType == "multipart/form-data":
+		validationErr = validateMultipart(spec, tc, recorder.Code, contentType, responseBody, params["boundary"])
+		normalized = bytes.ReplaceAll(responseBody, []byte(params["boundary"]), []byte(boundaryPlaceholder))
+		contentType = strings.ReplaceAll(contentType, params["boundary"], boundaryPlaceholder)
+	case mediaType == "text/csv":
+		validationErr = validateCSV(spec, tc, recorder.Code, contentType, responseBody)
+		normalized = responseBody
+	default:
+		validationErr = validateJSON(spec, tc, recorder.Code, contentType, responseBody)
+		normalized = indent(responseBody)
+	}
+
+	rendered := fmt.Appendf(nil, "%d %s\n\n%s\n", recorder.Code, contentType, bytes.TrimSpace(normalized))
+	return rendered, validationErr
+}
+
+func validateJSON(spec *openapi.Document, tc testCase, status int, contentType string, body []byte) error {
+	schema, err := spec.ResponseSchema(tc.path, tc.method, status, contentType)
+	if err != nil {
+		return err
+	}
+
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("response is not valid json: %w", err)
+	}
+	return schema.Validate(value)
+}
+
+// validateCSV validates that the response is a documented csv file with
+// rows of equal length.
+func validateCSV(spec *openapi.Document, tc testCase, status int, contentType string, body []byte) error {
+	schema, err := spec.ResponseSchema(tc.path, tc.method, status, contentType)
+	if err != nil {
+		return err
+	}
+	if _, err := csv.NewReader(bytes.NewReader(body)).ReadAll(); err != nil {
+		return fmt.Errorf("response is not valid csv: %w", err)
+	}
+	return schema.Validate(string(body))
+}
+
+// validateMultipart validates the json encoded parts of the response as the
+// properties of an object.
+func validateMultipart(spec *openapi.Document, tc testCase, status int, contentType string, body []byte, boundary string) error { //nolint:lll
+	schema, err := spec.ResponseSchema(tc.path, tc.method, status, contentType)
+	if err != nil {
+		return err
+	}
+
+	parts := make(map[string]any)
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid multipart response: %w", err)
+		}
+
+		value, err := jsonschema.UnmarshalJSON(part)
+		if err != nil {
+			return fmt.Errorf("part %s is not valid json: %w", part.FormName(), err)
+		}
+		parts[part.FormName()] = value
+	}
+	return schema.Validate(parts)
+}
+
+// indent formats json responses to make the golden files readable. Other
+// responses are returned unchanged.
+func indent(body []byte) []byte {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		return body
+	}
+	return indented.Bytes()
+}
+
+// firstDifference describes the first line that differs between both
+// contents or returns an empty string if they are equal.
+func firstDifference(expected, actual []byte) string {
+	if bytes.Equal(expected, actual) {
+		return ""
+	}
+
+	expectedLines := strings.Split(string(expected), "\n")
+	actualLines := strings.Split(string(actual), "\n")
+	for idx := range max(len(expectedLines), len(actualLines)) {
+		var e, a string
+		if idx < len(expectedLines) {
+			e = expectedLines[idx]
+		}
+		if idx < len(actualLines) {
+			a = actualLines[idx]
+		}
+		if e != a {
+			return fmt.Sprintf("line %d: expected %q, got %q", idx+1, e, a)
+		}
+	}
+	return "content differs"
+}
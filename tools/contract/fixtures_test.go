@@ -0,0 +1,176 @@
+package contract
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"microservice/internal/store"
+	"microservice/internal/store/storetest"
+	v2 "microservice/types/v2"
+)
+
+// fixtures creates a store containing water rights and usage locations that
+// use every attribute at least once.
+func fixtures() (store.WaterRightStore, error) {
+	m := store.NewMemory()
+
+	var retired v2.WaterRight
+	retired.Identifiers.Database = 1
+	retired.Identifiers.Cadenza = 4711
+	retired.Holder = storetest.Ptr("Erika Mustermann")
+	retired.Status = storetest.Ptr("historisch")
+	retired.LegalDepartments = []string{"E"}
+	retired.Authorities.Water = storetest.Ptr("Landkreis Osnabrück")
+	retired.Validity.From = storetest.Date(2004, time.March, 1)
+	retired.Validity.Until = storetest.Date(2004, time.February, 28)
+	m.AddWaterRight(retired, false)
+
+	var current v2.WaterRight
+	current.Identifiers.Database = 2
+	current.Identifiers.Cadenza = 4711
+	current.Identifiers.External = storetest.Ptr("OS-2024-0815")
+	current.Identifiers.File = storetest.Ptr("66.20-03-2024")
+	current.LegalTitle = storetest.Ptr("Erlaubnis")
+	current.Holder = storetest.Ptr("Erika Mustermann")
+	current.Address = storetest.Ptr("Musterweg 1, 49074 Osnabrück")
+	current.Status = storetest.Ptr("aktiv")
+	current.Subject = storetest.Ptr("Grundwasserentnahme zur Feldberegnung")
+	current.Annotation = storetest.Ptr("Entnahme nur zwischen April und September")
+	current.InitiallyGranted = storetest.Ptr(storetest.Date(2004, time.March, 1))
+	current.LastChange = storetest.Ptr(storetest.Date(2024, time.May, 15))
+	current.LegalDepartments = []string{"A", "E"}
+	current.Authorities.Water = storetest.Ptr("Landkreis Osnabrück")
+	current.Authorities.Registering = storetest.Ptr("Landkreis Osnabrück")
+	current.Authorities.Granting = storetest.Ptr("Landkreis Osnabrück")
+	current.Validity.From = storetest.Date(2024, time.June, 1)
+	current.Validity.Until = storetest.Date(2044, time.May, 31)
+	m.AddWaterRight(current, true)
+
+	var other v2.WaterRight
+	other.Identifiers.Database = 3
+	other.Identifiers.Cadenza = 4712
+	other.LegalDepartments = []string{"B"}
+	other.Authorities.Water = storetest.Ptr("Stadt Osnabrück")
+	other.Validity.From = storetest.Date(2010, time.January, 1)
+	m.AddWaterRight(other, true)
+
+	well := v2.UsageLocation{
+		ID:                  10,
+		CadenzaID:           90001,
+		WaterRightID:        2,
+		Serial:              storetest.Ptr("1"),
+		Active:              storetest.Ptr(true),
+		Real:                storetest.Ptr(true),
+		Name:                storetest.Ptr("Brunnen 1"),
+		LegalDepartment:     storetest.Ptr("E"),
+		LegalPurpose:        &[]string{"E1", "Entnahme von Grundwasser"},
+		MapExcerpt:          &v2.NumericKeyedValue{Key: storetest.Ptr(int64(3714)), Value: storetest.Ptr("Osnabrück")},
+		MunicipalArea:       &v2.NumericKeyedValue{Key: storetest.Ptr(int64(3459040)), Value: storetest.Ptr("Hasbergen")},
+		County:              storetest.Ptr("Osnabrück"),
+		Plot:                storetest.Ptr("112/4"),
+		Maintenance:         &v2.NumericKeyedValue{Key: storetest.Ptr(int64(12)), Value: storetest.Ptr("Unterhaltungsverband Hase")},
+		SurveyArea:          &v2.NumericKeyedValue{Key: storetest.Ptr(int64(3)), Value: storetest.Ptr("Ems")},
+		CatchmentArea:       &v2.NumericKeyedValue{Key: storetest.Ptr(int64(3614)), Value: storetest.Ptr("Düte")},
+		RegulationCitation:  storetest.Ptr("§ 8 WHG"),
+		GroundwaterBody:     storetest.Ptr("Hase Lockergestein links"),
+		WaterBody:           storetest.Ptr("Düte"),
+		FloodArea:           storetest.Ptr("HQ100 Düte"),
+		WaterProtectionArea: storetest.Ptr("WSG Hasbergen"),
+		RiverBasin:          storetest.Ptr("Ems"),
+		PhValues: &pgtype.Range[float64]{
+			Lower: 6.5, Upper: 8.5, //nolint:mnd
+			LowerType: pgtype.Inclusive, UpperType: pgtype.Inclusive, Valid: true,
+		},
+		InjectionLimits: []v2.InjectionLimit{
+			{Substance: storetest.Ptr("Nitrat"), Quantity: v2.Quantity{Value: storetest.Ptr(50.0), Unit: storetest.Ptr("mg/l")}},
+		},
+		LandRecord: &v2.LandRecord{District: storetest.Ptr("Hasbergen"), Field: storetest.Ptr(int64(7))},
+		IrrigationArea: &v2.Quantity{
+			Value: storetest.Ptr(12.5), Unit: storetest.Ptr("ha"), //nolint:mnd
+		},
+		DamTargetLevels: &v2.DamTarget{
+			Default: &v2.Quantity{Value: storetest.Ptr(52.1), Unit: storetest.Ptr("m NHN")}, //nolint:mnd
+			Max:     &v2.Quantity{Value: storetest.Ptr(52.8), Unit: storetest.Ptr("m NHN")}, //nolint:mnd
+		},
+		Geometry: storetest.Point(426780, 5790250), //nolint:mnd
+	}
+	well.Rates.Withdrawal = []v2.Rate{
+		{Value: storetest.Ptr(120000.0), Unit: storetest.Ptr("m³"), Per: pgtype.Interval{Months: 12, Valid: true}},       //nolint:mnd
+		{Value: storetest.Ptr(15.0), Unit: storetest.Ptr("l"), Per: pgtype.Interval{Microseconds: 1000000, Valid: true}}, //nolint:mnd
+	}
+	well.Rates.Pumping = []v2.Rate{
+		{Value: storetest.Ptr(60.0), Unit: storetest.Ptr("m³"), Per: pgtype.Interval{Microseconds: 3600000000, Valid: true}}, //nolint:mnd
+	}
+
+	virtual := v2.UsageLocation{
+		ID:              11,
+		CadenzaID:       90002,
+		WaterRightID:    2,
+		Serial:          storetest.Ptr("2"),
+		Active:          storetest.Ptr(false),
+		Real:            storetest.Ptr(false),
+		Name:            storetest.Ptr("Sammelstelle"),
+		LegalDepartment: storetest.Ptr("A"),
+		MunicipalArea:   &v2.NumericKeyedValue{Key: storetest.Ptr(int64(3459040)), Value: storetest.Ptr("Hasbergen")},
+		LandRecord:      &v2.LandRecord{Fallback: storetest.Ptr("Flur 3")},
+		Geometry:        storetest.Point(427010, 5790480), //nolint:mnd
+	}
+
+	discharge := v2.UsageLocation{
+		ID:              12,
+		CadenzaID:       90003,
+		WaterRightID:    3,
+		Active:          storetest.Ptr(true),
+		Real:            storetest.Ptr(true),
+		LegalDepartment: storetest.Ptr("B"),
+		MunicipalArea:   &v2.NumericKeyedValue{Key: storetest.Ptr(int64(3404000)), Value: storetest.Ptr("Osnabrück, Stadt")},
+		Geometry:        storetest.Point(435400, 5792100), //nolint:mnd
+	}
+	discharge.Rates.Withdrawal = []v2.Rate{
+		{Value: storetest.Ptr(250.0), Unit: storetest.Ptr("m³"), Per: pgtype.Interval{Days: 1, Valid: true}}, //nolint:mnd
+	}
+	discharge.Rates.WasteWater = []v2.Rate{
+		{Value: storetest.Ptr(200.0), Unit: storetest.Ptr("m³"), Per: pgtype.Interval{Days: 1, Valid: true}}, //nolint:mnd
+	}
+
+	// the crawled attributes of the retired water right are inconsistent and
+	// the location has not been recorded
+	retiredWell := v2.UsageLocation{
+		ID:              13,
+		CadenzaID:       90004,
+		WaterRightID:    1,
+		Active:          storetest.Ptr(false),
+		Real:            storetest.Ptr(true),
+		LegalDepartment: storetest.Ptr("E"),
+		LandRecord:      &v2.LandRecord{District: storetest.Ptr("Hasbergen"), Field: storetest.Ptr(int64(7)), Fallback: storetest.Ptr("Flur 7")},
+		PhValues: &pgtype.Range[float64]{
+			Lower: 8.5, Upper: 6.5, //nolint:mnd
+			LowerType: pgtype.Inclusive, UpperType: pgtype.Inclusive, Valid: true,
+		},
+	}
+	retiredWell.Rates.Withdrawal = []v2.Rate{
+		{Value: storetest.Ptr(80000.0), Unit: storetest.Ptr("Kubikmeter"), Per: pgtype.Interval{Months: 12, Valid: true}}, //nolint:mnd
+	}
+
+	for _, location := range []v2.UsageLocation{well, virtual, discharge, retiredWell} {
+		if err := m.AddUsageLocation(location); err != nil {
+			return nil, err
+		}
+	}
+
+	m.AddReferenceLayer("protection-zones", []store.ReferenceArea{
+		{Key: "WSG-01", Name: storetest.Ptr("Wasserschutzgebiet Düte"), Geometry: storetest.Square(426000, 5789500, 2000)},      //nolint:mnd
+		{Key: "WSG-02", Name: storetest.Ptr("Wasserschutzgebiet Osnabrück"), Geometry: storetest.Square(434000, 5791000, 3000)}, //nolint:mnd
+		{Key: "WSG-03", Geometry: storetest.Square(400000, 5800000, 1000)},                                                      //nolint:mnd
+	})
+
+	// the collection point of the virtual usage location lies across the
+	// border of its municipality
+	m.AddReferenceLayer("municipalities", []store.ReferenceArea{
+		{Key: "03459040", Name: storetest.Ptr("Hasbergen"), Geometry: storetest.Square(420000, 5785000, 7000)},         //nolint:mnd
+		{Key: "03404000", Name: storetest.Ptr("Osnabrück, Stadt"), Geometry: storetest.Square(427000, 5785000, 13000)}, //nolint:mnd
+	})
+
+	return storetest.Store{Memory: m}, nil
+}
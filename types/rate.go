@@ -35,6 +35,11 @@ const (
 	oneYear  = 12 * oneMonth
 )
 
+// CubicMeterPerYear normalizes the rate into cubic meters per year using a
+// year of twelve months with 30 days each.
+// Rates with unknown units or an empty interval are counted as zero.
+// The normalization is mirrored by the water_rights.cubic_meters_per_year
+// database function which needs to be kept in sync.
 func (r Rate) CubicMeterPerYear() float64 {
 	fl64, err := r.Value.Float64Value()
 	if err != nil {
@@ -42,7 +47,10 @@ func (r Rate) CubicMeterPerYear() float64 {
 	}
 
 	amount := fl64.Float64
-	micros := r.Per.Microseconds + int64(r.Per.Days)*oneDay.Microseconds() + int64(r.Per.Months)
+	micros := r.Per.Microseconds + int64(r.Per.Days)*oneDay.Microseconds() + int64(r.Per.Months)*oneMonth.Microseconds()
+	if micros <= 0 {
+		return 0
+	}
 
 	relativeMicros := float64(micros) / float64(oneYear.Microseconds())
 
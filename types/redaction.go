@@ -0,0 +1,58 @@
+package types
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+
+	v2 "microservice/types/v2"
+)
+
+// RedactPersonalData replaces the holder, address and annotation of the water
+// right.
+func (r *WaterRight) RedactPersonalData(redactor v2.Redactor) {
+	if !redactor.Enabled() {
+		return
+	}
+
+	r.Holder = redactText(redactor, r.Holder)
+	r.Address = redactText(redactor, r.Address)
+	r.Annotation = redactText(redactor, r.Annotation)
+}
+
+// RedactPersonalData replaces the plot and the land record of the usage
+// location.
+// The field number of the land record cannot be pseudonymized and is removed.
+func (l *UsageLocation) RedactPersonalData(redactor v2.Redactor) {
+	if !redactor.Enabled() {
+		return
+	}
+
+	l.Plot = redactText(redactor, l.Plot)
+
+	if l.LandRecord == nil {
+		return
+	}
+
+	district := redactText(redactor, l.LandRecord.District)
+	fallback := redactText(redactor, l.LandRecord.Fallback)
+	if district == nil && fallback == nil {
+		l.LandRecord = nil
+		return
+	}
+
+	l.LandRecord = &LandRecord{
+		District: district,
+		Fallback: fallback,
+	}
+}
+
+func redactText(redactor v2.Redactor, value *pgtype.Text) *pgtype.Text {
+	if value == nil || !value.Valid {
+		return value
+	}
+
+	redacted := redactor.Redact(&value.String)
+	if redacted == nil {
+		return nil
+	}
+	return &pgtype.Text{String: *redacted, Valid: true}
+}
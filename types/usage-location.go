@@ -287,6 +287,11 @@ func (l UsageLocation) MarshalJSON() ([]byte, error) {
 		InjectionLimits:        l.InjectionLimits,
 	}
 
+	// usage locations without a geometry are encoded with a null location
+	if l.Location == nil {
+		return json.Marshal(out)
+	}
+
 	geom.TransformInPlace(l.Location, func(c geom.Coord) {
 		transformer := wgs84.Transform(wgs84.EPSG(l.Location.SRID()), wgs84.EPSG(4326)) //nolint:mnd
 
@@ -0,0 +1,51 @@
+package v2
+
+import "time"
+
+// The event types that are recorded into the change events by the database
+// triggers.
+const (
+	EventWaterRightCreated    = "water-right.created"
+	EventWaterRightUpdated    = "water-right.updated"
+	EventWaterRightRetired    = "water-right.retired"
+	EventUsageLocationMoved   = "usage-location.moved"
+	EventUsageLocationCreated = "usage-location.created"
+	EventUsageLocationDeleted = "usage-location.deleted"
+)
+
+// EventTypes contains all event types that may be emitted.
+var EventTypes = []string{
+	EventWaterRightCreated,
+	EventWaterRightUpdated,
+	EventWaterRightRetired,
+	EventUsageLocationMoved,
+	EventUsageLocationCreated,
+	EventUsageLocationDeleted,
+}
+
+// ChangeEvent represents a single change that has been applied to the water
+// rights or their usage locations during an import.
+type ChangeEvent struct {
+	ID            int64     `db:"id"             json:"id"`
+	Type          string    `db:"type"           json:"type"`
+	WaterRight    *int64    `db:"water_right"    json:"waterRight"`
+	UsageLocation *int64    `db:"usage_location" json:"usageLocation"`
+	Occurred      time.Time `db:"occurred"       json:"occurred"`
+
+	// Transaction is the database transaction that recorded the event. The
+	// events are delivered in the order of their transactions.
+	Transaction uint64 `db:"transaction" json:"-"`
+
+	// LegalDepartments and WaterAuthority are used to apply the access policy
+	// to the event.
+	LegalDepartments []string `db:"legal_departments" json:"-"`
+	WaterAuthority   *string  `db:"water_authority"   json:"-"`
+}
+
+// After reports whether the event is delivered after the other event.
+func (e ChangeEvent) After(other ChangeEvent) bool {
+	if e.Transaction != other.Transaction {
+		return e.Transaction > other.Transaction
+	}
+	return e.ID > other.ID
+}
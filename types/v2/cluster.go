@@ -0,0 +1,51 @@
+package v2
+
+import (
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+)
+
+// UsageLocationCluster contains the usage locations that are displayed as a
+// single point on a map. The clusters are indexed by the column and row of
+// the grid cell they have been collected in.
+type UsageLocationCluster struct {
+	Column         int64
+	Row            int64
+	UsageLocations []UsageLocation
+}
+
+// ToFeature converts the cluster into a GeoJSON feature. The point of the
+// feature is the mean of the locations and the bounding box of the feature
+// contains all locations. The geometries of the usage locations are
+// transformed into EPSG:4326 in place.
+func (c UsageLocationCluster) ToFeature() *geojson.Feature {
+	var minimalWithdrawal, maximalWithdrawal, longitude, latitude float64
+	bounds := geom.NewBounds(geom.XY)
+	for _, location := range c.UsageLocations {
+		minimal, maximal := location.WithdrawalBounds()
+		minimalWithdrawal += minimal
+		maximalWithdrawal += maximal
+
+		point := location.EPSG4326Geom()
+		bounds.Extend(point)
+		longitude += point.FlatCoords()[0]
+		latitude += point.FlatCoords()[1]
+	}
+
+	members := float64(len(c.UsageLocations))
+	center := geom.NewPointFlat(geom.XY, []float64{longitude / members, latitude / members}).SetSRID(defaultCRS)
+
+	return &geojson.Feature{
+		ID:       fmt.Sprintf("cluster:%d:%d", c.Column, c.Row),
+		BBox:     bounds,
+		Geometry: center,
+		Properties: map[string]any{
+			"cluster":           true,
+			"usageLocations":    len(c.UsageLocations),
+			"minimalWithdrawal": minimalWithdrawal,
+			"maximalWithdrawal": maximalWithdrawal,
+		},
+	}
+}
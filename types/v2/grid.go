@@ -0,0 +1,45 @@
+package v2
+
+import (
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/wroge/wgs84/v2"
+)
+
+// GridCell contains the summed annual withdrawals of the active usage
+// locations of the current water rights within a cell of a regular grid.
+// The cells are indexed by their column and row like the PostGIS grid
+// functions.
+type GridCell struct {
+	Column            int64   `db:"i"`
+	Row               int64   `db:"j"`
+	Geometry          geom.T  `db:"geometry"` // the polygon of the cell
+	UsageLocations    int64   `db:"usage_locations"`
+	MinimalWithdrawal float64 `db:"minimal_withdrawal"`
+	MaximalWithdrawal float64 `db:"maximal_withdrawal"`
+}
+
+// ToFeature converts the cell into a GeoJSON feature. The polygon of the
+// cell is transformed into EPSG:4326 in place.
+func (c GridCell) ToFeature() *geojson.Feature {
+	if c.Geometry != nil && c.Geometry.SRID() != defaultCRS {
+		transformer := wgs84.Transform(wgs84.EPSG(c.Geometry.SRID()), wgs84.EPSG(defaultCRS))
+		geom.TransformInPlace(c.Geometry, func(coord geom.Coord) {
+			long, lat, _ := transformer(coord.X(), coord.Y(), 0)
+			coord.Set(geom.Coord{long, lat})
+		})
+		c.Geometry, _ = geom.SetSRID(c.Geometry, defaultCRS)
+	}
+
+	return &geojson.Feature{
+		ID:       fmt.Sprintf("%d:%d", c.Column, c.Row),
+		Geometry: c.Geometry,
+		Properties: map[string]any{
+			"usageLocations":    c.UsageLocations,
+			"minimalWithdrawal": c.MinimalWithdrawal,
+			"maximalWithdrawal": c.MaximalWithdrawal,
+		},
+	}
+}
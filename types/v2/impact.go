@@ -0,0 +1,49 @@
+package v2
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/twpayne/go-geom/encoding/geojson"
+)
+
+// ImpactRequest describes a planned withdrawal whose impact on the
+// surrounding usage locations is analyzed.
+type ImpactRequest struct {
+	// Point is the planned location of the withdrawal in EPSG:4326.
+	Point geojson.Geometry `json:"point"`
+
+	// Radius limits the analyzed usage locations in meters.
+	Radius float64 `json:"radius" binding:"required,gt=0"`
+
+	// Rate is the proposed withdrawal rate.
+	Rate Rate `json:"rate"`
+
+	// GroundwaterBody the withdrawal is taken from. If it is not set, the
+	// groundwater body of the closest usage location is used.
+	GroundwaterBody *string `json:"groundwaterBody"`
+}
+
+// ImpactAnalysis contains the annual withdrawals of the active usage
+// locations of the current water rights near a planned withdrawal that use
+// the same groundwater body.
+// All withdrawals are normalized into cubic meters per year.
+type ImpactAnalysis struct {
+	// Freshness is the time of the last refresh of the precomputed
+	// withdrawals. Changes made after this time are not reflected yet
+	Freshness       time.Time `json:"freshness"`
+	GroundwaterBody string    `json:"groundwaterBody"`
+
+	ProposedWithdrawal float64 `json:"proposedWithdrawal"`
+	MinimalWithdrawal  float64 `json:"minimalWithdrawal"`
+	MaximalWithdrawal  float64 `json:"maximalWithdrawal"`
+
+	// MinimalShare and MaximalShare are the shares of the proposed withdrawal
+	// in the total withdrawal after granting it. The minimal share is
+	// computed using the maximal existing withdrawal and vice versa.
+	MinimalShare float64 `json:"minimalShare"`
+	MaximalShare float64 `json:"maximalShare"`
+
+	// UsageLocations is a feature collection of the analyzed usage locations.
+	UsageLocations json.RawMessage `json:"usageLocations"`
+}
@@ -0,0 +1,14 @@
+package v2
+
+import "time"
+
+// ReferenceLayer is a named set of polygons the withdrawals may be grouped
+// by. The layers are uploaded by the administrators.
+type ReferenceLayer struct {
+	Name string `db:"name"        json:"name"`
+
+	// SourceSRID is the reference system the polygons have been uploaded in.
+	SourceSRID int       `db:"source_srid" json:"sourceSRID"`
+	Areas      int64     `db:"areas"       json:"areas"`
+	Uploaded   time.Time `db:"uploaded_at" json:"uploaded"`
+}
@@ -0,0 +1,69 @@
+package v2
+
+// The checks applied to the usage locations.
+const (
+	CheckMissingGeometry      = "missing-geometry"
+	CheckInvalidGeometry      = "invalid-geometry"
+	CheckOutsideExtent        = "outside-extent"
+	CheckMunicipalityMismatch = "municipality-mismatch"
+	CheckDuplicateLocation    = "duplicate-location"
+)
+
+// LocationIssue is a problem found by one of the checks of the usage
+// locations. A usage location may be reported by multiple checks.
+type LocationIssue struct {
+	UsageLocation int    `db:"usage_location" json:"usageLocation"`
+	WaterRight    int    `db:"water_right"    json:"waterRight"`
+	Check         string `db:"check_name"     json:"check"`
+	Detail        string `db:"detail"         json:"detail"`
+}
+
+// LocationQualityReport contains the issues found in the usage locations
+// together with the configuration of the checks.
+type LocationQualityReport struct {
+	// Extent is the bounding box the usage locations are expected in using
+	// the order of RFC 7946 (west, south, east, north).
+	Extent            [4]float64      `json:"extent"`
+	MunicipalityLayer string          `json:"municipalityLayer"`
+	DuplicateDistance float64         `json:"duplicateDistance"`
+	Summary           map[string]int  `json:"summary"`
+	Issues            []LocationIssue `json:"issues"`
+}
+
+// QualityRule describes a check of the attributes of the water rights and
+// their usage locations.
+type QualityRule struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// Weight is subtracted from the score of a water right for every issue
+	// found by the rule.
+	Weight float64 `json:"weight"`
+}
+
+// WaterRightIssue is a problem found in the attributes of a water right or
+// one of its usage locations.
+type WaterRightIssue struct {
+	WaterRight       uint64 `json:"waterRight"`
+	WaterRightNumber uint64 `json:"waterRightNumber"`
+	UsageLocation    *int   `json:"usageLocation"`
+	Rule             string `json:"rule"`
+	Detail           string `json:"detail"`
+}
+
+// WaterRightScore rates the attributes of a water right and its usage
+// locations between 0 and 100 points.
+type WaterRightScore struct {
+	WaterRight       uint64  `json:"waterRight"`
+	WaterRightNumber uint64  `json:"waterRightNumber"`
+	Score            float64 `json:"score"`
+	Issues           int     `json:"issues"`
+}
+
+// WaterRightQualityReport contains the scores of all water rights together
+// with the rules they have been computed with.
+type WaterRightQualityReport struct {
+	Rules        []QualityRule     `json:"rules"`
+	AverageScore *float64          `json:"averageScore"`
+	Scores       []WaterRightScore `json:"scores"`
+}
@@ -2,6 +2,7 @@ package v2
 
 import (
 	"encoding/json"
+	"math"
 	"time"
 
 	"github.com/go-chrono/chrono"
@@ -22,6 +23,54 @@ type rate struct {
 	Per   string   `json:"per"`
 }
 
+// CubicMeterPerYear normalizes the rate into cubic meters per year in the
+// same way as [types.Rate.CubicMeterPerYear].
+func (r Rate) CubicMeterPerYear() float64 {
+	if r.Value == nil || r.Unit == nil || !r.Per.Valid {
+		return 0
+	}
+
+	const (
+		day   = 24 * time.Hour
+		month = 30 * day
+		year  = 12 * month
+	)
+
+	micros := r.Per.Microseconds + int64(r.Per.Days)*day.Microseconds() + int64(r.Per.Months)*month.Microseconds()
+	if micros <= 0 {
+		return 0
+	}
+
+	factor, known := volumeFactor(*r.Unit)
+	if !known {
+		return 0
+	}
+	return *r.Value / (float64(micros) / float64(year.Microseconds())) * factor
+}
+
+// KnownUnit reports if the volume unit of the rate can be normalized into
+// cubic meters.
+func (r Rate) KnownUnit() bool {
+	if r.Unit == nil {
+		return false
+	}
+	_, known := volumeFactor(*r.Unit)
+	return known
+}
+
+// volumeFactor returns the factor converting the volume unit into cubic
+// meters.
+func volumeFactor(unit string) (float64, bool) {
+	switch unit {
+	case "l", "L", "liter", "litre", "Liter", "Litre":
+		return 0.001, true //nolint:mnd
+	case "m³", "m^3", "m3":
+		return 1, true
+	default:
+		return 0, false
+	}
+}
+
 func (r Rate) MarshalJSON() ([]byte, error) {
 	out := rate{
 		Value: r.Value,
@@ -39,3 +88,27 @@ func (r Rate) MarshalJSON() ([]byte, error) {
 	out.Per = chrono.FormatDuration(period, duration)
 	return json.Marshal(out)
 }
+
+// UnmarshalJSON reads a rate using an ISO 8601 duration as interval like it
+// is written by [Rate.MarshalJSON].
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	var in rate
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	period, duration, err := chrono.ParseDuration(in.Per)
+	if err != nil {
+		return err
+	}
+
+	r.Value = in.Value
+	r.Unit = in.Unit
+	r.Per = pgtype.Interval{
+		Months:       int32(math.Round(float64(period.Years*12 + period.Months))), //nolint:mnd
+		Days:         int32(math.Round(float64(period.Weeks*7 + period.Days))),    //nolint:mnd
+		Microseconds: int64(duration.Microseconds()),
+		Valid:        true,
+	}
+	return nil
+}
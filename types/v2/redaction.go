@@ -0,0 +1,55 @@
+package v2
+
+// Redactor replaces personal data with values that may be output to callers
+// which are not permitted to read the personal data.
+type Redactor interface {
+	// Enabled reports if the personal data needs to be redacted
+	Enabled() bool
+
+	// Redact returns the value that may be output in place of the supplied
+	// value
+	Redact(value *string) *string
+}
+
+// RedactPersonalData replaces the holder, address and annotation of the water
+// right as well as the personal data of the associated usage locations.
+func (r *WaterRight) RedactPersonalData(redactor Redactor) {
+	if !redactor.Enabled() {
+		return
+	}
+
+	r.Holder = redactor.Redact(r.Holder)
+	r.Address = redactor.Redact(r.Address)
+	r.Annotation = redactor.Redact(r.Annotation)
+
+	for idx := range r.AssociatedUsageLocations {
+		r.AssociatedUsageLocations[idx].RedactPersonalData(redactor)
+	}
+}
+
+// RedactPersonalData replaces the plot and the land record of the usage
+// location.
+// The field number of the land record cannot be pseudonymized and is removed.
+func (l *UsageLocation) RedactPersonalData(redactor Redactor) {
+	if !redactor.Enabled() {
+		return
+	}
+
+	l.Plot = redactor.Redact(l.Plot)
+
+	if l.LandRecord == nil {
+		return
+	}
+
+	district := redactor.Redact(l.LandRecord.District)
+	fallback := redactor.Redact(l.LandRecord.Fallback)
+	if district == nil && fallback == nil {
+		l.LandRecord = nil
+		return
+	}
+
+	l.LandRecord = &LandRecord{
+		District: district,
+		Fallback: fallback,
+	}
+}
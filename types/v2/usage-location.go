@@ -2,6 +2,8 @@ package v2
 
 import (
 	"encoding/json"
+	"math"
+	"slices"
 	"strconv"
 
 	"github.com/jackc/pgx/v5/pgtype"
@@ -52,8 +54,10 @@ type UsageLocation struct {
 	Geometry geom.T `db:"location" json:"-"`
 }
 
+// EPSG4326Geom transforms the geometry of the usage location into EPSG:4326
+// in place. Usage locations without a geometry return nil.
 func (l UsageLocation) EPSG4326Geom() geom.T {
-	if l.Geometry.SRID() == defaultCRS {
+	if l.Geometry == nil || l.Geometry.SRID() == defaultCRS {
 		return l.Geometry
 	}
 	geom.TransformInPlace(l.Geometry, func(c geom.Coord) {
@@ -69,13 +73,13 @@ func (l UsageLocation) EPSG4326Geom() geom.T {
 }
 
 func (l UsageLocation) ToFeature() (*geojson.Feature, error) {
-	if l.Geometry.SRID() != defaultCRS {
-		l.EPSG4326Geom()
-	}
-
+	// usage locations without a usable geometry are converted into features
+	// with a null geometry as allowed by RFC 7946
 	feature := &geojson.Feature{
-		ID:       strconv.Itoa(l.ID),
-		Geometry: l.Geometry,
+		ID: strconv.Itoa(l.ID),
+	}
+	if l.HasGeometry() {
+		feature.Geometry = l.EPSG4326Geom()
 	}
 
 	if l.PhValues != nil {
@@ -102,3 +106,34 @@ func (l UsageLocation) ToFeature() (*geojson.Feature, error) {
 	}
 	return feature, nil
 }
+
+// HasGeometry reports if the usage location has a geometry that can be
+// encoded as GeoJSON. Geometries with non-finite coordinates are reported by
+// the plausibility checks of the usage locations.
+func (l UsageLocation) HasGeometry() bool {
+	if l.Geometry == nil || l.Geometry.Empty() {
+		return false
+	}
+	for _, coordinate := range l.Geometry.FlatCoords() {
+		if math.IsNaN(coordinate) || math.IsInf(coordinate, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// WithdrawalBounds returns the smallest and largest normalized withdrawal
+// rate like the water_rights.minimal_annual_withdrawal and
+// water_rights.maximal_annual_withdrawal database functions.
+// Usage locations without withdrawal rates do not contribute a withdrawal.
+func (l UsageLocation) WithdrawalBounds() (float64, float64) {
+	if len(l.Rates.Withdrawal) == 0 {
+		return 0, 0
+	}
+
+	normalized := make([]float64, len(l.Rates.Withdrawal))
+	for idx, rate := range l.Rates.Withdrawal {
+		normalized[idx] = rate.CubicMeterPerYear()
+	}
+	return slices.Min(normalized), slices.Max(normalized)
+}
@@ -60,11 +60,17 @@ func (r WaterRight) MarshalJSON() ([]byte, error) {
 	}
 
 	for _, location := range r.AssociatedUsageLocations {
-		geometry := location.EPSG4326Geom()
-		featureCollection.BBox.Extend(geometry)
-
 		feature, _ := location.ToFeature()
 		featureCollection.Features = append(featureCollection.Features, feature)
+		if feature.Geometry != nil {
+			featureCollection.BBox.Extend(feature.Geometry)
+		}
+	}
+
+	// the bounds of a collection without geometries are infinite and can not
+	// be encoded
+	if featureCollection.BBox.IsEmpty() {
+		featureCollection.BBox = nil
 	}
 
 	out.AssociatedUsageLocations, _ = featureCollection.MarshalJSON()
@@ -0,0 +1,33 @@
+package v2
+
+import "time"
+
+// WebhookSubscription represents a subscriber that is notified about changes
+// in the water rights.
+type WebhookSubscription struct {
+	ID           int64      `db:"id"            json:"id"`
+	URL          string     `db:"url"           json:"url"`
+	Secret       string     `db:"secret"        json:"-"`
+	Events       []string   `db:"events"        json:"events"`
+	LastEvent    int64      `db:"last_event"    json:"lastEvent"`
+	Created      time.Time  `db:"created"       json:"created"`
+	ClaimedUntil *time.Time `db:"claimed_until" json:"-"`
+}
+
+// WebhookDelivery represents a single attempt to deliver a change event to a
+// subscriber.
+type WebhookDelivery struct {
+	ID           int64     `db:"id"           json:"id"`
+	Subscription int64     `db:"subscription" json:"subscription"`
+	Event        int64     `db:"event"        json:"event"`
+	EventType    string    `db:"event_type"   json:"eventType"`
+	Attempt      int       `db:"attempt"      json:"attempt"`
+	StatusCode   *int      `db:"status_code"  json:"statusCode"`
+	Error        *string   `db:"error"        json:"error"`
+	Duration     int64     `db:"duration_ms"  json:"durationMilliseconds"`
+	Attempted    time.Time `db:"attempted"    json:"attempted"`
+
+	// Failed is set on the last attempt of an event that could not be
+	// delivered. The event is not delivered again afterward.
+	Failed bool `db:"failed" json:"failed"`
+}
@@ -0,0 +1,26 @@
+package v2
+
+import "time"
+
+// WithdrawalSummary contains the annual withdrawals of the active usage
+// locations of the current water rights grouped by an area.
+// All withdrawals are normalized into cubic meters per year.
+type WithdrawalSummary struct {
+	// Freshness is the time of the last refresh of the precomputed
+	// withdrawals. Changes made after this time are not reflected yet
+	Freshness time.Time         `json:"freshness"`
+	GroupBy   string            `json:"groupBy"`
+	Groups    []WithdrawalGroup `json:"groups"`
+}
+
+// WithdrawalGroup contains the summed annual withdrawals of the usage
+// locations in a single area.
+// Usage locations with multiple withdrawal rates contribute their smallest
+// rate to the minimal and their largest rate to the maximal withdrawal.
+type WithdrawalGroup struct {
+	Key               string  `db:"key"                json:"key"`
+	Name              *string `db:"name"               json:"name"`
+	UsageLocations    int64   `db:"usage_locations"    json:"usageLocations"`
+	MinimalWithdrawal float64 `db:"minimal_withdrawal" json:"minimalWithdrawal"`
+	MaximalWithdrawal float64 `db:"maximal_withdrawal" json:"maximalWithdrawal"`
+}